@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Ladicle/org2html/org"
+)
+
+// evaluator executes source blocks of Org files and writes them back with
+// the results. Only the results are changed in the files.
+type evaluator struct {
+	evaluator org.Evaluator
+	write     bool
+	out       io.Writer
+}
+
+func runEval(args []string) int {
+	var (
+		flags = flag.NewFlagSet("eval", flag.ExitOnError)
+		e     = evaluator{evaluator: org.NewEvaluator(nil), out: os.Stdout}
+		langs string
+	)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: org2html eval [flags] file ...")
+		flags.PrintDefaults()
+	}
+	flags.BoolVar(&e.write, "w", false, "write result to (source) file instead of stdout")
+	flags.DurationVar(&e.evaluator.Timeout, "timeout", e.evaluator.Timeout, "time limit of each source block")
+	flags.StringVar(&langs, "lang", "sh,bash,go", "comma separated languages to evaluate")
+	flags.BoolVar(&e.evaluator.InheritEnv, "inherit-env", false, "pass the whole environment to the source blocks instead of only PATH, HOME and TMPDIR")
+	_ = flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+	executors := org.DefaultExecutors()
+	e.evaluator.Executors = make(map[string]org.Executor)
+	for _, lang := range strings.Split(langs, ",") {
+		executor, ok := executors[strings.TrimSpace(lang)]
+		if !ok {
+			fmt.Fprintf(os.Stderr, "org2html eval: unsupported language %q\n", lang)
+			return 2
+		}
+		e.evaluator.Executors[strings.TrimSpace(lang)] = executor
+	}
+
+	exitCode := 0
+	for _, name := range flags.Args() {
+		if err := e.eval(name); err != nil {
+			fmt.Fprintf(os.Stderr, "org2html eval: %v\n", err)
+			exitCode = 2
+		}
+	}
+	return exitCode
+}
+
+// eval evaluates the Org file in its directory.
+func (e evaluator) eval(name string) error {
+	src, err := os.ReadFile(name)
+	if err != nil {
+		return err
+	}
+	nodes, err := parse(src)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	ev := e.evaluator
+	if ev.Dir == "" {
+		ev.Dir = filepath.Dir(name)
+	}
+	evaluated, err := ev.Evaluate(context.Background(), nodes)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	out, err := org.SpliceResults(src, nodes, evaluated)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	if err := org.VerifyOrg(evaluated, out); err != nil {
+		return fmt.Errorf("%s: cannot write the results safely: %w", name, err)
+	}
+	if !e.write {
+		_, err := e.out.Write(out)
+		return err
+	}
+	info, err := os.Stat(name)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(name, out, info.Mode().Perm())
+}
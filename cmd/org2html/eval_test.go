@@ -0,0 +1,40 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/Ladicle/org2html/org"
+)
+
+func TestEvaluator(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not found")
+	}
+	dir := t.TempDir()
+	name := filepath.Join(dir, "a.org")
+	src := "#+begin_src sh :exports both\npwd\n#+end_src\n\n#+RESULTS:\n: old\n"
+	if err := os.WriteFile(name, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	e := evaluator{evaluator: org.NewEvaluator(org.DefaultExecutors()), write: true}
+	if err := e.eval(name); err != nil {
+		t.Fatalf("unexpected error: err=%v", err)
+	}
+	got, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the working directory is the directory of the Org file.
+	wd, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "#+begin_src sh :exports both\npwd\n#+end_src\n\n#+RESULTS:\n: " + wd + "\n"
+	if !bytes.Equal(got, []byte(want)) {
+		t.Errorf("unexpected file:\ngot=%s\nwant=%s", got, want)
+	}
+}
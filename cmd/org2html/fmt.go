@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/Ladicle/org2html/org"
+)
+
+const orgFileExt = ".org"
+
+// formatter formats Org files like gofmt.
+type formatter struct {
+	writer org.OrgWriter
+	list   bool
+	diff   bool
+	write  bool
+	out    io.Writer
+}
+
+func runFmt(args []string) int {
+	var (
+		flags = flag.NewFlagSet("fmt", flag.ExitOnError)
+		f     = formatter{writer: org.DefaultOrgWriter(), out: os.Stdout}
+	)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: org2html fmt [flags] [path ...]")
+		flags.PrintDefaults()
+	}
+	flags.BoolVar(&f.list, "l", false, "list files whose formatting differs from org2html fmt's")
+	flags.BoolVar(&f.diff, "d", false, "display diffs instead of rewriting files")
+	flags.BoolVar(&f.write, "w", false, "write result to (source) file instead of stdout")
+	flags.IntVar(&f.writer.TagsColumn, "tags-column", f.writer.TagsColumn,
+		"column of headline tags; negative value aligns the end of tags")
+	flags.BoolVar(&f.writer.UpperCase, "upper", false, "write keywords and block names in upper case")
+	_ = flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		if f.write {
+			fmt.Fprintln(os.Stderr, "org2html fmt: cannot use -w with standard input")
+			return 2
+		}
+		if err := f.process("<standard input>", os.Stdin); err != nil {
+			fmt.Fprintf(os.Stderr, "org2html fmt: %v\n", err)
+			return 2
+		}
+		return 0
+	}
+
+	exitCode := 0
+	for _, path := range flags.Args() {
+		if err := f.walk(path); err != nil {
+			fmt.Fprintf(os.Stderr, "org2html fmt: %v\n", err)
+			exitCode = 2
+		}
+	}
+	return exitCode
+}
+
+// walk formats the file or all Org files under the directory.
+func (f formatter) walk(root string) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		// the root file is always formatted even if it does not have the extension.
+		if d.IsDir() || (path != root && filepath.Ext(path) != orgFileExt) {
+			return nil
+		}
+		in, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+		return f.process(path, in)
+	})
+}
+
+// process formats the input and outputs the result according to the flags.
+func (f formatter) process(name string, in io.Reader) error {
+	src, err := io.ReadAll(in)
+	if err != nil {
+		return err
+	}
+	res, err := format(f.writer, src)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	if !f.list && !f.diff && !f.write {
+		_, err := f.out.Write(res)
+		return err
+	}
+	if bytes.Equal(src, res) {
+		return nil
+	}
+	if f.list {
+		fmt.Fprintln(f.out, name)
+	}
+	if f.write {
+		info, err := os.Stat(name)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(name, res, info.Mode().Perm()); err != nil {
+			return err
+		}
+	}
+	if f.diff {
+		fmt.Fprintf(f.out, "diff -u %s.orig %s\n", name, name)
+		writeUnifiedDiff(f.out, name+".orig", name, src, res)
+	}
+	return nil
+}
+
+// format parses the Org source and writes it back with the writer. It
+// returns an error if the result changes the meaning of the source.
+func format(w org.OrgWriter, src []byte) ([]byte, error) {
+	nodes, err := parse(src)
+	if err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	if err := w.Write(nodes, &out); err != nil {
+		return nil, err
+	}
+	if err := org.VerifyOrg(nodes, out.Bytes()); err != nil {
+		return nil, fmt.Errorf("cannot format safely: %w", err)
+	}
+	return out.Bytes(), nil
+}
+
+// parse parses the Org source with the default tokenizer and parser.
+func parse(src []byte) ([]org.Node, error) {
+	tokens, err := org.DefaultTokenizer().Tokenize(bytes.NewReader(src))
+	if err != nil {
+		return nil, err
+	}
+	return org.DefaultParser(tokens).Parse()
+}
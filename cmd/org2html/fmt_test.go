@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Ladicle/org2html/org"
+)
+
+const (
+	unformatted = "#+TITLE: title\n* headline :tag:\n| a | bb |\n|-\n| ccc |\n"
+	formatted   = `#+title: title
+
+* headline                                                              :tag:
+
+| a   | bb |
+|-----+----|
+| ccc |    |
+`
+)
+
+func TestFormatter(t *testing.T) {
+	var tests = []struct {
+		desc     string
+		fmt      formatter
+		wantOut  string
+		wantFile string
+	}{
+		{
+			desc:     "stdout",
+			fmt:      formatter{},
+			wantOut:  formatted,
+			wantFile: unformatted,
+		},
+		{
+			desc:     "list",
+			fmt:      formatter{list: true},
+			wantOut:  "FILE\n",
+			wantFile: unformatted,
+		},
+		{
+			desc:     "write",
+			fmt:      formatter{write: true},
+			wantFile: formatted,
+		},
+		{
+			desc: "diff",
+			fmt:  formatter{diff: true},
+			wantOut: `diff -u FILE.orig FILE
+--- FILE.orig
++++ FILE
+@@ -1,5 +1,7 @@
+-#+TITLE: title
+-* headline :tag:
+-| a | bb |
+-|-
+-| ccc |
++#+title: title
++
++* headline                                                              :tag:
++
++| a   | bb |
++|-----+----|
++| ccc |    |
+`,
+			wantFile: unformatted,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "test.org")
+			if err := os.WriteFile(file, []byte(unformatted), 0600); err != nil {
+				t.Fatal(err)
+			}
+			var out bytes.Buffer
+			tt.fmt.writer = org.DefaultOrgWriter()
+			tt.fmt.out = &out
+			if err := tt.fmt.walk(filepath.Dir(file)); err != nil {
+				t.Fatalf("unexpected error: err=%v", err)
+			}
+			if got, want := out.String(), string(bytes.ReplaceAll([]byte(tt.wantOut), []byte("FILE"), []byte(file))); got != want {
+				t.Errorf("unexpected output:\ngot=%v\nwant=%v", got, want)
+			}
+			data, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := string(data); got != tt.wantFile {
+				t.Errorf("unexpected file:\ngot=%v\nwant=%v", got, tt.wantFile)
+			}
+		})
+	}
+}
+
+func TestWriteUnifiedDiff(t *testing.T) {
+	var (
+		a   = "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"
+		b   = "1\n2\nthree\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n"
+		out bytes.Buffer
+	)
+	writeUnifiedDiff(&out, "a", "b", []byte(a), []byte(b))
+	want := `--- a
++++ b
+@@ -1,6 +1,6 @@
+ 1
+ 2
+-3
++three
+ 4
+ 5
+ 6
+@@ -10,3 +10,4 @@
+ 10
+ 11
+ 12
++13
+`
+	if got := out.String(); got != want {
+		t.Errorf("unexpected diff:\ngot=%v\nwant=%v", got, want)
+	}
+}
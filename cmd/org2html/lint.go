@@ -0,0 +1,59 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/Ladicle/org2html/org"
+)
+
+func runLint(args []string) int {
+	flags := flag.NewFlagSet("lint", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: org2html lint file ...")
+		flags.PrintDefaults()
+	}
+	_ = flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+	exitCode := 0
+	for _, name := range flags.Args() {
+		n, err := lint(os.Stdout, name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "org2html lint: %v\n", err)
+			exitCode = 2
+		} else if n > 0 && exitCode == 0 {
+			exitCode = 1
+		}
+	}
+	return exitCode
+}
+
+// lint writes the problems of the Org file such as undefined footnotes, and
+// returns the number of them. #+INCLUDE and #+SETUPFILE are expanded, such as
+// for the macros defined in the setup files, and the problems in the included
+// lines are reported at the lines of the keywords.
+func lint(out io.Writer, name string) (int, error) {
+	loader := org.Loader{FS: os.DirFS(filepath.Dir(name))}
+	if home, err := os.UserHomeDir(); err == nil {
+		loader.HomeFS = os.DirFS(home)
+	}
+	nodes, lines, err := loader.LoadLines(filepath.Base(name))
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", name, err)
+	}
+	diags := org.Diagnose(nodes)
+	for _, d := range diags {
+		if n := d.Pos.Line; n > 0 && n <= len(lines) {
+			d.Pos.Line = lines[n-1]
+		}
+		fmt.Fprintf(out, "%s:%s\n", name, d)
+	}
+	return len(diags), nil
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "a.org")
+	src := "text[fn:1][fn:2]\n\n[fn:1] one\n\n[fn:3] three\n"
+	if err := os.WriteFile(name, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	n, err := lint(&out, name)
+	if err != nil {
+		t.Fatalf("unexpected error: err=%v", err)
+	}
+	if n != 2 {
+		t.Errorf("unexpected number of problems: got=%v, want=2", n)
+	}
+	want := name + `:1: footnote "2" is not defined` + "\n" +
+		name + `:5: footnote "3" is not referenced` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("unexpected output:\ngot=%v\nwant=%v", got, want)
+	}
+}
+
+func TestLintSetupFile(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"setup.org": "#+MACRO: greet Hello\n#+MACRO: name World\n",
+		"a.org":     "#+SETUPFILE: setup.org\n\n{{{greet}}}, {{{name}}}{{{missing}}}\n",
+	}
+	for name, src := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	name := filepath.Join(dir, "a.org")
+	var out bytes.Buffer
+	if _, err := lint(&out, name); err != nil {
+		t.Fatalf("unexpected error: err=%v", err)
+	}
+	want := name + `:3: macro "missing" is not defined` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("unexpected output:\ngot=%v\nwant=%v", got, want)
+	}
+}
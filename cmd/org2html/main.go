@@ -0,0 +1,58 @@
+// Command org2html processes Org documents.
+//
+// Usage:
+//
+//	org2html <command> [flags] [path ...]
+//
+// The commands are:
+//
+//	eval    evaluate source blocks and update their results
+//	fmt     format Org files
+//	lint    report problems such as undefined footnotes
+//	publish convert a directory of Org files to HTML pages
+//	tangle  extract source blocks into files
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+)
+
+// command is a sub-command which returns the exit code.
+type command = func(args []string) int
+
+var commands = map[string]command{
+	"eval":    runEval,
+	"fmt":     runFmt,
+	"lint":    runLint,
+	"publish": runPublish,
+	"tangle":  runTangle,
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "org2html: unknown command %q\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+	os.Exit(cmd(os.Args[2:]))
+}
+
+func usage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintln(os.Stderr, "usage: org2html <command> [flags] [path ...]")
+	fmt.Fprintln(os.Stderr, "commands:")
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %s\n", name)
+	}
+}
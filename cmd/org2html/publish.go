@@ -0,0 +1,96 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"runtime/debug"
+	"strings"
+
+	"github.com/Ladicle/org2html/org"
+)
+
+func runPublish(args []string) int {
+	var (
+		flags            = flag.NewFlagSet("publish", flag.ExitOnError)
+		out              string
+		include, exclude string
+		baseURL          string
+		sitemap          bool
+		sitemapConfig    org.Sitemap
+		feedConfig       org.Feed
+		cacheConfig      org.BuildCache
+	)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: org2html publish [flags] dir")
+		flags.PrintDefaults()
+	}
+	flags.StringVar(&out, "o", "public", "output directory")
+	flags.BoolVar(&cacheConfig.Force, "force", false, "render all documents even if they are not changed since the last build")
+	flags.StringVar(&include, "include", "", "comma separated glob patterns of the documents")
+	flags.StringVar(&exclude, "exclude", "", "comma separated glob patterns of the excluded files and directories")
+	flags.StringVar(&baseURL, "base-url", "", "absolute URL of the output directory to write sitemap.xml and the feed")
+	flags.BoolVar(&sitemap, "sitemap", false, "write the sitemap page which lists the documents")
+	flags.StringVar(&sitemapConfig.FileName, "sitemap-file", "sitemap.org", "path of the sitemap page")
+	flags.StringVar(&sitemapConfig.Title, "sitemap-title", "Sitemap", "title of the sitemap page")
+	flags.StringVar((*string)(&sitemapConfig.GroupBy), "sitemap-group", "", "group of the sitemap page: directory or tag")
+	flags.StringVar((*string)(&sitemapConfig.SortBy), "sitemap-sort", "title", "order of the sitemap page: title, date or path")
+	flags.BoolVar(&sitemapConfig.Reverse, "sitemap-reverse", false, "reverse the order of the sitemap page")
+	flags.StringVar((*string)(&feedConfig.Format), "feed", "", "write the feed of the dated documents: atom or rss")
+	flags.StringVar(&feedConfig.FileName, "feed-file", "", "path of the feed (default atom.xml or rss.xml)")
+	flags.StringVar(&feedConfig.Title, "feed-title", "", "title of the feed")
+	flags.StringVar(&feedConfig.Description, "feed-description", "", "description of the feed")
+	flags.StringVar(&feedConfig.Author, "feed-author", "", "author of the feed")
+	flags.BoolVar(&feedConfig.Headlines, "feed-headlines", false, "make entries from the headlines with PUBDATE or CLOSED instead of the documents")
+	flags.IntVar(&feedConfig.Limit, "feed-limit", 0, "maximum number of the feed entries")
+	_ = flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+	project := newProject(flags.Arg(0), include, exclude)
+	project.BaseURL = baseURL
+	// pages are rendered again after updating the program.
+	if info, ok := debug.ReadBuildInfo(); ok {
+		cacheConfig.Version = info.Main.Version
+	}
+	project.Cache = &cacheConfig
+	if sitemap {
+		project.Sitemap = &sitemapConfig
+	}
+	if feedConfig.Format != "" {
+		project.Feed = &feedConfig
+	}
+	if err := project.Publish(out); err != nil {
+		fmt.Fprintf(os.Stderr, "org2html publish: %v\n", err)
+		return 2
+	}
+	return 0
+}
+
+// newProject returns the project of the directory. #+SETUPFILE in the home
+// directory is available if the home directory is known.
+func newProject(dir, include, exclude string) org.Project {
+	project := org.Project{
+		FS:      os.DirFS(dir),
+		Include: splitPatterns(include),
+		Exclude: splitPatterns(exclude),
+		Writer:  org.HTMLWriter{Highlighter: org.DefaultHighlighter()},
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		project.HomeFS = os.DirFS(home)
+	}
+	return project
+}
+
+// splitPatterns splits the comma separated patterns.
+func splitPatterns(s string) []string {
+	var patterns []string
+	for _, p := range strings.Split(s, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			patterns = append(patterns, p)
+		}
+	}
+	return patterns
+}
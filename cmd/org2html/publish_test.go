@@ -0,0 +1,47 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPublish(t *testing.T) {
+	src, out := t.TempDir(), t.TempDir()
+	for name, data := range map[string]string{
+		"home.org":        "[[file:notes/a.org][a]]\n",
+		"notes/a.org":     "#+DATE: 2022-02-03\na\n",
+		"drafts/tmp.org":  "draft\n",
+		"notes/image.png": "",
+	} {
+		name = filepath.Join(src, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	args := []string{"-o", out, "-exclude", "drafts", "-sitemap", "-sitemap-file", "index.org", "-base-url", "https://example.com/", "-feed", "rss", src}
+	if code := runPublish(args); code != 0 {
+		t.Fatalf("unexpected exit code: got=%v, want=0", code)
+	}
+	if code := runPublish(append([]string{"-force"}, args...)); code != 0 {
+		t.Fatalf("unexpected exit code of the forced build: got=%v, want=0", code)
+	}
+	for name, want := range map[string]bool{
+		"home.html":            true,
+		"index.html":           true,
+		"sitemap.xml":          true,
+		"rss.xml":              true,
+		".org2html-cache.json": true,
+		"notes/a.html":         true,
+		"drafts/tmp.html":      false,
+		"notes/image.html":     false,
+	} {
+		_, err := os.Stat(filepath.Join(out, filepath.FromSlash(name)))
+		if got := err == nil; got != want {
+			t.Errorf("unexpected existence of %s: got=%v, want=%v", name, got, want)
+		}
+	}
+}
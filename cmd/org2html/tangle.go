@@ -0,0 +1,103 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Ladicle/org2html/org"
+)
+
+// tangler writes source blocks of Org files into files like org-babel-tangle.
+type tangler struct {
+	list bool
+	out  io.Writer
+}
+
+func runTangle(args []string) int {
+	var (
+		flags = flag.NewFlagSet("tangle", flag.ExitOnError)
+		t     = tangler{out: os.Stdout}
+	)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: org2html tangle [flags] file ...")
+		flags.PrintDefaults()
+	}
+	flags.BoolVar(&t.list, "l", false, "list tangled files instead of writing them")
+	_ = flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+	exitCode := 0
+	for _, name := range flags.Args() {
+		if err := t.tangle(name); err != nil {
+			fmt.Fprintf(os.Stderr, "org2html tangle: %v\n", err)
+			exitCode = 2
+		}
+	}
+	return exitCode
+}
+
+// tangle writes the tangled files of the Org file. Relative paths are
+// resolved from the directory of the Org file.
+func (t tangler) tangle(name string) error {
+	src, err := os.ReadFile(name)
+	if err != nil {
+		return err
+	}
+	nodes, err := parse(src)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	files, err := org.Tangle(nodes, filepath.ToSlash(name))
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	for _, f := range files {
+		path, err := tanglePath(f.Path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if t.list {
+			fmt.Fprintln(t.out, path)
+			continue
+		}
+		if f.Mkdirp {
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				return err
+			}
+		}
+		perm := os.FileMode(0o644)
+		if f.Executable {
+			perm = 0o755
+		}
+		if err := os.WriteFile(path, []byte(f.Content), perm); err != nil {
+			return err
+		}
+		// WriteFile does not change the permission of existing files.
+		if f.Executable {
+			if err := os.Chmod(path, perm); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// tanglePath returns the file path of the tangled file. The `~/` prefix is
+// expanded to the home directory.
+func tanglePath(name string) (string, error) {
+	if !strings.HasPrefix(name, "~/") {
+		return filepath.FromSlash(name), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, filepath.FromSlash(strings.TrimPrefix(name, "~/"))), nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const literate = `#+PROPERTY: header-args:sh :tangle bin/hello.sh :mkdirp yes :shebang "#!/bin/sh"
+
+#+begin_src sh
+echo hello
+#+end_src
+
+#+begin_src go :tangle yes
+package main
+#+end_src
+`
+
+func TestTangler(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "config.org")
+	if err := os.WriteFile(name, []byte(literate), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := (tangler{list: true, out: &out}).tangle(name); err != nil {
+		t.Fatalf("unexpected error: err=%v", err)
+	}
+	wantList := filepath.Join(dir, "bin", "hello.sh") + "\n" + filepath.Join(dir, "config.go") + "\n"
+	if got := out.String(); got != wantList {
+		t.Errorf("unexpected list:\ngot=%v\nwant=%v", got, wantList)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "bin")); !os.IsNotExist(err) {
+		t.Errorf("list must not write files: err=%v", err)
+	}
+
+	if err := (tangler{out: &out}).tangle(name); err != nil {
+		t.Fatalf("unexpected error: err=%v", err)
+	}
+	var tests = []struct {
+		file     string
+		wantData string
+		wantExec bool
+	}{
+		{file: filepath.Join("bin", "hello.sh"), wantData: "#!/bin/sh\necho hello\n", wantExec: true},
+		{file: "config.go", wantData: "package main\n"},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(dir, tt.file)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := string(data); got != tt.wantData {
+			t.Errorf("unexpected %v:\ngot=%v\nwant=%v", tt.file, got, tt.wantData)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := info.Mode().Perm()&0o100 != 0; got != tt.wantExec {
+			t.Errorf("unexpected executable of %v: got=%v, want=%v", tt.file, got, tt.wantExec)
+		}
+	}
+}
+
+func TestTanglerHome(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	name := filepath.Join(t.TempDir(), "dotfiles.org")
+	src := "#+begin_src sh :tangle ~/.bashrc_test\nalias ll='ls -l'\n#+end_src\n"
+	if err := os.WriteFile(name, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := (tangler{list: true, out: &out}).tangle(name); err != nil {
+		t.Fatalf("unexpected error: err=%v", err)
+	}
+	want := filepath.Join(home, ".bashrc_test")
+	if got := out.String(); got != want+"\n" {
+		t.Errorf("unexpected list:\ngot=%v\nwant=%v", got, want)
+	}
+	if err := (tangler{out: &out}).tangle(name); err != nil {
+		t.Fatalf("unexpected error: err=%v", err)
+	}
+	if data, err := os.ReadFile(want); err != nil || string(data) != "alias ll='ls -l'\n" {
+		t.Errorf("unexpected %v: data=%q, err=%v", want, data, err)
+	}
+}
@@ -2,4 +2,4 @@
 
 set -ex
 
-golangci-lint run -c .golangci.yml org/...
+golangci-lint run -c .golangci.yml org/... cmd/...
@@ -19,7 +19,10 @@ var _ Node = Agenda{}
 
 // Agenda is a Node to describe Headline agenda such as closed date, deadline and start time.
 type Agenda struct {
-	Logs map[AgendaKey]Timestamp
+	Pos  Pos                     `json:"pos"`
+	Logs map[AgendaKey]Timestamp `json:"logs"`
+	// Indent is the indentation of the planning line such as `  `.
+	Indent string `json:"indent,omitempty"` // optional
 }
 
 func (a Agenda) Write(w io.Writer) error {
@@ -57,9 +60,11 @@ func ParseAgenda(p *Parser, i int) (int, Node, error) {
 	const colNum = 5
 	var (
 		itemNum = p.tokens[i].num
-		agenda  = Agenda{Logs: make(map[AgendaKey]Timestamp, itemNum)}
+		agenda  = Agenda{Pos: p.tokens[i].pos, Logs: make(map[AgendaKey]Timestamp, itemNum)}
 		vals    = p.tokens[i].vals
+		line    = p.tokens[i].text()
 	)
+	agenda.Indent = line[:len(line)-len(strings.TrimLeft(line, " \t"))]
 	// validate the number of items and values
 	if itemNum*colNum != len(vals) {
 		return 0, nil, fmt.Errorf("agenda item number and its values are unmatched: num=%v, vals=%#v",
@@ -90,3 +95,28 @@ func ParseAgenda(p *Parser, i int) (int, Node, error) {
 	}
 	return 1, agenda, nil
 }
+
+// SetAgenda sets the agenda item of the headline nodes[i] and returns the
+// updated nodes. A new Agenda is inserted after the headline if it does not
+// have agenda yet. The nodes are not modified.
+func SetAgenda(nodes []Node, i int, key AgendaKey, t Timestamp) []Node {
+	if i+1 < len(nodes) {
+		if agenda, ok := nodes[i+1].(Agenda); ok {
+			logs := make(map[AgendaKey]Timestamp, len(agenda.Logs)+1)
+			for k, v := range agenda.Logs {
+				logs[k] = v
+			}
+			logs[key] = t
+			agenda.Logs = logs
+			ret := make([]Node, len(nodes))
+			copy(ret, nodes)
+			ret[i+1] = agenda
+			return ret
+		}
+	}
+	agenda := Agenda{Logs: map[AgendaKey]Timestamp{key: t}}
+	ret := make([]Node, 0, len(nodes)+1)
+	ret = append(ret, nodes[:i+1]...)
+	ret = append(ret, agenda)
+	return append(ret, nodes[i+1:]...)
+}
@@ -1,47 +1,236 @@
 package org
 
 import (
-	"bytes"
 	"errors"
 	"fmt"
 	"io"
 	"regexp"
+	"strconv"
 	"strings"
 )
 
-// TODO: details/summary
-
 var _ Node = Block{}
 
+// Block is a Node to describe blocks except the source block. Children is the
+// parsed Content of greater blocks such as QUOTE, CENTER and special blocks.
 type Block struct {
-	Name    string
-	Content string
+	Pos        Pos    `json:"pos"`
+	Name       string `json:"name"`
+	Parameters string `json:"parameters,omitempty"`
+	Content    string `json:"content"`
+	Children   Nodes  `json:"children,omitempty"`
 }
 
 var _ Node = SourceBlock{}
 
+// SourceBlock is a Node to describe the source code block. Header is the
+// header arguments inherited from `#+PROPERTY: header-args` and `#+HEADER:`
+// keywords, and Property takes precedence over them.
 type SourceBlock struct {
-	Language   string
-	SourceCode string
-	Property   []string
+	Pos        Pos      `json:"pos"`
+	Name       string   `json:"name,omitempty"` // #+NAME
+	Language   string   `json:"language"`
+	SourceCode string   `json:"sourceCode"`
+	Switches   string   `json:"switches,omitempty"`
+	Property   []string `json:"property,omitempty"`
+	Header     []string `json:"header,omitempty"`
+	Results    *Results `json:"results,omitempty"` // optional
+}
+
+// HeaderArgs returns the typed header arguments of the block.
+func (c SourceBlock) HeaderArgs() HeaderArgs {
+	return parseHeaderArgs(c.Header, c.Property)
 }
 
 func (c Block) Write(w io.Writer) error {
-	fmt.Fprintf(w, "<div class=\"org-block block-%s\">\n", strings.ToLower(c.Name))
-	fmt.Fprintln(w, c.Content)
-	fmt.Fprintln(w, "</div>")
-	return nil
+	return c.writeHTML(w, &htmlContext{HTMLWriter: DefaultHTMLWriter()})
+}
+
+// writeHTML writes the block as the semantic HTML element of the block type.
+func (c Block) writeHTML(w io.Writer, ctx *htmlContext) error {
+	switch c.Name {
+	case "EXAMPLE":
+		fmt.Fprintln(w, `<pre class="example">`)
+		fmt.Fprintln(w, codeEscaper.Replace(c.Content))
+		fmt.Fprintln(w, "</pre>")
+		return nil
+	case "VERSE":
+		writeVerse(w, c.Content)
+		return nil
+	case "EXPORT":
+		// export blocks of other backends are dropped.
+		if strings.EqualFold(c.Parameters, "html") {
+			fmt.Fprintln(w, c.Content)
+		}
+		return nil
+	case "COMMENT":
+		return nil
+	case "QUOTE":
+		fmt.Fprintln(w, "<blockquote>")
+		defer fmt.Fprintln(w, "</blockquote>")
+	case "CENTER":
+		fmt.Fprintln(w, `<div class="org-center">`)
+		defer fmt.Fprintln(w, "</div>")
+	default:
+		fmt.Fprintf(w, "<div class=\"%s\">\n", strings.ToLower(c.Name))
+		defer fmt.Fprintln(w, "</div>")
+	}
+
+	return ctx.writeAll(w, c.Children)
+}
+
+// writeVerse writes the verse with line breaks. The indentation of lines is
+// preserved with non-breaking spaces.
+func writeVerse(w io.Writer, content string) {
+	fmt.Fprintln(w, `<p class="verse">`)
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		text := strings.TrimLeft(line, " ")
+		fmt.Fprint(w, strings.Repeat("&#xa0;", len(line)-len(text)), codeEscaper.Replace(text))
+		if i < len(lines)-1 {
+			fmt.Fprint(w, "<br />")
+		}
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintln(w, "</p>")
 }
 
 func (c SourceBlock) Write(w io.Writer) error {
+	return c.writeHTML(w, &htmlContext{HTMLWriter: DefaultHTMLWriter()})
+}
+
+// writeHTML writes the source code with the highlighter of ctx. Lines are
+// wrapped by spans if the block has line numbers or highlighted lines. The
+// code is folded by the details element if `:details` is set.
+func (c SourceBlock) writeHTML(w io.Writer, ctx *htmlContext) error {
+	args := c.HeaderArgs()
+	if args.exportsCode() {
+		if err := c.writeCode(w, ctx, args); err != nil {
+			return err
+		}
+	}
+	if args.exportsResults() && c.Results != nil {
+		return c.Results.writeHTML(w, ctx)
+	}
+	return nil
+}
+
+func (c SourceBlock) writeCode(w io.Writer, ctx *htmlContext, args HeaderArgs) error {
+	tokens := []CodeToken{{Text: c.SourceCode}}
+	if ctx.Highlighter != nil {
+		if hl, ok := ctx.Highlighter.Highlight(c.Language, c.SourceCode); ok {
+			tokens = hl
+		}
+	}
+	number, numbered := c.lineNumberStart(ctx.lineNumber)
+	hlLines, err := highlightedLines(args, strings.Count(c.SourceCode, "\n")+1)
+	if err != nil {
+		return err
+	}
+
+	details := args.enabled("details")
+	if details {
+		fmt.Fprintln(w, "<details>")
+		fmt.Fprintf(w, "<summary>%s</summary>\n", detailsSummary(args, c.Language))
+	}
 	fmt.Fprintln(w, "<div class=\"org-block block-src\">")
 	fmt.Fprintf(w, "<code class=\"block lang-%s\" data-lang=\"%s\">\n", c.Language, c.Language)
-	fmt.Fprintln(w, c.SourceCode)
+	if !numbered && len(hlLines) == 0 {
+		writeCodeTokens(w, tokens, ctx.HighlightStyle)
+		fmt.Fprintln(w)
+	} else {
+		for i, line := range splitCodeLines(tokens) {
+			class := "line"
+			if hlLines[i+1] {
+				class += " hl"
+			}
+			fmt.Fprintf(w, "<span class=\"%s\">", class)
+			if numbered {
+				fmt.Fprintf(w, "<span class=\"ln\">%d</span>", number)
+				ctx.lineNumber = number
+				number++
+			}
+			writeCodeTokens(w, line, ctx.HighlightStyle)
+			fmt.Fprintln(w, "</span>")
+		}
+	}
 	fmt.Fprintln(w, "</code>")
 	fmt.Fprintln(w, "</div>")
+	if details {
+		fmt.Fprintln(w, "</details>")
+	}
 	return nil
 }
 
+// detailsSummary returns the summary of the folded code. The value of
+// `:details` is used unless it is just `t`.
+func detailsSummary(args HeaderArgs, lang string) string {
+	if val, _ := args.Get("details"); val != "t" && val != "" {
+		return val
+	}
+	if lang == "" {
+		return "Code"
+	}
+	return lang
+}
+
+// lineNumberStart returns the first line number from the -n or +n switch. The
+// -n switch starts from 1 or its argument. The +n switch continues from the
+// last line number of the previous block and its argument is the increment.
+func (c SourceBlock) lineNumberStart(last int) (int, bool) {
+	fields := strings.Fields(c.Switches)
+	for i, f := range fields {
+		if f != "-n" && f != "+n" {
+			continue
+		}
+		arg := 1
+		if i+1 < len(fields) {
+			if n, err := strconv.Atoi(fields[i+1]); err == nil {
+				arg = n
+			}
+		}
+		if f == "+n" {
+			return last + arg, true
+		}
+		return arg, true
+	}
+	return 0, false
+}
+
+// highlightedLines returns the line numbers in the block, which starts from 1,
+// specified by the header argument such as `:hl_lines 1-3,5`. Ranges are
+// clamped to the number of lines n.
+func highlightedLines(args HeaderArgs, n int) (map[int]bool, error) {
+	val, ok := args.Get("hl_lines")
+	if !ok {
+		return nil, nil
+	}
+	lines := make(map[int]bool)
+	for _, r := range strings.FieldsFunc(val, func(r rune) bool { return r == ',' || r == ' ' }) {
+		bounds := strings.SplitN(r, "-", 2)
+		from, err := strconv.Atoi(bounds[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid hl_lines of source block: %q", val)
+		}
+		to := from
+		if len(bounds) == 2 {
+			if to, err = strconv.Atoi(bounds[1]); err != nil {
+				return nil, fmt.Errorf("invalid hl_lines of source block: %q", val)
+			}
+		}
+		if from < 1 {
+			from = 1
+		}
+		if to > n {
+			to = n
+		}
+		for i := from; i <= to; i++ {
+			lines[i] = true
+		}
+	}
+	return lines, nil
+}
+
 const sourceBlockName = "SRC"
 
 var (
@@ -68,47 +257,141 @@ func ParseBlock(p *Parser, i int) (int, Node, error) {
 	case "":
 		return 0, nil, errors.New("block name is empty")
 	default:
-		block = Block{Name: name}
-	}
-
-	var (
-		content bytes.Buffer
-		start   = i
-	)
-	for i++; i < len(p.tokens); i++ {
-		if p.tokens[i].kind == KindBlockEnd {
-			if got, want := strings.ToUpper(p.tokens[i].vals[0]), block.Name; got != want {
-				return 0, nil, fmt.Errorf(
-					"token[%d] is unexpected block end: got=%v, want=%v", i, got, want)
-			}
-			break
-		}
-		content.WriteString(p.tokens[i].vals[0])
-		content.WriteString("\n")
+		block = Block{Pos: p.tokens[i].pos, Name: name, Parameters: strings.TrimSpace(p.tokens[i].vals[1])}
+	}
+
+	start := i
+	i, err := p.blockEnd(start)
+	if err != nil {
+		return 0, nil, err
 	}
-	block.Content = strings.TrimRight(content.String(), "\n")
+	block.Content = blockContent(p.tokens[start+1 : i])
 
+	if isGreaterBlock(block.Name) {
+		children, err := p.subParser(start+1, i).Parse()
+		if err != nil {
+			return 0, nil, err
+		}
+		block.Children = children
+	}
 	if block.Name != sourceBlockName {
 		return i - start + 1, block, nil
 	}
 
 	// start extra parsing for source block.
+	block.Parameters = ""
 	parts := strings.SplitN(p.tokens[start].vals[1], " ", 2)
 
 	lang := parts[0]
-	var property string
+	var switches, property string
 	if len(parts) == 2 {
-		property = strings.TrimPrefix(strings.TrimSpace(parts[1]), ":")
+		// switches such as -n are placed before the header arguments.
+		rest := " " + strings.TrimSpace(parts[1])
+		idx := strings.Index(rest, " :")
+		if idx < 0 {
+			idx = len(rest)
+		}
+		switches = strings.TrimSpace(rest[:idx])
+		property = rest[idx:]
 	}
 
 	var srcBlock = SourceBlock{
+		Pos:        block.Pos,
 		Language:   lang,
 		SourceCode: block.Content,
+		Switches:   switches,
+		Property:   splitHeaderArgs(property),
+		Header:     p.inheritedHeaderArgs(start, lang),
 	}
-	if property != "" {
-		for _, v := range strings.Split(property, " :") {
-			srcBlock.Property = append(srcBlock.Property, strings.TrimSpace(v))
-		}
+	if names := p.affiliatedValues(start, string(NameKey), strings.Fields); len(names) > 0 {
+		srcBlock.Name = names[len(names)-1]
 	}
 	return i - start + 1, srcBlock, nil
 }
+
+// isGreaterBlock reports whether the block contains other elements. The
+// contents of other blocks such as SRC and EXAMPLE are verbatim.
+func isGreaterBlock(name string) bool {
+	switch name {
+	case sourceBlockName, "EXAMPLE", "EXPORT", "COMMENT", "VERSE":
+		return false
+	}
+	return true
+}
+
+// blockEnd returns the index of the end token of the block p.tokens[i]. Blocks
+// nested in the greater block are skipped, so the block can contain the block
+// of the same name. It returns len(p.tokens) if the block is not closed, or
+// an error if a nested block is not closed and consumes the end of the block.
+func (p *Parser) blockEnd(i int) (int, error) {
+	name := strings.ToUpper(p.tokens[i].vals[0])
+	// nested is the indexes of the begin and end tokens of the nested blocks.
+	var nested [][2]int
+	for j := i + 1; j < len(p.tokens); j++ {
+		switch p.tokens[j].kind {
+		case KindBlockBegin:
+			if !isGreaterBlock(name) || len(p.tokens[j].vals) == 0 {
+				continue
+			}
+			end, err := p.blockEnd(j)
+			if err != nil {
+				return 0, err
+			}
+			nested = append(nested, [2]int{j, end})
+			j = end
+		case KindBlockEnd:
+			got := strings.ToUpper(p.tokens[j].vals[0])
+			if got != name && !isGreaterBlock(name) {
+				// contents of other blocks are verbatim.
+				continue
+			}
+			if got != name {
+				return 0, fmt.Errorf("token[%d] is unexpected block end: got=%v, want=%v", j, got, name)
+			}
+			return j, nil
+		}
+	}
+	for _, r := range nested {
+		j := r[0]
+		for k := j + 1; k < r[1] && k < len(p.tokens); k++ {
+			if p.tokens[k].kind == KindBlockEnd && strings.EqualFold(p.tokens[k].vals[0], name) {
+				return 0, fmt.Errorf("%s block at line %d is not closed before the end of %s block at line %d",
+					strings.ToUpper(p.tokens[j].vals[0]), p.tokens[j].pos.Line, name, p.tokens[k].pos.Line)
+			}
+		}
+	}
+	return len(p.tokens), nil
+}
+
+var escapedLineRegexp = regexp.MustCompile(`^(\s*),(,*(?:\*|#\+))`)
+
+// blockContent returns the original lines of tokens. The common indentation
+// and the comma escapes of lines such as ",* text" are removed.
+func blockContent(tokens []Token) string {
+	lines := make([]string, len(tokens))
+	indent := -1
+	for i := range tokens {
+		lines[i] = strings.TrimRight(tokens[i].text(), " \t")
+		if lines[i] == "" {
+			continue
+		}
+		if n := len(lines[i]) - len(strings.TrimLeft(lines[i], " \t")); indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	for i := range lines {
+		if len(lines[i]) >= indent && indent > 0 {
+			lines[i] = lines[i][indent:]
+		}
+		lines[i] = escapedLineRegexp.ReplaceAllString(lines[i], "$1$2")
+	}
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n")
+}
+
+// escapeBlockContent escapes lines which can be parsed as headlines or
+// keywords with a comma. It is the reverse of blockContent.
+func escapeBlockContent(content string) string {
+	return blockEscapeRegexp.ReplaceAllString(content, "$1,$2")
+}
+
+var blockEscapeRegexp = regexp.MustCompile(`(?m)^(\s*)(,*(?:\*|#\+))`)
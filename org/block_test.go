@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"errors"
 	"reflect"
+	"strings"
 	"testing"
 
 	. "github.com/Ladicle/org2html/org"
@@ -128,7 +129,11 @@ func TestParseBlock(t *testing.T) {
 				NewToken(KindText, 1, []string{"world"}),
 				NewToken(KindBlockEnd, 1, []string{"QUOTE", ""}),
 			},
-			wantNode:     Block{Name: "QUOTE", Content: "hello\nworld"},
+			wantNode: Block{
+				Name:     "QUOTE",
+				Content:  "hello\nworld",
+				Children: Nodes{Section{Paragraphs: []string{"hello\nworld"}}},
+			},
 			wantConsumed: 4,
 		},
 		{
@@ -162,6 +167,21 @@ func TestParseBlock(t *testing.T) {
 			},
 			wantConsumed: 5,
 		},
+		{
+			desc: "source code block with switches",
+			tokens: []Token{
+				NewToken(KindBlockBegin, 1, []string{"SRC", "go -n 10 :hl_lines 2"}),
+				NewToken(KindText, 1, []string{"package main"}),
+				NewToken(KindBlockEnd, 1, []string{"SRC", ""}),
+			},
+			wantNode: SourceBlock{
+				Language:   "go",
+				SourceCode: "package main",
+				Switches:   "-n 10",
+				Property:   []string{"hl_lines 2"},
+			},
+			wantConsumed: 3,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.desc, func(t *testing.T) {
@@ -185,6 +205,48 @@ func TestParseBlock(t *testing.T) {
 	}
 }
 
+func TestParseNestedBlocks(t *testing.T) {
+	nodes := parseString(t, `#+begin_quote
+#+begin_quote
+inner
+#+end_quote
+#+begin_src sh
+#+end_quote
+#+end_src
+- item
+#+end_quote
+`)
+	want := []Node{
+		Block{
+			Name:    "QUOTE",
+			Content: "#+begin_quote\ninner\n#+end_quote\n#+begin_src sh\n#+end_quote\n#+end_src\n- item",
+			Children: Nodes{
+				Block{
+					Name:     "QUOTE",
+					Content:  "inner",
+					Children: Nodes{Section{Paragraphs: []string{"inner"}}},
+				},
+				SourceBlock{Language: "sh", SourceCode: "#+end_quote"},
+				List{Items: []ListItem{{Bullet: "-", Content: "item"}}},
+			},
+		},
+	}
+	if got := withoutPos(nodes); !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected nodes:\ngot=%#v\nwant=%#v", got, want)
+	}
+}
+
+func TestParseUnclosedNestedBlock(t *testing.T) {
+	tokens, err := DefaultTokenizer().Tokenize(strings.NewReader("#+begin_quote\n#+begin_src sh\necho\n#+end_quote\n* Headline\n#+begin_src go\n#+end_src\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: err=%v", err)
+	}
+	want := "SRC block at line 2 is not closed before the end of QUOTE block at line 4"
+	if _, err := DefaultParser(tokens).Parse(); err == nil || err.Error() != want {
+		t.Errorf("unexpected error: err=%v, want=%v", err, want)
+	}
+}
+
 func TestBlockWriter(t *testing.T) {
 	var tests = []struct {
 		desc    string
@@ -192,12 +254,65 @@ func TestBlockWriter(t *testing.T) {
 		wantOut string
 	}{
 		{
-			desc: "normal block",
+			desc: "custom block",
+			block: Block{
+				Name: "INFO",
+				Children: Nodes{
+					Section{Paragraphs: []string{"hello\nworld"}},
+					List{Items: []ListItem{{Bullet: "-", Content: "item"}}},
+				},
+			},
+			wantOut: "<div class=\"info\">\n<p>hello world</p>\n<ul class=\"org-list\">\n<li>item</li>\n</ul>\n</div>\n",
+		},
+		{
+			desc: "quote block",
+			block: Block{
+				Name:     "QUOTE",
+				Children: Nodes{Section{Paragraphs: []string{"hello", "world"}}},
+			},
+			wantOut: "<blockquote>\n<p>hello</p>\n<p>world</p>\n</blockquote>\n",
+		},
+		{
+			desc: "center block",
 			block: Block{
-				Name:    "info",
-				Content: "hello\nworld",
+				Name:     "CENTER",
+				Children: Nodes{Section{Paragraphs: []string{"hello"}}},
 			},
-			wantOut: "<div class=\"org-block block-info\">\nhello\nworld\n</div>\n",
+			wantOut: "<div class=\"org-center\">\n<p>hello</p>\n</div>\n",
+		},
+		{
+			desc: "example block",
+			block: Block{
+				Name:    "EXAMPLE",
+				Content: "* <b>\n  indented",
+			},
+			wantOut: "<pre class=\"example\">\n* &lt;b&gt;\n  indented\n</pre>\n",
+		},
+		{
+			desc: "verse block",
+			block: Block{
+				Name:    "VERSE",
+				Content: "Great clouds\n  overhead",
+			},
+			wantOut: "<p class=\"verse\">\nGreat clouds<br />\n&#xa0;&#xa0;overhead\n</p>\n",
+		},
+		{
+			desc: "html export block",
+			block: Block{
+				Name:       "EXPORT",
+				Parameters: "HTML",
+				Content:    "<br>",
+			},
+			wantOut: "<br>\n",
+		},
+		{
+			desc: "latex export block",
+			block: Block{
+				Name:       "EXPORT",
+				Parameters: "latex",
+				Content:    "\\newpage",
+			},
+			wantOut: "",
 		},
 	}
 	for _, tt := range tests {
@@ -247,3 +362,212 @@ echo 'hello world!'
 		})
 	}
 }
+
+func TestSrcBlockHTMLWriter(t *testing.T) {
+	var tests = []struct {
+		desc    string
+		writer  HTMLWriter
+		nodes   []Node
+		wantOut string
+		wantErr error
+	}{
+		{
+			desc:   "escape plain text",
+			writer: DefaultHTMLWriter(),
+			nodes: []Node{SourceBlock{
+				Language:   "html",
+				SourceCode: "<p>a & b</p>",
+			}},
+			wantOut: `<div class="org-block block-src">
+<code class="block lang-html" data-lang="html">
+&lt;p&gt;a &amp; b&lt;/p&gt;
+</code>
+</div>
+`,
+		},
+		{
+			desc:   "highlight with classes",
+			writer: HTMLWriter{Highlighter: DefaultHighlighter()},
+			nodes: []Node{SourceBlock{
+				Language:   "go",
+				SourceCode: "return 1 // one",
+			}},
+			wantOut: `<div class="org-block block-src">
+<code class="block lang-go" data-lang="go">
+<span class="hl-keyword">return</span> <span class="hl-number">1</span> <span class="hl-comment">// one</span>
+</code>
+</div>
+`,
+		},
+		{
+			desc:   "highlight with inline style",
+			writer: HTMLWriter{Highlighter: DefaultHighlighter(), HighlightStyle: map[string]string{HighlightString: "color:red"}},
+			nodes: []Node{SourceBlock{
+				Language:   "bash",
+				SourceCode: "echo \"<hi>\"",
+			}},
+			wantOut: `<div class="org-block block-src">
+<code class="block lang-bash" data-lang="bash">
+echo <span style="color:red">"&lt;hi&gt;"</span>
+</code>
+</div>
+`,
+		},
+		{
+			desc:   "unknown language",
+			writer: HTMLWriter{Highlighter: DefaultHighlighter()},
+			nodes: []Node{SourceBlock{
+				Language:   "unknown",
+				SourceCode: "return 1",
+			}},
+			wantOut: `<div class="org-block block-src">
+<code class="block lang-unknown" data-lang="unknown">
+return 1
+</code>
+</div>
+`,
+		},
+		{
+			desc:   "line numbers",
+			writer: HTMLWriter{Highlighter: DefaultHighlighter()},
+			nodes: []Node{
+				SourceBlock{
+					Language:   "go",
+					SourceCode: "/* a\nb */",
+					Switches:   "-n 10",
+				},
+				SourceBlock{
+					Language:   "text",
+					SourceCode: "c",
+					Switches:   "+n",
+				},
+				SourceBlock{
+					Language:   "text",
+					SourceCode: "d",
+					Switches:   "+n 5",
+				},
+			},
+			wantOut: `<div class="org-block block-src">
+<code class="block lang-go" data-lang="go">
+<span class="line"><span class="ln">10</span><span class="hl-comment">/* a</span></span>
+<span class="line"><span class="ln">11</span><span class="hl-comment">b */</span></span>
+</code>
+</div>
+<div class="org-block block-src">
+<code class="block lang-text" data-lang="text">
+<span class="line"><span class="ln">12</span>c</span>
+</code>
+</div>
+<div class="org-block block-src">
+<code class="block lang-text" data-lang="text">
+<span class="line"><span class="ln">17</span>d</span>
+</code>
+</div>
+`,
+		},
+		{
+			desc:   "highlighted lines",
+			writer: DefaultHTMLWriter(),
+			nodes: []Node{SourceBlock{
+				Language:   "text",
+				SourceCode: "a\nb\nc\nd",
+				Property:   []string{"hl_lines 1,3-4"},
+			}},
+			wantOut: `<div class="org-block block-src">
+<code class="block lang-text" data-lang="text">
+<span class="line hl">a</span>
+<span class="line">b</span>
+<span class="line hl">c</span>
+<span class="line hl">d</span>
+</code>
+</div>
+`,
+		},
+		{
+			desc:   "highlighted lines out of the block",
+			writer: DefaultHTMLWriter(),
+			nodes: []Node{SourceBlock{
+				Language:   "text",
+				SourceCode: "a\nb",
+				Property:   []string{"hl_lines 2-5000000000"},
+			}},
+			wantOut: `<div class="org-block block-src">
+<code class="block lang-text" data-lang="text">
+<span class="line">a</span>
+<span class="line hl">b</span>
+</code>
+</div>
+`,
+		},
+		{
+			desc:   "exports none",
+			writer: DefaultHTMLWriter(),
+			nodes: []Node{SourceBlock{
+				Language:   "text",
+				SourceCode: "a",
+				Header:     []string{"exports none"},
+			}},
+			wantOut: "",
+		},
+		{
+			desc:   "details",
+			writer: DefaultHTMLWriter(),
+			nodes: []Node{
+				SourceBlock{
+					Language:   "text",
+					SourceCode: "a",
+					Property:   []string{"details t"},
+				},
+				SourceBlock{
+					Language:   "text",
+					SourceCode: "b",
+					Property:   []string{"details Show code"},
+				},
+			},
+			wantOut: `<details>
+<summary>text</summary>
+<div class="org-block block-src">
+<code class="block lang-text" data-lang="text">
+a
+</code>
+</div>
+</details>
+<details>
+<summary>Show code</summary>
+<div class="org-block block-src">
+<code class="block lang-text" data-lang="text">
+b
+</code>
+</div>
+</details>
+`,
+		},
+		{
+			desc:   "invalid highlighted lines",
+			writer: DefaultHTMLWriter(),
+			nodes: []Node{SourceBlock{
+				Language:   "text",
+				SourceCode: "a",
+				Property:   []string{"hl_lines a-b"},
+			}},
+			wantErr: errors.New(`invalid hl_lines of source block: "a-b"`),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var out bytes.Buffer
+			err := tt.writer.Write(tt.nodes, &out)
+			if err != nil {
+				if tt.wantErr == nil || err.Error() != tt.wantErr.Error() {
+					t.Fatalf("unexpected error: err=%v, want=%v", err, tt.wantErr)
+				}
+				return
+			} else if tt.wantErr != nil {
+				t.Fatalf("expect error but not occurred: want=%v", tt.wantErr)
+			}
+			if got := out.String(); got != tt.wantOut {
+				t.Errorf("unexpected output:\ngot=%v\nwant=%v", got, tt.wantOut)
+			}
+		})
+	}
+}
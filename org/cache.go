@@ -0,0 +1,339 @@
+package org
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const defaultBuildCacheFileName = ".org2html-cache.json"
+
+// BuildCache is the settings of incremental builds of the Project. The
+// manifest of the build is kept in the published directory, which records
+// the content hashes of the documents and their dependencies such as
+// #+INCLUDE, #+SETUPFILE and links to the other documents. The next build
+// renders only the documents which are changed or whose dependencies are
+// changed. All documents are rendered if the settings of the Project are
+// changed.
+type BuildCache struct {
+	// FileName is the path of the manifest in the published directory.
+	// ".org2html-cache.json" is used if it is empty.
+	FileName string
+	// Force renders all documents ignoring the manifest. The pages of the
+	// documents which are removed since the last build are still removed.
+	Force bool
+	// Version is recorded as a part of the settings, such as the version of
+	// the program, so that all documents are rendered after changing it.
+	// Highlighter and MathRenderer of the Project.Writer are compared only by
+	// their types, so it should be changed with their settings.
+	Version string
+}
+
+func (c BuildCache) fileName() string {
+	if c.FileName == "" {
+		return defaultBuildCacheFileName
+	}
+	return c.FileName
+}
+
+// buildManifest is the record of the last build.
+type buildManifest struct {
+	// Settings is the hash of the settings of the Project.
+	Settings string                `json:"settings"`
+	Files    map[string]buildEntry `json:"files"`
+}
+
+// buildEntry is the record of the rendered document.
+type buildEntry struct {
+	// Hash is the content hash of the document.
+	Hash string `json:"hash"`
+	// Deps maps the files of #+INCLUDE and #+SETUPFILE to the content hashes.
+	Deps map[string]string `json:"deps,omitempty"`
+	// IDs is the CUSTOM_ID and ID properties of the headlines for links from
+	// the other documents.
+	IDs []string `json:"ids,omitempty"`
+	// Links maps the `file:` and `id:` link targets to the resolved URLs,
+	// which are empty if they are not documents of the project.
+	Links map[string]string `json:"links,omitempty"`
+}
+
+func readBuildManifest(name string) (buildManifest, error) {
+	m := buildManifest{Files: make(map[string]buildEntry)}
+	data, err := os.ReadFile(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return m, nil
+	} else if err != nil {
+		return m, err
+	}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return m, err
+	}
+	if m.Files == nil {
+		m.Files = make(map[string]buildEntry)
+	}
+	return m, nil
+}
+
+func hashContent(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// settingsHash returns the hash of the settings which change the pages.
+func (p Project) settingsHash() (string, error) {
+	settings := struct {
+		Version        string
+		BaseURL        string
+		Sitemap        *Sitemap
+		Feed           *Feed
+		Highlighter    string
+		HighlightStyle map[string]string
+		MathRenderer   string
+		WriterBaseURL  string
+		MacroEnv       MacroEnv
+	}{
+		Version:        p.Cache.Version,
+		BaseURL:        p.BaseURL,
+		Sitemap:        p.Sitemap,
+		Feed:           p.Feed,
+		Highlighter:    fmt.Sprintf("%T", p.Writer.Highlighter),
+		HighlightStyle: p.Writer.HighlightStyle,
+		MathRenderer:   fmt.Sprintf("%T", p.Writer.MathRenderer),
+		WriterBaseURL:  p.Writer.BaseURL,
+		MacroEnv:       p.Writer.MacroEnv,
+	}
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return "", err
+	}
+	return hashContent(data), nil
+}
+
+// build is the state of Project.Publish, which decides the documents to be
+// rendered from the manifest of the last build.
+type build struct {
+	project Project
+	dir     string
+	files   []string
+	// hashes maps the documents to the content hashes.
+	hashes   map[string]string
+	last     buildManifest
+	manifest buildManifest
+	// rebuild renders all documents because of Force or the changed settings.
+	rebuild bool
+}
+
+func (p Project) newBuild(dir string, files []string) (*build, error) {
+	b := &build{
+		project:  p,
+		dir:      dir,
+		files:    files,
+		hashes:   make(map[string]string),
+		last:     buildManifest{Files: make(map[string]buildEntry)},
+		manifest: buildManifest{Files: make(map[string]buildEntry)},
+	}
+	if p.Cache == nil {
+		return b, nil
+	}
+	settings, err := p.settingsHash()
+	if err != nil {
+		return nil, err
+	}
+	b.manifest.Settings = settings
+	// the manifest is read even with Force to remove the pages of the removed
+	// documents, and a broken manifest is ignored in that case.
+	last, err := readBuildManifest(filepath.Join(dir, filepath.FromSlash(p.Cache.fileName())))
+	if err != nil && !p.Cache.Force {
+		return nil, err
+	} else if err == nil {
+		b.last = last
+	}
+	b.rebuild = p.Cache.Force || b.last.Settings != settings
+	for _, name := range files {
+		data, err := fs.ReadFile(p.FS, name)
+		if err != nil {
+			return nil, err
+		}
+		b.hashes[name] = hashContent(data)
+	}
+	return b, nil
+}
+
+// stale returns the documents which are changed or whose dependencies are
+// changed since the last build, or whose pages do not exist.
+func (b *build) stale() []string {
+	if b.project.Cache == nil || b.rebuild {
+		return b.files
+	}
+	var stale []string
+	for _, name := range b.files {
+		if !b.upToDate(name) {
+			stale = append(stale, name)
+		}
+	}
+	return stale
+}
+
+func (b *build) upToDate(name string) bool {
+	entry, ok := b.last.Files[name]
+	if !ok || entry.Hash != b.hashes[name] {
+		return false
+	}
+	if _, err := os.Stat(filepath.Join(b.dir, filepath.FromSlash(Document{Path: name}.HTMLPath()))); err != nil {
+		return false
+	}
+	loader := Loader{FS: b.project.FS, HomeFS: b.project.HomeFS}
+	for dep, hash := range entry.Deps {
+		data, err := loader.readFile(dep)
+		if err != nil || hashContent(data) != hash {
+			return false
+		}
+	}
+	return true
+}
+
+// links returns the ProjectLinks of the loaded documents and the other
+// documents whose IDs are recorded in the last build.
+func (b *build) links(loaded []Document) *ProjectLinks {
+	ids := make(map[string][]string)
+	for _, doc := range loaded {
+		ids[doc.Path] = documentIDs(doc.Nodes)
+	}
+	links := newProjectLinks()
+	for _, name := range b.files {
+		if docIDs, ok := ids[name]; ok {
+			links.add(name, docIDs)
+		} else {
+			links.add(name, b.last.Files[name].IDs)
+		}
+	}
+	return links
+}
+
+// relinked returns the documents which are not loaded but whose links are
+// resolved differently from the last build, such as links to the removed
+// documents.
+func (b *build) relinked(loaded []Document, links *ProjectLinks) []string {
+	if b.project.Cache == nil {
+		return nil
+	}
+	skip := make(map[string]bool)
+	for _, doc := range loaded {
+		skip[doc.Path] = true
+	}
+	var relinked []string
+	for _, name := range b.files {
+		if skip[name] {
+			continue
+		}
+		for target, want := range b.last.Files[name].Links {
+			if got, _ := links.Resolve(name, target); got != want {
+				relinked = append(relinked, name)
+				break
+			}
+		}
+	}
+	return relinked
+}
+
+// update records the rendered document in the manifest.
+func (b *build) update(doc Document, links *ProjectLinks) error {
+	if b.project.Cache == nil {
+		return nil
+	}
+	entry := buildEntry{Hash: b.hashes[doc.Path], IDs: documentIDs(doc.Nodes)}
+	loader := Loader{FS: b.project.FS, HomeFS: b.project.HomeFS}
+	for _, dep := range doc.Deps {
+		data, err := loader.readFile(dep)
+		if err != nil {
+			return err
+		}
+		if entry.Deps == nil {
+			entry.Deps = make(map[string]string)
+		}
+		entry.Deps[dep] = hashContent(data)
+	}
+	for _, target := range linkTargets(doc.Nodes) {
+		if !strings.HasPrefix(target, "file:") && !strings.HasPrefix(target, "id:") {
+			continue
+		}
+		if entry.Links == nil {
+			entry.Links = make(map[string]string)
+		}
+		entry.Links[target], _ = links.Resolve(doc.Path, target)
+	}
+	b.manifest.Files[doc.Path] = entry
+	return nil
+}
+
+// removePages removes the pages of the documents which are removed since the
+// last build, and returns the number of them.
+func (b *build) removePages() (int, error) {
+	exists := make(map[string]bool)
+	for _, name := range b.files {
+		exists[name] = true
+	}
+	var removed int
+	for name := range b.last.Files {
+		if exists[name] {
+			continue
+		}
+		err := os.Remove(filepath.Join(b.dir, filepath.FromSlash(Document{Path: name}.HTMLPath())))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// documents returns all documents in the order of the files. The documents
+// which are not loaded yet are loaded.
+func (b *build) documents(loaded []Document) ([]Document, error) {
+	docs := make(map[string]Document)
+	for _, doc := range loaded {
+		docs[doc.Path] = doc
+	}
+	var rest []string
+	for _, name := range b.files {
+		if _, ok := docs[name]; !ok {
+			rest = append(rest, name)
+		}
+	}
+	more, err := b.project.loadFiles(rest)
+	if err != nil {
+		return nil, err
+	}
+	for _, doc := range more {
+		docs[doc.Path] = doc
+	}
+	ret := make([]Document, len(b.files))
+	for i, name := range b.files {
+		ret[i] = docs[name]
+	}
+	return ret, nil
+}
+
+// save writes the manifest. The records of the documents which are not
+// rendered are kept from the last build.
+func (b *build) save() error {
+	if b.project.Cache == nil {
+		return nil
+	}
+	for _, name := range b.files {
+		if _, ok := b.manifest.Files[name]; !ok {
+			b.manifest.Files[name] = b.last.Files[name]
+		}
+	}
+	data, err := json.MarshalIndent(b.manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeOutputFile(b.dir, b.project.Cache.fileName(), append(data, '\n'))
+}
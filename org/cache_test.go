@@ -0,0 +1,113 @@
+package org_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"testing/fstest"
+
+	. "github.com/Ladicle/org2html/org"
+)
+
+func TestProjectPublishCache(t *testing.T) {
+	var (
+		dir   = t.TempDir()
+		files = fstest.MapFS{
+			"index.org": {Data: []byte("[[file:a.org][a]] [[id:top][top]]\n")},
+			"a.org":     {Data: []byte("#+INCLUDE: inc.org\n")},
+			"inc.org":   {Data: []byte("included\n")},
+			"b.org":     {Data: []byte("* Top\n:PROPERTIES:\n:ID: top\n:END:\n")},
+			"c.org":     {Data: []byte("c\n")},
+		}
+	)
+	var tests = []struct {
+		desc         string
+		change       func()
+		force        bool
+		baseURL      string
+		wantRendered []string
+	}{
+		{
+			desc:         "first build",
+			change:       func() {},
+			wantRendered: []string{"a.html", "b.html", "c.html", "inc.html", "index.html"},
+		},
+		{
+			desc:   "no changes",
+			change: func() {},
+		},
+		{
+			desc:         "included file",
+			change:       func() { files["inc.org"] = &fstest.MapFile{Data: []byte("changed\n")} },
+			wantRendered: []string{"a.html", "inc.html"},
+		},
+		{
+			desc: "moved id",
+			change: func() {
+				files["b.org"] = &fstest.MapFile{Data: []byte("b\n")}
+				files["c.org"] = &fstest.MapFile{Data: []byte("* Top\n:PROPERTIES:\n:ID: top\n:END:\n")}
+			},
+			wantRendered: []string{"b.html", "c.html", "index.html"},
+		},
+		{
+			desc:         "removed document",
+			change:       func() { delete(files, "a.org") },
+			wantRendered: []string{"index.html"},
+		},
+		{
+			desc:         "changed settings",
+			change:       func() {},
+			baseURL:      "https://example.com/",
+			wantRendered: []string{"b.html", "c.html", "inc.html", "index.html"},
+		},
+		{
+			desc:         "force with removed document",
+			change:       func() { delete(files, "c.org") },
+			force:        true,
+			baseURL:      "https://example.com/",
+			wantRendered: []string{"b.html", "inc.html", "index.html"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			tt.change()
+			project := Project{FS: files, BaseURL: tt.baseURL, Cache: &BuildCache{Force: tt.force}}
+			if err := project.Publish(dir); err != nil {
+				t.Fatalf("unexpected error: err=%v", err)
+			}
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatalf("unexpected error: err=%v", err)
+			}
+			var rendered []string
+			for _, e := range entries {
+				if filepath.Ext(e.Name()) != ".html" {
+					continue
+				}
+				name := filepath.Join(dir, e.Name())
+				data, err := os.ReadFile(name)
+				if err != nil {
+					t.Fatalf("unexpected error: err=%v", err)
+				}
+				if string(data) != "stale" {
+					rendered = append(rendered, e.Name())
+				}
+				// mark the page to find whether it is rendered in the next build.
+				if err := os.WriteFile(name, []byte("stale"), 0o644); err != nil {
+					t.Fatalf("unexpected error: err=%v", err)
+				}
+			}
+			sort.Strings(rendered)
+			if !reflect.DeepEqual(rendered, tt.wantRendered) {
+				t.Errorf("unexpected rendered pages: got=%v, want=%v", rendered, tt.wantRendered)
+			}
+		})
+	}
+	for _, name := range []string{"a.html", "c.html"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+			t.Errorf("page of the removed document exists: name=%v, err=%v", name, err)
+		}
+	}
+}
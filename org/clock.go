@@ -0,0 +1,106 @@
+package org
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+const clockKey = "CLOCK"
+
+var _ Node = Clock{}
+
+// Clock is a Node to describe clock lines such as
+// `CLOCK: [2022-01-31 Mon 10:00]--[2022-01-31 Mon 11:30] =>  1:30`. End is nil
+// while the clock is running.
+type Clock struct {
+	Pos      Pos           `json:"pos"`
+	Start    Timestamp     `json:"start"`
+	End      *Timestamp    `json:"end,omitempty"`
+	Duration time.Duration `json:"duration,omitempty"`
+}
+
+// Write does nothing because clocks are not exported by default like
+// org-export-with-clocks.
+func (c Clock) Write(w io.Writer) error {
+	// noop
+	return nil
+}
+
+// Literal returns the clock line.
+func (c Clock) Literal() string {
+	s := fmt.Sprintf("%s: %s", clockKey, c.Start.Literal(false))
+	if c.End != nil {
+		s += fmt.Sprintf("--%s => %s", c.End.Literal(false), formatClockDuration(c.Duration, 2))
+	}
+	return s
+}
+
+// formatClockDuration formats the duration as H:MM. Hours are padded to the
+// width.
+func formatClockDuration(d time.Duration, width int) string {
+	minutes := int(d / time.Minute)
+	return fmt.Sprintf("%*d:%02d", width, minutes/60, minutes%60)
+}
+
+var clockRegexp = regexp.MustCompile(
+	`^\s*CLOCK:\s+\[([^\]]+)\](?:--\[([^\]]+)\](?:\s+=>\s+(\d+):(\d{2}))?)?\s*$`)
+
+func LexClock(line string) (Token, bool) {
+	if m := clockRegexp.FindStringSubmatch(line); m != nil {
+		return NewToken(KindClock, 1, m[1:]), true
+	}
+	return Token{}, false
+}
+
+// validClock reports whether the timestamps of the clock token are valid.
+func validClock(t Token) bool {
+	if len(t.vals) != 4 {
+		return false
+	}
+	if _, err := ParseTimestamp(t.vals[0], ""); err != nil {
+		return false
+	}
+	if t.vals[1] == "" {
+		return true
+	}
+	_, err := ParseTimestamp(t.vals[1], "")
+	return err == nil
+}
+
+// ParseClock parses the clock line. The line is a part of the paragraph if
+// the timestamps are not valid, such as with unknown day names.
+func ParseClock(p *Parser, i int) (int, Node, error) {
+	// 0: start, 1: end, 2: hours, 3: minutes
+	vals := p.tokens[i].vals
+	if len(vals) != 4 {
+		return 0, nil, fmt.Errorf("clock token[%d] does not have 4 values: got=%d", i, len(vals))
+	}
+	if !validClock(p.tokens[i]) {
+		return ParseSection(p, i)
+	}
+	start, err := ParseTimestamp(vals[0], "")
+	if err != nil {
+		return 0, nil, err
+	}
+	clock := Clock{Pos: p.tokens[i].pos, Start: start}
+	if vals[1] == "" {
+		return 1, clock, nil
+	}
+
+	end, err := ParseTimestamp(vals[1], "")
+	if err != nil {
+		return 0, nil, err
+	}
+	clock.End = &end
+	if vals[2] == "" {
+		clock.Duration = end.Time.Sub(start.Time)
+		return 1, clock, nil
+	}
+	hours, _ := strconv.Atoi(vals[2])
+	minutes, _ := strconv.Atoi(vals[3])
+	clock.Duration = time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute
+	return 1, clock, nil
+}
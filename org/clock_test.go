@@ -0,0 +1,106 @@
+package org_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	. "github.com/Ladicle/org2html/org"
+)
+
+func TestLexClock(t *testing.T) {
+	var tests = []struct {
+		desc      string
+		line      string
+		wantToken Token
+		wantFlag  bool
+	}{
+		{
+			desc: "empty line",
+			line: "",
+		},
+		{
+			desc:     "closed clock",
+			line:     "  CLOCK: [2022-01-31 Mon 10:00]--[2022-01-31 Mon 11:30] =>  1:30",
+			wantFlag: true,
+			wantToken: NewToken(KindClock, 1, []string{
+				"2022-01-31 Mon 10:00", "2022-01-31 Mon 11:30", "1", "30",
+			}),
+		},
+		{
+			desc:      "running clock",
+			line:      "CLOCK: [2022-01-31 Mon 10:00]",
+			wantFlag:  true,
+			wantToken: NewToken(KindClock, 1, []string{"2022-01-31 Mon 10:00", "", "", ""}),
+		},
+		{
+			desc: "text",
+			line: "CLOCK: is a keyword",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			token, flag := LexClock(tt.line)
+			if flag != tt.wantFlag {
+				t.Errorf("unexpected flag: got=%v, want=%v", flag, tt.wantFlag)
+			}
+			if !reflect.DeepEqual(token, tt.wantToken) {
+				t.Errorf("unexpected token:\ngot=%#v\nwant=%#v", token, tt.wantToken)
+			}
+		})
+	}
+}
+
+func TestParseClock(t *testing.T) {
+	start := mustParseTimestamp(t, "2022-01-31 Mon 10:00", "")
+	end := mustParseTimestamp(t, "2022-01-31 Mon 11:30", "")
+	var tests = []struct {
+		desc     string
+		token    Token
+		wantNode Node
+	}{
+		{
+			desc:     "closed clock",
+			token:    NewToken(KindClock, 1, []string{"2022-01-31 Mon 10:00", "2022-01-31 Mon 11:30", "2", "05"}),
+			wantNode: Clock{Start: start, End: &end, Duration: 2*time.Hour + 5*time.Minute},
+		},
+		{
+			desc:     "clock without duration",
+			token:    NewToken(KindClock, 1, []string{"2022-01-31 Mon 10:00", "2022-01-31 Mon 11:30", "", ""}),
+			wantNode: Clock{Start: start, End: &end, Duration: 90 * time.Minute},
+		},
+		{
+			desc:     "running clock",
+			token:    NewToken(KindClock, 1, []string{"2022-01-31 Mon 10:00", "", "", ""}),
+			wantNode: Clock{Start: start},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			parser := DefaultParser([]Token{tt.token})
+			consumed, node, err := ParseClock(&parser, 0)
+			if err != nil {
+				t.Fatalf("unexpected error: err=%v", err)
+			}
+			if consumed != 1 {
+				t.Errorf("unexpected consumed: got=%v, want=1", consumed)
+			}
+			if !reflect.DeepEqual(node, tt.wantNode) {
+				t.Errorf("unexpected node:\ngot=%#v\nwant=%#v", node, tt.wantNode)
+			}
+			if got, want := node.(Clock).Literal(), tt.wantNode.(Clock).Literal(); got != want {
+				t.Errorf("unexpected literal: got=%v, want=%v", got, want)
+			}
+		})
+	}
+}
+
+func TestParseInvalidClock(t *testing.T) {
+	nodes := parseString(t, "note\nCLOCK: [2022-01-31 月 10:00]--[2022-01-31 月 11:00] =>  1:00\nCLOCK: [yesterday]\n")
+	want := []Node{
+		Section{Paragraphs: []string{"note\nCLOCK: [2022-01-31 月 10:00]--[2022-01-31 月 11:00] =>  1:00\nCLOCK: [yesterday]"}},
+	}
+	if got := withoutPos(nodes); !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected nodes:\ngot=%#v\nwant=%#v", got, want)
+	}
+}
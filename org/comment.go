@@ -9,7 +9,8 @@ import (
 var _ Node = Comment{}
 
 type Comment struct {
-	Message string
+	Pos     Pos    `json:"pos"`
+	Message string `json:"message"`
 }
 
 func (c Comment) Write(w io.Writer) error {
@@ -36,5 +37,5 @@ func ParseComment(p *Parser, i int) (int, Node, error) {
 	if msg == "" {
 		return 1, nil, nil
 	}
-	return 1, Comment{Message: msg}, nil
+	return 1, Comment{Pos: p.tokens[i].pos, Message: msg}, nil
 }
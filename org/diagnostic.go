@@ -0,0 +1,30 @@
+package org
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Diagnostic is a problem of the document which does not prevent writing it,
+// such as a reference to an undefined footnote.
+type Diagnostic struct {
+	Pos     Pos
+	Message string
+}
+
+func (d Diagnostic) String() string {
+	if d.Pos.Line == 0 {
+		return d.Message
+	}
+	return fmt.Sprintf("%d: %s", d.Pos.Line, d.Message)
+}
+
+// Diagnose returns the problems of the document in order of the position.
+func Diagnose(nodes []Node) []Diagnostic {
+	diags := append(footnoteDiagnostics(nodes), macroDiagnostics(nodes)...)
+	diags = append(diags, drawerDiagnostics(nodes)...)
+	sort.SliceStable(diags, func(i, j int) bool {
+		return diags[i].Pos.Line < diags[j].Pos.Line
+	})
+	return diags
+}
@@ -0,0 +1,216 @@
+package org
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+const (
+	propertiesDrawerName = "PROPERTIES"
+	logbookDrawerName    = "LOGBOOK"
+	drawerEndName        = "END"
+)
+
+var _ Node = Drawer{}
+
+// Drawer is a Node to describe drawers such as :LOGBOOK: except the property
+// drawer. Children is the parsed Content.
+type Drawer struct {
+	Pos      Pos    `json:"pos"`
+	Name     string `json:"name"`
+	Content  string `json:"content"`
+	Children Nodes  `json:"children,omitempty"`
+}
+
+var _ Node = PropertyDrawer{}
+
+// PropertyDrawer is a Node to describe the :PROPERTIES: drawer of headlines.
+type PropertyDrawer struct {
+	Pos        Pos        `json:"pos"`
+	Properties []Property `json:"properties"`
+}
+
+type Property struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// Get returns the value of the property. Property keys are case-insensitive.
+// The values of the key with the trailing `+` such as `:header-args+:` are
+// appended to the value with spaces, and the key without `+` replaces it.
+func (d PropertyDrawer) Get(key string) (string, bool) {
+	var (
+		value string
+		found bool
+	)
+	for _, p := range d.Properties {
+		switch {
+		case strings.EqualFold(p.Key, key):
+			value, found = p.Value, true
+		case strings.EqualFold(p.Key, key+"+"):
+			if found {
+				value += " " + p.Value
+			} else {
+				value, found = p.Value, true
+			}
+		}
+	}
+	return value, found
+}
+
+// Write writes drawer as HTML elements. The logbook drawer and the properties
+// drawer which is not a PropertyDrawer are ignored.
+func (d Drawer) Write(w io.Writer) error {
+	return d.writeHTML(w, &htmlContext{HTMLWriter: DefaultHTMLWriter()})
+}
+
+func (d Drawer) writeHTML(w io.Writer, ctx *htmlContext) error {
+	if d.Name == logbookDrawerName || d.Name == propertiesDrawerName {
+		return nil
+	}
+	fmt.Fprintf(w, "<div class=\"org-drawer drawer-%s\">\n", strings.ToLower(d.Name))
+	if err := ctx.writeAll(w, d.Children); err != nil {
+		return err
+	}
+	fmt.Fprintln(w, "</div>")
+	return nil
+}
+
+func (d PropertyDrawer) Write(w io.Writer) error {
+	// noop
+	return nil
+}
+
+// drawerRegexp matches drawer lines. Property keys can have the trailing `+`
+// such as `:header-args+:` to append the value to the property.
+var drawerRegexp = regexp.MustCompile(`^\s*:([\w-]+\+?):(?:\s+(.*?))?\s*$`)
+
+// LexDrawer lexes drawer begin/end lines and properties. All of them have the
+// same syntax `:<name>: <value>` and are distinguished by the parser.
+func LexDrawer(line string) (Token, bool) {
+	if m := drawerRegexp.FindStringSubmatch(line); m != nil {
+		return NewToken(KindDrawer, 1, m[1:]), true
+	}
+	return Token{}, false
+}
+
+func ParseDrawer(p *Parser, i int) (int, Node, error) {
+	if len(p.tokens[i].vals) != 2 {
+		return 0, nil, fmt.Errorf("drawer token[%d] does not have 2 values: got=%d", i, len(p.tokens[i].vals))
+	}
+	name := strings.ToUpper(p.tokens[i].vals[0])
+
+	// the line is not a drawer, so it is a part of the paragraph.
+	end := p.drawerEnd(i)
+	if end < 0 {
+		return ParseSection(p, i)
+	}
+
+	if name != propertiesDrawerName || !p.onlyProperties(i+1, end) {
+		children, err := p.subParser(i+1, end).Parse()
+		if err != nil {
+			return 0, nil, err
+		}
+		return end - i + 1, Drawer{
+			Pos:      p.tokens[i].pos,
+			Name:     name,
+			Content:  blockContent(p.tokens[i+1 : end]),
+			Children: children,
+		}, nil
+	}
+
+	drawer := PropertyDrawer{Pos: p.tokens[i].pos}
+	for j := i + 1; j < end; j++ {
+		if p.tokens[j].kind != KindDrawer {
+			continue
+		}
+		drawer.Properties = append(drawer.Properties, Property{
+			Key:   p.tokens[j].vals[0],
+			Value: p.tokens[j].vals[1],
+		})
+	}
+	return end - i + 1, drawer, nil
+}
+
+// onlyProperties reports whether p.tokens[start:end] are properties or blank
+// lines. Otherwise, the :PROPERTIES: drawer is a regular drawer as Org mode,
+// which is reported by Diagnose.
+func (p *Parser) onlyProperties(start, end int) bool {
+	for _, t := range p.tokens[start:end] {
+		if t.kind == KindText && len(t.vals) > 0 && t.vals[0] == "" {
+			continue
+		}
+		if t.kind != KindDrawer {
+			return false
+		}
+	}
+	return true
+}
+
+// drawerEnd returns the index of the :END: token of the drawer p.tokens[i],
+// or -1 if the token does not begin a drawer. Drawer cannot contain
+// headlines.
+func (p *Parser) drawerEnd(i int) int {
+	t := p.tokens[i]
+	if len(t.vals) != 2 || strings.EqualFold(t.vals[0], drawerEndName) || t.vals[1] != "" {
+		return -1
+	}
+	for j := i + 1; j < len(p.tokens); j++ {
+		switch {
+		case p.tokens[j].kind == KindHeadline:
+			return -1
+		case p.tokens[j].kind == KindDrawer && strings.EqualFold(p.tokens[j].vals[0], drawerEndName):
+			return j
+		}
+	}
+	return -1
+}
+
+// drawerText returns the line of the drawer token which is not a drawer such
+// as `:smile: text`.
+func drawerText(t Token) string {
+	if t.raw != "" {
+		return strings.TrimSpace(t.raw)
+	}
+	line := ":" + t.vals[0] + ":"
+	if len(t.vals) > 1 && t.vals[1] != "" {
+		line += " " + t.vals[1]
+	}
+	return line
+}
+
+// HeadlineProperties returns the property drawer of the headline nodes[i].
+// The property drawer must be placed just after the headline or its agenda.
+func HeadlineProperties(nodes []Node, i int) (PropertyDrawer, bool) {
+	for i++; i < len(nodes); i++ {
+		switch n := nodes[i].(type) {
+		case Agenda:
+			continue
+		case PropertyDrawer:
+			return n, true
+		}
+		break
+	}
+	return PropertyDrawer{}, false
+}
+
+// drawerDiagnostics reports the :PROPERTIES: drawers which have lines other
+// than properties.
+func drawerDiagnostics(nodes []Node) []Diagnostic {
+	var diags []Diagnostic
+	for _, node := range nodes {
+		switch n := node.(type) {
+		case Drawer:
+			if n.Name == propertiesDrawerName {
+				diags = append(diags, Diagnostic{Pos: n.Pos, Message: "properties drawer has lines which are not properties"})
+			}
+		case Block:
+			diags = append(diags, drawerDiagnostics(n.Children)...)
+		case DynamicBlock:
+			diags = append(diags, drawerDiagnostics(n.Children)...)
+		}
+	}
+	return diags
+}
@@ -0,0 +1,237 @@
+package org_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	. "github.com/Ladicle/org2html/org"
+)
+
+func TestLexDrawer(t *testing.T) {
+	var tests = []struct {
+		desc      string
+		line      string
+		wantToken Token
+		wantFlag  bool
+	}{
+		{
+			desc:      "empty line",
+			line:      "",
+			wantToken: Token{},
+		},
+		{
+			desc:      "drawer begin",
+			line:      "  :PROPERTIES:",
+			wantFlag:  true,
+			wantToken: NewToken(KindDrawer, 1, []string{"PROPERTIES", ""}),
+		},
+		{
+			desc:      "property",
+			line:      ":EXPORT_FILE_NAME: my-post  ",
+			wantFlag:  true,
+			wantToken: NewToken(KindDrawer, 1, []string{"EXPORT_FILE_NAME", "my-post"}),
+		},
+		{
+			desc:      "appending property",
+			line:      ":header-args+: :var x=1",
+			wantFlag:  true,
+			wantToken: NewToken(KindDrawer, 1, []string{"header-args+", ":var x=1"}),
+		},
+		{
+			desc:      "fixed width",
+			line:      ": hello world!",
+			wantToken: Token{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			token, flag := LexDrawer(tt.line)
+			if flag != tt.wantFlag {
+				t.Errorf("unexpected flag: got=%v, want=%v", flag, tt.wantFlag)
+			}
+			if !reflect.DeepEqual(token, tt.wantToken) {
+				t.Errorf("unexpected token:\ngot=%#v\nwant=%#v", token, tt.wantToken)
+			}
+		})
+	}
+}
+
+func TestParseDrawer(t *testing.T) {
+	var tests = []struct {
+		desc         string
+		tokens       []Token
+		wantConsumed int
+		wantNode     Node
+	}{
+		{
+			desc: "property drawer",
+			tokens: []Token{
+				NewToken(KindDrawer, 1, []string{"properties", ""}),
+				NewToken(KindDrawer, 1, []string{"ID", "abc"}),
+				NewToken(KindDrawer, 1, []string{"EMPTY", ""}),
+				NewToken(KindDrawer, 1, []string{"end", ""}),
+			},
+			wantNode: PropertyDrawer{Properties: []Property{
+				{Key: "ID", Value: "abc"},
+				{Key: "EMPTY", Value: ""},
+			}},
+			wantConsumed: 4,
+		},
+		{
+			desc: "logbook drawer",
+			tokens: []Token{
+				NewToken(KindDrawer, 1, []string{"LOGBOOK", ""}),
+				NewToken(KindText, 1, []string{"note"}),
+				NewToken(KindDrawer, 1, []string{"END", ""}),
+			},
+			wantNode: Drawer{
+				Name:     "LOGBOOK",
+				Content:  "note",
+				Children: Nodes{Section{Paragraphs: []string{"note"}}},
+			},
+			wantConsumed: 3,
+		},
+		{
+			desc: "drawer without end",
+			tokens: []Token{
+				NewToken(KindDrawer, 1, []string{"NOTE", ""}),
+				NewToken(KindHeadline, 1, []string{"*", "headline"}),
+				NewToken(KindDrawer, 1, []string{"END", ""}),
+			},
+			wantNode:     Section{Paragraphs: []string{":NOTE:"}},
+			wantConsumed: 1,
+		},
+		{
+			desc: "property outside drawer",
+			tokens: []Token{
+				NewToken(KindDrawer, 1, []string{"key", "value"}),
+			},
+			wantNode:     Section{Paragraphs: []string{":key: value"}},
+			wantConsumed: 1,
+		},
+		{
+			desc: "property in paragraph",
+			tokens: []Token{
+				NewToken(KindDrawer, 1, []string{"smile", "is a paragraph"}),
+				NewToken(KindText, 1, []string{"second line"}),
+				NewToken(KindDrawer, 1, []string{"END", ""}),
+				NewToken(KindText, 1, []string{""}),
+			},
+			wantNode:     Section{Paragraphs: []string{":smile: is a paragraph\nsecond line\n:END:"}},
+			wantConsumed: 4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			parser := DefaultParser(tt.tokens)
+			consumed, node, err := ParseDrawer(&parser, 0)
+			if err != nil {
+				t.Fatalf("unexpected error: err=%v", err)
+			}
+			if consumed != tt.wantConsumed {
+				t.Errorf("unexpected consumed: got=%v, want=%v", consumed, tt.wantConsumed)
+			}
+			if !reflect.DeepEqual(node, tt.wantNode) {
+				t.Errorf("unexpected node:\ngot=%#v\nwant=%#v", node, tt.wantNode)
+			}
+		})
+	}
+}
+
+func TestParseDrawerLineInParagraph(t *testing.T) {
+	nodes := parseString(t, "first line\n:smile:  is a paragraph\nlast line\n\n:NOTE:\n* Headline\n")
+	want := []Node{
+		Section{Paragraphs: []string{"first line\n:smile:  is a paragraph\nlast line", ":NOTE:"}},
+		Headline{Starts: 1, Title: "Headline"},
+	}
+	if got := withoutPos(nodes); !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected nodes:\ngot=%#v\nwant=%#v", got, want)
+	}
+}
+
+func TestParsePropertiesDrawerWithText(t *testing.T) {
+	nodes := parseString(t, "* Headline\n:PROPERTIES:\n:ID: abc\nstray\n:END:\n")
+	wantNodes := []Node{
+		Headline{Starts: 1, Title: "Headline"},
+		Drawer{
+			Name:     "PROPERTIES",
+			Content:  ":ID: abc\nstray",
+			Children: Nodes{Section{Paragraphs: []string{":ID: abc\nstray"}}},
+		},
+	}
+	if got := withoutPos(nodes); !reflect.DeepEqual(got, wantNodes) {
+		t.Errorf("unexpected nodes:\ngot=%#v\nwant=%#v", got, wantNodes)
+	}
+	want := []string{"2: properties drawer has lines which are not properties"}
+	var got []string
+	for _, d := range Diagnose(nodes) {
+		got = append(got, d.String())
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected diagnostics:\ngot=%#v\nwant=%#v", got, want)
+	}
+}
+
+func TestDrawerWriter(t *testing.T) {
+	var tests = []struct {
+		desc    string
+		drawer  Drawer
+		wantOut string
+	}{
+		{
+			desc:    "normal drawer",
+			drawer:  Drawer{Name: "NOTE", Children: Nodes{Section{Paragraphs: []string{"hello"}}}},
+			wantOut: "<div class=\"org-drawer drawer-note\">\n<p>hello</p>\n</div>\n",
+		},
+		{
+			desc:   "logbook drawer",
+			drawer: Drawer{Name: "LOGBOOK", Content: "hello"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := tt.drawer.Write(&out); err != nil {
+				t.Fatalf("unexpected error: err=%v", err)
+			}
+			if got := out.String(); got != tt.wantOut {
+				t.Errorf("unexpected output: got=%v, want=%v", got, tt.wantOut)
+			}
+		})
+	}
+}
+
+func TestHeadlineProperties(t *testing.T) {
+	props := PropertyDrawer{Properties: []Property{{Key: "ID", Value: "abc"}}}
+	nodes := []Node{
+		Headline{Starts: 1, Title: "with agenda"},
+		Agenda{},
+		props,
+		Headline{Starts: 1, Title: "without properties"},
+		Section{Paragraphs: []string{"text"}},
+		props,
+	}
+	if got, ok := HeadlineProperties(nodes, 0); !ok || !reflect.DeepEqual(got, props) {
+		t.Errorf("unexpected properties: got=%#v, ok=%v", got, ok)
+	}
+	if got, ok := HeadlineProperties(nodes, 3); ok {
+		t.Errorf("unexpected properties: got=%#v", got)
+	}
+	if got, ok := props.Get("id"); !ok || got != "abc" {
+		t.Errorf("unexpected property value: got=%v, ok=%v", got, ok)
+	}
+
+	props = PropertyDrawer{Properties: []Property{
+		{Key: "header-args+", Value: ":var x=1"},
+		{Key: "HEADER-ARGS+", Value: ":var y=2"},
+		{Key: "var", Value: "a"},
+		{Key: "var+", Value: "b"},
+	}}
+	if got, ok := props.Get("header-args"); !ok || got != ":var x=1 :var y=2" {
+		t.Errorf("unexpected property value: got=%v, ok=%v", got, ok)
+	}
+	if got, ok := props.Get("VAR"); !ok || got != "a b" {
+		t.Errorf("unexpected property value: got=%v, ok=%v", got, ok)
+	}
+}
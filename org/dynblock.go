@@ -0,0 +1,415 @@
+package org
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	dynamicBlockBegin = "BEGIN"
+	dynamicBlockEnd   = "END"
+	columnsKey        = "COLUMNS"
+)
+
+var _ Node = DynamicBlock{}
+
+// DynamicBlock is a Node to describe dynamic blocks such as
+// `#+BEGIN: clocktable :scope file`. Children is the parsed Content, which
+// can be regenerated by UpdateDynamicBlocks.
+type DynamicBlock struct {
+	Pos        Pos        `json:"pos"`
+	Name       string     `json:"name"`
+	Parameters []Property `json:"parameters,omitempty"`
+	Content    string     `json:"content"`
+	Children   Nodes      `json:"children,omitempty"`
+}
+
+// Param returns the value of the parameter such as `:scope`. The key does not
+// have the colon.
+func (b DynamicBlock) Param(key string) (string, bool) {
+	for _, p := range b.Parameters {
+		if p.Key == key {
+			return p.Value, true
+		}
+	}
+	return "", false
+}
+
+func (b DynamicBlock) Write(w io.Writer) error {
+	return b.writeHTML(w, &htmlContext{HTMLWriter: DefaultHTMLWriter()})
+}
+
+func (b DynamicBlock) writeHTML(w io.Writer, ctx *htmlContext) error {
+	return ctx.writeAll(w, b.Children)
+}
+
+var (
+	beginDynamicBlockRegexp = regexp.MustCompile(`(?i)^\s*#\+BEGIN:\s*(\S+)(?:\s+(.*?))?\s*$`)
+	endDynamicBlockRegexp   = regexp.MustCompile(`(?i)^\s*#\+END:\s*$`)
+)
+
+func LexDynamicBlock(line string) (Token, bool) {
+	if m := beginDynamicBlockRegexp.FindStringSubmatch(line); m != nil {
+		return NewToken(KindDynamicBegin, 1, m[1:]), true
+	} else if endDynamicBlockRegexp.MatchString(line) {
+		return NewToken(KindDynamicEnd, 1, nil), true
+	}
+	return Token{}, false
+}
+
+// ParseDynamicBlock parses the dynamic block. Dynamic blocks cannot contain
+// headlines, and unclosed blocks and stray `#+END:` lines are keywords.
+func ParseDynamicBlock(p *Parser, i int) (int, Node, error) {
+	if p.tokens[i].kind == KindDynamicEnd {
+		return 1, Keyword{Pos: p.tokens[i].pos, Key: dynamicBlockEnd}, nil
+	}
+	if len(p.tokens[i].vals) != 2 {
+		return 0, nil, fmt.Errorf("dynamic block token[%d] does not have 2 values: got=%d", i, len(p.tokens[i].vals))
+	}
+
+	end := -1
+findEnd:
+	for j := i + 1; j < len(p.tokens); j++ {
+		switch p.tokens[j].kind {
+		case KindHeadline:
+			break findEnd
+		case KindDynamicEnd:
+			end = j
+			break findEnd
+		}
+	}
+	if end < 0 {
+		value := strings.TrimSpace(strings.Join(p.tokens[i].vals, " "))
+		return 1, Keyword{Pos: p.tokens[i].pos, Key: dynamicBlockBegin, Value: value}, nil
+	}
+
+	children, err := p.subParser(i+1, end).Parse()
+	if err != nil {
+		return 0, nil, err
+	}
+	block := DynamicBlock{
+		Pos:      p.tokens[i].pos,
+		Name:     p.tokens[i].vals[0],
+		Content:  blockContent(p.tokens[i+1 : end]),
+		Children: children,
+	}
+	for _, arg := range splitHeaderArgs(p.tokens[i].vals[1]) {
+		parts := strings.SplitN(arg, " ", 2)
+		param := Property{Key: parts[0]}
+		if len(parts) == 2 {
+			param.Value = strings.TrimSpace(parts[1])
+		}
+		block.Parameters = append(block.Parameters, param)
+	}
+	return end - i + 1, block, nil
+}
+
+// DynamicBlockFn is a function to generate the contents of the dynamic block
+// nodes[i] from the document nodes.
+type DynamicBlockFn = func(nodes []Node, i int) ([]Node, error)
+
+// defaultDynamicBlockFns expresses currently supported dynamic blocks.
+var defaultDynamicBlockFns = map[string]DynamicBlockFn{
+	"clocktable": ClockTable,
+	"columnview": ColumnView,
+}
+
+// DefaultDynamicBlockFns returns a new map of the supported dynamic blocks, so
+// callers can register their own functions to it.
+func DefaultDynamicBlockFns() map[string]DynamicBlockFn {
+	fns := make(map[string]DynamicBlockFn, len(defaultDynamicBlockFns))
+	for name, fn := range defaultDynamicBlockFns {
+		fns[name] = fn
+	}
+	return fns
+}
+
+// UpdateDynamicBlocks regenerates the contents of dynamic blocks with the
+// functions of their names like org-update-all-dblocks, and returns the
+// updated nodes. Blocks without functions are not changed. Only the dynamic
+// blocks at the top level are updated.
+func UpdateDynamicBlocks(nodes []Node, fns map[string]DynamicBlockFn) ([]Node, error) {
+	ret := make([]Node, len(nodes))
+	copy(ret, nodes)
+	for i := range ret {
+		block, ok := ret[i].(DynamicBlock)
+		if !ok {
+			continue
+		}
+		fn, ok := fns[block.Name]
+		if !ok {
+			continue
+		}
+		children, err := fn(ret, i)
+		if err != nil {
+			return nil, fmt.Errorf("fail to update %v block at line %d: %w", block.Name, block.Pos.Line, err)
+		}
+		var content bytes.Buffer
+		if err := DefaultOrgWriter().Write(children, &content); err != nil {
+			return nil, err
+		}
+		block.Children = children
+		block.Content = strings.TrimSuffix(content.String(), "\n")
+		ret[i] = block
+	}
+	return ret, nil
+}
+
+// subtree returns the range of the subtree which contains nodes[i]. The whole
+// nodes are returned if nodes[i] is placed before the first headline.
+func subtree(nodes []Node, i int) (start, end int) {
+	for start = i; start >= 0; start-- {
+		if _, ok := nodes[start].(Headline); ok {
+			break
+		}
+	}
+	if start < 0 {
+		return 0, len(nodes)
+	}
+	return start, subtreeEnd(nodes, start)
+}
+
+// subtreeEnd returns the end of the subtree of the headline nodes[i].
+func subtreeEnd(nodes []Node, i int) int {
+	level := nodes[i].(Headline).Starts
+	for end := i + 1; end < len(nodes); end++ {
+		if h, ok := nodes[end].(Headline); ok && h.Starts <= level {
+			return end
+		}
+	}
+	return len(nodes)
+}
+
+// dynamicBlockMaxLevel returns the value of `:maxlevel`.
+func dynamicBlockMaxLevel(b DynamicBlock, defaultLevel int) (int, error) {
+	v, ok := b.Param("maxlevel")
+	if !ok {
+		return defaultLevel, nil
+	}
+	level, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid maxlevel: %q", v)
+	}
+	return level, nil
+}
+
+// clockEntry is the total time of the headline.
+type clockEntry struct {
+	level int
+	title string
+	time  time.Duration
+}
+
+// ClockTable generates the clock table of the headlines like the clocktable
+// dynamic block. `:scope` supports file and subtree, and `:maxlevel` limits
+// the level of headlines.
+func ClockTable(nodes []Node, i int) ([]Node, error) {
+	block := nodes[i].(DynamicBlock)
+	maxLevel, err := dynamicBlockMaxLevel(block, 3)
+	if err != nil {
+		return nil, err
+	}
+	scope := nodes
+	switch v, _ := block.Param("scope"); v {
+	case "", "file", "nil":
+	case "subtree":
+		start, end := subtree(nodes, i)
+		scope = nodes[start:end]
+	default:
+		return nil, fmt.Errorf("unsupported scope: %q", v)
+	}
+
+	var (
+		entries []clockEntry
+		total   time.Duration
+	)
+	for _, node := range scope {
+		if h, ok := node.(Headline); ok {
+			entries = append(entries, clockEntry{level: h.Starts, title: h.Title})
+			continue
+		}
+		d := clockDuration(node)
+		total += d
+		if len(entries) > 0 {
+			entries[len(entries)-1].time += d
+		}
+	}
+	// the time of headlines includes the time of their children.
+	for j := len(entries) - 1; j >= 0; j-- {
+		for k := j - 1; k >= 0; k-- {
+			if entries[k].level < entries[j].level {
+				entries[k].time += entries[j].time
+				break
+			}
+		}
+	}
+
+	depth := 1
+	for _, e := range entries {
+		if e.time > 0 && e.level <= maxLevel && e.level > depth {
+			depth = e.level
+		}
+	}
+	newRow := func(title string) []string {
+		row := make([]string, depth+1)
+		row[0] = title
+		return row
+	}
+	header := newRow("Headline")
+	header[1] = "Time"
+	totalRow := newRow("*Total time*")
+	totalRow[1] = "*" + formatClockDuration(total, 0) + "*"
+	table := Table{Rows: [][]string{header, nil, totalRow, nil}}
+	for _, e := range entries {
+		if e.time == 0 || e.level > maxLevel {
+			continue
+		}
+		title := e.title
+		if e.level > 1 {
+			title = `\_` + strings.Repeat("  ", e.level-1) + title
+		}
+		row := newRow(title)
+		row[e.level] = formatClockDuration(e.time, 0)
+		table.Rows = append(table.Rows, row)
+	}
+	return []Node{table}, nil
+}
+
+// clockDuration returns the total time of the clocks in the node.
+func clockDuration(node Node) time.Duration {
+	var d time.Duration
+	switch n := node.(type) {
+	case Clock:
+		d = n.Duration
+	case Drawer:
+		for _, child := range n.Children {
+			d += clockDuration(child)
+		}
+	}
+	return d
+}
+
+// column is a column of the column view such as `%25ITEM(Task)`.
+type column struct {
+	property string
+	title    string
+}
+
+var columnRegexp = regexp.MustCompile(`%\d*([\w-]+)(?:\(([^)]*)\))?(?:\{[^}]*\})?`)
+
+const defaultColumns = "%25ITEM %TODO %3PRIORITY %TAGS"
+
+func parseColumns(s string) []column {
+	var columns []column
+	for _, m := range columnRegexp.FindAllStringSubmatch(s, -1) {
+		c := column{property: strings.ToUpper(m[1]), title: m[2]}
+		if c.title == "" {
+			c.title = c.property
+		}
+		columns = append(columns, c)
+	}
+	return columns
+}
+
+// ColumnView generates the table of headline properties like the columnview
+// dynamic block. `:id` supports local, global and the ID property of a
+// headline, and `:maxlevel` limits the level of headlines. Columns are defined
+// by the COLUMNS property of the root headline or the #+COLUMNS keyword.
+func ColumnView(nodes []Node, i int) ([]Node, error) {
+	block := nodes[i].(DynamicBlock)
+	maxLevel, err := dynamicBlockMaxLevel(block, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	start, end := 0, len(nodes)
+	switch id, _ := block.Param("id"); id {
+	case "global":
+	case "", "local":
+		start, end = subtree(nodes, i)
+	default:
+		start = -1
+		id = unquote(id)
+		for j := range nodes {
+			if _, ok := nodes[j].(Headline); !ok {
+				continue
+			}
+			if props, ok := HeadlineProperties(nodes, j); ok {
+				if v, _ := props.Get("ID"); v == id {
+					start, end = j, subtreeEnd(nodes, j)
+					break
+				}
+			}
+		}
+		if start < 0 {
+			return nil, fmt.Errorf("entry %q is not found", id)
+		}
+	}
+
+	spec := defaultColumns
+	for _, node := range nodes {
+		if k, ok := node.(Keyword); ok && k.Key == columnsKey {
+			spec = k.Value
+		}
+	}
+	if _, ok := nodes[start].(Headline); ok {
+		if props, ok := HeadlineProperties(nodes, start); ok {
+			if v, ok := props.Get(columnsKey); ok {
+				spec = v
+			}
+		}
+	}
+	columns := parseColumns(spec)
+
+	header := make([]string, len(columns))
+	for j, c := range columns {
+		header[j] = c.title
+	}
+	table := Table{Rows: [][]string{header, nil}}
+	skipEmpty := dynamicBlockEnabled(block, "skip-empty-rows")
+	for j := start; j < end; j++ {
+		h, ok := nodes[j].(Headline)
+		if !ok || (maxLevel > 0 && h.Starts > maxLevel) {
+			continue
+		}
+		props, _ := HeadlineProperties(nodes, j)
+		row := make([]string, len(columns))
+		empty := true
+		for k, c := range columns {
+			switch c.property {
+			case "ITEM":
+				row[k] = h.Title
+				continue
+			case "TODO":
+				row[k] = h.Keyword
+			case "PRIORITY":
+				row[k] = h.Priority
+			case "TAGS":
+				if len(h.Tags) > 0 {
+					row[k] = ":" + strings.Join(h.Tags, ":") + ":"
+				}
+			default:
+				row[k], _ = props.Get(c.property)
+			}
+			if row[k] != "" {
+				empty = false
+			}
+		}
+		if skipEmpty && empty {
+			continue
+		}
+		table.Rows = append(table.Rows, row)
+	}
+	return []Node{table}, nil
+}
+
+// dynamicBlockEnabled reports whether the parameter is set to a non-nil value.
+func dynamicBlockEnabled(b DynamicBlock, key string) bool {
+	v, ok := b.Param(key)
+	return ok && v != "nil" && v != "no"
+}
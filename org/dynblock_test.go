@@ -0,0 +1,182 @@
+package org_test
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+
+	. "github.com/Ladicle/org2html/org"
+)
+
+func TestLexDynamicBlock(t *testing.T) {
+	var tests = []struct {
+		desc      string
+		line      string
+		wantToken Token
+		wantFlag  bool
+	}{
+		{
+			desc: "empty line",
+			line: "",
+		},
+		{
+			desc:      "block begin",
+			line:      "#+BEGIN: clocktable :scope file :maxlevel 2  ",
+			wantFlag:  true,
+			wantToken: NewToken(KindDynamicBegin, 1, []string{"clocktable", ":scope file :maxlevel 2"}),
+		},
+		{
+			desc:      "block begin without parameters",
+			line:      "  #+begin: columnview",
+			wantFlag:  true,
+			wantToken: NewToken(KindDynamicBegin, 1, []string{"columnview", ""}),
+		},
+		{
+			desc:      "block end",
+			line:      "#+end:",
+			wantFlag:  true,
+			wantToken: NewToken(KindDynamicEnd, 1, nil),
+		},
+		{
+			desc: "greater block",
+			line: "#+begin_quote",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			token, flag := LexDynamicBlock(tt.line)
+			if flag != tt.wantFlag {
+				t.Errorf("unexpected flag: got=%v, want=%v", flag, tt.wantFlag)
+			}
+			if !reflect.DeepEqual(token, tt.wantToken) {
+				t.Errorf("unexpected token:\ngot=%#v\nwant=%#v", token, tt.wantToken)
+			}
+		})
+	}
+}
+
+func TestParseDynamicBlock(t *testing.T) {
+	var tests = []struct {
+		desc      string
+		input     string
+		wantNodes []Node
+	}{
+		{
+			desc:  "dynamic block",
+			input: "#+BEGIN: clocktable :scope subtree :maxlevel 2\n| a |\n#+END:\n",
+			wantNodes: []Node{DynamicBlock{
+				Name: "clocktable",
+				Parameters: []Property{
+					{Key: "scope", Value: "subtree"},
+					{Key: "maxlevel", Value: "2"},
+				},
+				Content:  "| a |",
+				Children: Nodes{Table{Rows: [][]string{{"a"}}}},
+			}},
+		},
+		{
+			desc:  "unclosed block",
+			input: "#+BEGIN: clocktable\n* headline\n#+END:\n",
+			wantNodes: []Node{
+				Keyword{Key: "BEGIN", Value: "clocktable"},
+				Headline{Starts: 1, Title: "headline"},
+				Keyword{Key: "END"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			if got := withoutPos(parseString(t, tt.input)); !reflect.DeepEqual(got, tt.wantNodes) {
+				t.Errorf("unexpected nodes:\ngot=%#v\nwant=%#v", got, tt.wantNodes)
+			}
+		})
+	}
+}
+
+func TestUpdateDynamicBlocks(t *testing.T) {
+	var tests = []struct {
+		desc      string
+		input     string
+		wantOut   string
+		wantError error
+	}{
+		{
+			desc: "clock table",
+			input: `#+BEGIN: clocktable :maxlevel 2
+#+END:
+* A
+:LOGBOOK:
+CLOCK: [2022-01-31 Mon 10:00]--[2022-01-31 Mon 11:00] =>  1:00
+:END:
+** B
+CLOCK: [2022-01-31 Mon 12:00]--[2022-01-31 Mon 12:30] =>  0:30
+*** C
+CLOCK: [2022-01-31 Mon 13:00]--[2022-01-31 Mon 13:15] =>  0:15
+* D
+`,
+			wantOut: `#+begin: clocktable :maxlevel 2
+| Headline     | Time   |      |
+|--------------+--------+------|
+| *Total time* | *1:45* |      |
+|--------------+--------+------|
+| A            | 1:45   |      |
+| \_  B        |        | 0:45 |
+#+end:
+`,
+		},
+		{
+			desc: "column view",
+			input: `#+COLUMNS: %25ITEM(Task) %TODO %EFFORT
+* Project
+#+BEGIN: columnview
+#+END:
+** TODO Task1
+:PROPERTIES:
+:EFFORT:   1:00
+:END:
+** Task2
+* Other
+`,
+			wantOut: `#+begin: columnview
+| Task    | TODO | EFFORT |
+|---------+------+--------|
+| Project |      |        |
+| Task1   | TODO | 1:00   |
+| Task2   |      |        |
+#+end:
+`,
+		},
+		{
+			desc:      "unsupported scope",
+			input:     "#+BEGIN: clocktable :scope agenda\n#+END:\n",
+			wantError: errors.New(`fail to update clocktable block at line 1: unsupported scope: "agenda"`),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			nodes, err := UpdateDynamicBlocks(parseString(t, tt.input), DefaultDynamicBlockFns())
+			if err != nil {
+				if tt.wantError == nil || err.Error() != tt.wantError.Error() {
+					t.Fatalf("unexpected error: err=%v, want=%v", err, tt.wantError)
+				}
+				return
+			} else if tt.wantError != nil {
+				t.Fatalf("expect error but not occurred: want=%v", tt.wantError)
+			}
+			for _, node := range nodes {
+				block, ok := node.(DynamicBlock)
+				if !ok {
+					continue
+				}
+				var out bytes.Buffer
+				if err := DefaultOrgWriter().Write([]Node{block}, &out); err != nil {
+					t.Fatalf("unexpected error: err=%v", err)
+				}
+				if got := out.String(); got != tt.wantOut {
+					t.Errorf("unexpected output:\ngot=%v\nwant=%v", got, tt.wantOut)
+				}
+			}
+		})
+	}
+}
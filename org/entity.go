@@ -0,0 +1,560 @@
+package org
+
+import (
+	"io"
+	"regexp"
+)
+
+var (
+	// entities must not be followed by letters. `{}` terminates the entity
+	// explicitly such as `\alpha{}beta`.
+	entityRegexp        = regexp.MustCompile(`\\([A-Za-z]+)(\{\})?`)
+	specialStringRegexp = regexp.MustCompile(`---|--|\.\.\.|\\-`)
+	lineBreakRegexp     = regexp.MustCompile(`(?m)\\\\[ \t]*$`)
+)
+
+// specialStrings maps special strings to HTML.
+var specialStrings = map[string]string{
+	"---": "&#x2014;",
+	"--":  "&#x2013;",
+	"...": "&#x2026;",
+	`\-`:  "&shy;",
+}
+
+// writeEntity writes the entity as HTML. Unknown entities and entities
+// disabled by `e:nil` are written as text.
+func writeEntity(w io.Writer, ctx *htmlContext, m []string) {
+	if e, ok := entities[m[1]]; ok && ctx.options().Entities {
+		io.WriteString(w, e)
+		return
+	}
+	io.WriteString(w, codeEscaper.Replace(m[0]))
+}
+
+// writeSpecialString writes the special string as HTML unless `-:nil`.
+func writeSpecialString(w io.Writer, ctx *htmlContext, m []string) {
+	if ctx.options().SpecialStrings {
+		io.WriteString(w, specialStrings[m[0]])
+		return
+	}
+	io.WriteString(w, m[0])
+}
+
+// writeLineBreak writes the forced line break `\\` at the end of lines.
+func writeLineBreak(w io.Writer, ctx *htmlContext, m []string) {
+	io.WriteString(w, "<br>")
+}
+
+// entities maps the names of org-entities to HTML. Characters without the
+// named HTML entity are written as the character references.
+var entities = map[string]string{
+	"AA":                 "&Aring;",
+	"aa":                 "&aring;",
+	"Aacute":             "&Aacute;",
+	"aacute":             "&aacute;",
+	"Acirc":              "&Acirc;",
+	"acirc":              "&acirc;",
+	"acute":              "&#xb4;",
+	"AE":                 "&AElig;",
+	"ae":                 "&aelig;",
+	"AElig":              "&AElig;",
+	"aelig":              "&aelig;",
+	"Agrave":             "&Agrave;",
+	"agrave":             "&agrave;",
+	"alefsym":            "&alefsym;",
+	"aleph":              "&alefsym;",
+	"Alpha":              "&Alpha;",
+	"alpha":              "&alpha;",
+	"amp":                "&amp;",
+	"and":                "&and;",
+	"ang":                "&ang;",
+	"angle":              "&ang;",
+	"approx":             "&asymp;",
+	"Aring":              "&Aring;",
+	"aring":              "&aring;",
+	"ast":                "*",
+	"asymp":              "&asymp;",
+	"Atilde":             "&Atilde;",
+	"atilde":             "&atilde;",
+	"Auml":               "&Auml;",
+	"auml":               "&auml;",
+	"backslash":          "\\",
+	"bdquo":              "&bdquo;",
+	"Beta":               "&Beta;",
+	"beta":               "&beta;",
+	"beth":               "&#x2136;",
+	"bigstar":            "&#x2605;",
+	"bigtriangledown":    "&#x25bd;",
+	"bigtriangleup":      "&#x25b3;",
+	"blacksmile":         "&#x263b;",
+	"blacksquare":        "&#x25a0;",
+	"blacktriangle":      "&#x25b2;",
+	"bot":                "&perp;",
+	"Box":                "&#x25a1;",
+	"brvbar":             "&brvbar;",
+	"bull":               "&bull;",
+	"bullet":             "&bull;",
+	"cap":                "&cap;",
+	"Ccedil":             "&Ccedil;",
+	"ccedil":             "&ccedil;",
+	"cdot":               "&sdot;",
+	"cdotp":              "&#xb7;",
+	"cdots":              "&#x22ef;",
+	"cedil":              "&cedil;",
+	"cent":               "&cent;",
+	"checkmark":          "&#x2713;",
+	"Chi":                "&Chi;",
+	"chi":                "&chi;",
+	"circ":               "&circ;",
+	"clubs":              "&clubs;",
+	"clubsuit":           "&clubs;",
+	"colon":              ":",
+	"comp":               "&#x2218;",
+	"complement":         "&#x2201;",
+	"cong":               "&cong;",
+	"coprod":             "&#x2210;",
+	"copy":               "&copy;",
+	"crarr":              "&crarr;",
+	"cup":                "&cup;",
+	"curren":             "&curren;",
+	"curvearrowleft":     "&#x21b6;",
+	"curvearrowright":    "&#x21b7;",
+	"dag":                "&dagger;",
+	"Dagger":             "&Dagger;",
+	"dagger":             "&dagger;",
+	"daleth":             "&#x2138;",
+	"dArr":               "&dArr;",
+	"darr":               "&darr;",
+	"dashleftarrow":      "&#x21e0;",
+	"dashrightarrow":     "&#x21e2;",
+	"dashv":              "&#x22a3;",
+	"ddag":               "&Dagger;",
+	"Ddagger":            "&#x2021;",
+	"ddagger":            "&Dagger;",
+	"ddots":              "&#x22f1;",
+	"deg":                "&deg;",
+	"Delta":              "&Delta;",
+	"delta":              "&delta;",
+	"diamond":            "&#x22c4;",
+	"diamondsuit":        "&diams;",
+	"diams":              "&diams;",
+	"die":                "&uml;",
+	"Digamma":            "&#x3dc;",
+	"digamma":            "&#x3dd;",
+	"div":                "&divide;",
+	"divide":             "&divide;",
+	"dollar":             "$",
+	"dots":               "&hellip;",
+	"Downarrow":          "&dArr;",
+	"downarrow":          "&darr;",
+	"downdownarrows":     "&#x21ca;",
+	"Eacute":             "&Eacute;",
+	"eacute":             "&eacute;",
+	"Ecirc":              "&Ecirc;",
+	"ecirc":              "&ecirc;",
+	"Egrave":             "&Egrave;",
+	"egrave":             "&egrave;",
+	"ell":                "&#x2113;",
+	"empty":              "&empty;",
+	"emptyset":           "&empty;",
+	"emsp":               "&emsp;",
+	"ensp":               "&ensp;",
+	"Epsilon":            "&Epsilon;",
+	"epsilon":            "&epsilon;",
+	"equiv":              "&equiv;",
+	"Eta":                "&Eta;",
+	"eta":                "&eta;",
+	"ETH":                "&ETH;",
+	"eth":                "&eth;",
+	"Euml":               "&Euml;",
+	"euml":               "&euml;",
+	"EUR":                "&euro;",
+	"euro":               "&euro;",
+	"excl":               "!",
+	"exist":              "&exist;",
+	"exists":             "&exist;",
+	"flat":               "&#x266d;",
+	"fnof":               "&fnof;",
+	"forall":             "&forall;",
+	"frac12":             "&frac12;",
+	"frac14":             "&frac14;",
+	"frac34":             "&frac34;",
+	"frasl":              "&frasl;",
+	"frown":              "&#x2322;",
+	"frowny":             "&#x2639;",
+	"Gamma":              "&Gamma;",
+	"gamma":              "&gamma;",
+	"ge":                 "&ge;",
+	"geq":                "&ge;",
+	"gets":               "&larr;",
+	"gg":                 "&#x226b;",
+	"gimel":              "&#x2137;",
+	"grave":              "`",
+	"gt":                 "&gt;",
+	"guillemotleft":      "&laquo;",
+	"guillemotright":     "&raquo;",
+	"guilsinglleft":      "&#x2039;",
+	"guilsinglright":     "&#x203a;",
+	"hArr":               "&hArr;",
+	"harr":               "&harr;",
+	"hbar":               "&#x210f;",
+	"hearts":             "&hearts;",
+	"heartsuit":          "&hearts;",
+	"hellip":             "&hellip;",
+	"hookleftarrow":      "&crarr;",
+	"hslash":             "&#x210f;",
+	"Iacute":             "&Iacute;",
+	"iacute":             "&iacute;",
+	"Icirc":              "&Icirc;",
+	"icirc":              "&icirc;",
+	"iexcl":              "&iexcl;",
+	"iff":                "&hArr;",
+	"Igrave":             "&Igrave;",
+	"igrave":             "&igrave;",
+	"Im":                 "&image;",
+	"image":              "&image;",
+	"imath":              "&#x131;",
+	"impliedby":          "&lArr;",
+	"implies":            "&rArr;",
+	"in":                 "&isin;",
+	"infin":              "&infin;",
+	"infty":              "&infin;",
+	"int":                "&int;",
+	"Iota":               "&Iota;",
+	"iota":               "&iota;",
+	"iquest":             "&iquest;",
+	"isin":               "&isin;",
+	"Iuml":               "&Iuml;",
+	"iuml":               "&iuml;",
+	"jmath":              "&#x237;",
+	"Kappa":              "&Kappa;",
+	"kappa":              "&kappa;",
+	"Lambda":             "&Lambda;",
+	"lambda":             "&lambda;",
+	"land":               "&and;",
+	"lang":               "&lang;",
+	"langle":             "&lang;",
+	"laquo":              "&laquo;",
+	"lArr":               "&lArr;",
+	"larr":               "&larr;",
+	"lceil":              "&lceil;",
+	"lcub":               "{",
+	"ldots":              "&hellip;",
+	"ldquo":              "&ldquo;",
+	"ldquor":             "&#x201e;",
+	"le":                 "&le;",
+	"leadsto":            "&#x219d;",
+	"Leftarrow":          "&lArr;",
+	"leftarrow":          "&larr;",
+	"leftharpoonup":      "&#x21bc;",
+	"Leftrightarrow":     "&hArr;",
+	"leftrightarrow":     "&harr;",
+	"leftrightarrows":    "&#x21c6;",
+	"leftrightharpoons":  "&#x21cb;",
+	"leq":                "&le;",
+	"lfloor":             "&lfloor;",
+	"ll":                 "&#x226a;",
+	"lnot":               "&not;",
+	"Longleftarrow":      "&#x27f8;",
+	"longleftarrow":      "&#x27f5;",
+	"Longleftrightarrow": "&#x27fa;",
+	"longleftrightarrow": "&#x27f7;",
+	"Longrightarrow":     "&#x27f9;",
+	"longrightarrow":     "&#x27f6;",
+	"lor":                "&or;",
+	"lowast":             "&lowast;",
+	"lowbar":             "_",
+	"loz":                "&loz;",
+	"lozenge":            "&loz;",
+	"lrm":                "&lrm;",
+	"lsaquo":             "&lsaquo;",
+	"lsqb":               "[",
+	"lsquo":              "&lsquo;",
+	"lt":                 "&lt;",
+	"macr":               "&macr;",
+	"mapsto":             "&#x21a6;",
+	"mdash":              "&mdash;",
+	"measuredangle":      "&#x2221;",
+	"mho":                "&#x2127;",
+	"micro":              "&micro;",
+	"mid":                "&#x2223;",
+	"middot":             "&middot;",
+	"minus":              "&minus;",
+	"models":             "&#x22a8;",
+	"mp":                 "&#x2213;",
+	"Mu":                 "&Mu;",
+	"mu":                 "&mu;",
+	"nabla":              "&nabla;",
+	"natural":            "&#x266e;",
+	"nbsp":               "&nbsp;",
+	"ndash":              "&ndash;",
+	"ne":                 "&ne;",
+	"nearrow":            "&#x2197;",
+	"neg":                "&not;",
+	"neq":                "&ne;",
+	"nexist":             "&#x2204;",
+	"nexists":            "&#x2204;",
+	"ngeq":               "&#x2271;",
+	"ni":                 "&ni;",
+	"nleftarrow":         "&#x219a;",
+	"nleq":               "&#x2270;",
+	"not":                "&not;",
+	"notin":              "&notin;",
+	"nrightarrow":        "&#x219b;",
+	"nsub":               "&nsub;",
+	"nsubseteq":          "&#x2288;",
+	"nsupseteq":          "&#x2289;",
+	"Ntilde":             "&Ntilde;",
+	"ntilde":             "&ntilde;",
+	"Nu":                 "&Nu;",
+	"nu":                 "&nu;",
+	"num":                "#",
+	"nvdash":             "&#x22ac;",
+	"nwarrow":            "&#x2196;",
+	"O":                  "&Oslash;",
+	"o":                  "&oslash;",
+	"Oacute":             "&Oacute;",
+	"oacute":             "&oacute;",
+	"Ocirc":              "&Ocirc;",
+	"ocirc":              "&ocirc;",
+	"odot":               "&#x2299;",
+	"OElig":              "&OElig;",
+	"oelig":              "&oelig;",
+	"Ograve":             "&Ograve;",
+	"ograve":             "&ograve;",
+	"oint":               "&#x222e;",
+	"oline":              "&oline;",
+	"Omega":              "&Omega;",
+	"omega":              "&omega;",
+	"Omicron":            "&Omicron;",
+	"omicron":            "&omicron;",
+	"ominus":             "&#x2296;",
+	"oplus":              "&oplus;",
+	"or":                 "&or;",
+	"ordf":               "&ordf;",
+	"ordm":               "&ordm;",
+	"Oslash":             "&Oslash;",
+	"oslash":             "&oslash;",
+	"Otilde":             "&Otilde;",
+	"otilde":             "&otilde;",
+	"otimes":             "&otimes;",
+	"Ouml":               "&Ouml;",
+	"ouml":               "&ouml;",
+	"P":                  "&para;",
+	"para":               "&para;",
+	"parallel":           "&#x2225;",
+	"part":               "&part;",
+	"partial":            "&part;",
+	"percnt":             "%",
+	"permil":             "&permil;",
+	"perp":               "&perp;",
+	"Phi":                "&Phi;",
+	"phi":                "&phi;",
+	"Pi":                 "&Pi;",
+	"pi":                 "&pi;",
+	"piv":                "&piv;",
+	"plus":               "+",
+	"plusmn":             "&plusmn;",
+	"pm":                 "&plusmn;",
+	"pound":              "&pound;",
+	"Prime":              "&Prime;",
+	"prime":              "&prime;",
+	"prod":               "&prod;",
+	"prop":               "&prop;",
+	"propto":             "&prop;",
+	"Psi":                "&Psi;",
+	"psi":                "&psi;",
+	"quest":              "?",
+	"quot":               "&quot;",
+	"quotedblbase":       "&#x201e;",
+	"quotesinglbase":     "&#x201a;",
+	"radic":              "&radic;",
+	"rang":               "&rang;",
+	"rangle":             "&rang;",
+	"raquo":              "&raquo;",
+	"rArr":               "&rArr;",
+	"rarr":               "&rarr;",
+	"rceil":              "&rceil;",
+	"rcub":               "}",
+	"rdquo":              "&rdquo;",
+	"Re":                 "&real;",
+	"real":               "&real;",
+	"reg":                "&reg;",
+	"rfloor":             "&rfloor;",
+	"Rho":                "&Rho;",
+	"rho":                "&rho;",
+	"Rightarrow":         "&rArr;",
+	"rightarrow":         "&rarr;",
+	"rightharpoonup":     "&#x21c0;",
+	"rightleftarrows":    "&#x21c4;",
+	"rightleftharpoons":  "&#x21cc;",
+	"rlm":                "&rlm;",
+	"rsaquo":             "&rsaquo;",
+	"rsqb":               "]",
+	"rsquo":              "&rsquo;",
+	"S":                  "&sect;",
+	"sad":                "&#x2639;",
+	"sbquo":              "&sbquo;",
+	"Scaron":             "&Scaron;",
+	"scaron":             "&scaron;",
+	"sdot":               "&sdot;",
+	"searrow":            "&#x2198;",
+	"sect":               "&sect;",
+	"setminus":           "&#x2216;",
+	"sharp":              "&#x266f;",
+	"shy":                "&shy;",
+	"Sigma":              "&Sigma;",
+	"sigma":              "&sigma;",
+	"sigmaf":             "&sigmaf;",
+	"sim":                "&sim;",
+	"simeq":              "&#x2243;",
+	"slash":              "/",
+	"smallsetminus":      "&#x2216;",
+	"smile":              "&#x2323;",
+	"smiley":             "&#x263a;",
+	"spades":             "&spades;",
+	"spadesuit":          "&spades;",
+	"sphericalangle":     "&#x2222;",
+	"sqrt":               "&radic;",
+	"sqsubseteq":         "&#x2291;",
+	"sqsupseteq":         "&#x2292;",
+	"square":             "&#x25a1;",
+	"ss":                 "&szlig;",
+	"star":               "&#x22c6;",
+	"sub":                "&sub;",
+	"sube":               "&sube;",
+	"subset":             "&sub;",
+	"subseteq":           "&sube;",
+	"subsetneq":          "&#x228a;",
+	"sum":                "&sum;",
+	"sup":                "&sup;",
+	"sup1":               "&sup1;",
+	"sup2":               "&sup2;",
+	"sup3":               "&sup3;",
+	"supe":               "&supe;",
+	"supset":             "&sup;",
+	"supseteq":           "&supe;",
+	"supsetneq":          "&#x228b;",
+	"surd":               "&radic;",
+	"swarrow":            "&#x2199;",
+	"szlig":              "&szlig;",
+	"Tau":                "&Tau;",
+	"tau":                "&tau;",
+	"textasciiacute":     "&#xb4;",
+	"textasciicircum":    "^",
+	"textasciidieresis":  "&#xa8;",
+	"textasciigrave":     "`",
+	"textasciitilde":     "~",
+	"textbackslash":      "\\",
+	"textbar":            "|",
+	"textbrokenbar":      "&#xa6;",
+	"textbullet":         "&bull;",
+	"textcent":           "&#xa2;",
+	"textcopyright":      "&copy;",
+	"textdagger":         "&dagger;",
+	"textdaggerdbl":      "&Dagger;",
+	"textdegree":         "&deg;",
+	"textdiv":            "&#xf7;",
+	"textellipsis":       "&hellip;",
+	"textemdash":         "&#x2014;",
+	"textendash":         "&#x2013;",
+	"texteuro":           "&#x20ac;",
+	"textexclamdown":     "&iexcl;",
+	"textlangle":         "&#x27e8;",
+	"textlnot":           "&#xac;",
+	"textminus":          "&#x2212;",
+	"textmu":             "&#xb5;",
+	"textonehalf":        "&#xbd;",
+	"textonequarter":     "&#xbc;",
+	"textonesuperior":    "&#xb9;",
+	"textordfeminine":    "&#xaa;",
+	"textordmasculine":   "&#xba;",
+	"textperiodcentered": "&#xb7;",
+	"textpilcrow":        "&#xb6;",
+	"textpm":             "&#xb1;",
+	"textquestiondown":   "&#xbf;",
+	"textquotedblleft":   "&#x201c;",
+	"textquotedblright":  "&#x201d;",
+	"textquoteleft":      "&#x2018;",
+	"textquoteright":     "&#x2019;",
+	"textquotesingle":    "'",
+	"textrangle":         "&#x27e9;",
+	"textregistered":     "&reg;",
+	"textsection":        "&sect;",
+	"textsterling":       "&#xa3;",
+	"textthreequarters":  "&#xbe;",
+	"textthreesuperior":  "&#xb3;",
+	"texttimes":          "&#xd7;",
+	"texttrademark":      "&trade;",
+	"texttwosuperior":    "&#xb2;",
+	"textyen":            "&#xa5;",
+	"there4":             "&there4;",
+	"therefore":          "&there4;",
+	"Theta":              "&Theta;",
+	"theta":              "&theta;",
+	"thetasym":           "&thetasym;",
+	"thinsp":             "&thinsp;",
+	"THORN":              "&THORN;",
+	"thorn":              "&thorn;",
+	"tilde":              "&tilde;",
+	"times":              "&times;",
+	"to":                 "&rarr;",
+	"top":                "&#x22a4;",
+	"trade":              "&trade;",
+	"triangle":           "&#x25b3;",
+	"triangleleft":       "&#x25c1;",
+	"triangleright":      "&#x25b7;",
+	"Uacute":             "&Uacute;",
+	"uacute":             "&uacute;",
+	"uArr":               "&uArr;",
+	"uarr":               "&uarr;",
+	"Ucirc":              "&Ucirc;",
+	"ucirc":              "&ucirc;",
+	"Ugrave":             "&Ugrave;",
+	"ugrave":             "&ugrave;",
+	"uml":                "&uml;",
+	"under":              "_",
+	"Uparrow":            "&uArr;",
+	"uparrow":            "&uarr;",
+	"Updownarrow":        "&#x21d5;",
+	"updownarrow":        "&#x2195;",
+	"upsih":              "&upsih;",
+	"Upsilon":            "&Upsilon;",
+	"upsilon":            "&upsilon;",
+	"upuparrows":         "&#x21c8;",
+	"Uuml":               "&Uuml;",
+	"uuml":               "&uuml;",
+	"varepsilon":         "&#x3b5;",
+	"varkappa":           "&#x3f0;",
+	"varnothing":         "&empty;",
+	"varphi":             "&#x3c6;",
+	"varpi":              "&piv;",
+	"varrho":             "&#x3f1;",
+	"varsigma":           "&sigmaf;",
+	"vartheta":           "&thetasym;",
+	"vbar":               "|",
+	"Vdash":              "&#x22a9;",
+	"vDash":              "&#x22a8;",
+	"vdash":              "&#x22a2;",
+	"vdots":              "&#x22ee;",
+	"vee":                "&or;",
+	"Vert":               "&#x2016;",
+	"vert":               "|",
+	"wedge":              "&and;",
+	"weierp":             "&weierp;",
+	"wp":                 "&weierp;",
+	"Xi":                 "&Xi;",
+	"xi":                 "&xi;",
+	"Yacute":             "&Yacute;",
+	"yacute":             "&yacute;",
+	"yen":                "&yen;",
+	"Ypsilon":            "&Upsilon;",
+	"ypsilon":            "&upsilon;",
+	"Yuml":               "&Yuml;",
+	"yuml":               "&yuml;",
+	"Zeta":               "&Zeta;",
+	"zeta":               "&zeta;",
+	"zwj":                "&zwj;",
+	"zwnj":               "&zwnj;",
+	"zwsp":               "&#x200b;",
+}
@@ -0,0 +1,53 @@
+package org_test
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/Ladicle/org2html/org"
+)
+
+func TestEntityWriter(t *testing.T) {
+	var tests = []struct {
+		desc    string
+		input   string
+		wantOut string
+	}{
+		{
+			desc:    "entities",
+			input:   "\\alpha\\rarr{}b, x\\nbsp{}y, \\alphabet and \\unknown\n",
+			wantOut: "<p>&alpha;&rarr;b, x&nbsp;y, \\alphabet and \\unknown</p>\n",
+		},
+		{
+			desc:    "special strings",
+			input:   "a -- b --- c... hy\\-phen\n",
+			wantOut: "<p>a &#x2013; b &#x2014; c&#x2026; hy&shy;phen</p>\n",
+		},
+		{
+			desc:    "line breaks",
+			input:   "first\\\\\nsecond \\\\  \nthird \\\\ no\n",
+			wantOut: "<p>first<br>\nsecond <br>\nthird \\\\ no</p>\n",
+		},
+		{
+			desc:    "disabled by options",
+			input:   "#+OPTIONS: e:nil -:nil\n\\alpha -- ...\n",
+			wantOut: "<p>\\alpha -- ...</p>\n",
+		},
+		{
+			desc:    "math is not converted",
+			input:   "\\(\\alpha--\\beta\\) \\beta\n",
+			wantOut: `<p><span class="math inline">\(\alpha--\beta\)</span> &beta;</p>` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := Write(parseString(t, tt.input), &out); err != nil {
+				t.Fatalf("unexpected error: err=%v", err)
+			}
+			if got := out.String(); got != tt.wantOut {
+				t.Errorf("unexpected output:\ngot=%v\nwant=%v", got, tt.wantOut)
+			}
+		})
+	}
+}
@@ -0,0 +1,297 @@
+package org
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const defaultEvalTimeout = 10 * time.Second
+
+// ExecRequest is the source code and the environment to execute it.
+type ExecRequest struct {
+	Code string
+	// Vars is the list of `:var` assignments such as `x=1`.
+	Vars []string
+	// Dir is the working directory. Env is the additional environment
+	// variables. The environment of the current process is passed only if
+	// InheritEnv is true.
+	Dir        string
+	Env        []string
+	InheritEnv bool
+}
+
+// Executor executes the source code of a language and returns the output.
+type Executor interface {
+	Execute(ctx context.Context, req ExecRequest) (string, error)
+}
+
+// Evaluator executes source blocks and stores the output as #+RESULTS. It is
+// never used by the writers, so documents are evaluated only when Evaluate is
+// called explicitly.
+type Evaluator struct {
+	// Executors maps languages to executors. Blocks of other languages are not
+	// evaluated.
+	Executors map[string]Executor
+	// Timeout is the time limit of each block.
+	Timeout time.Duration
+	// Dir is the working directory. The `:dir` header argument is resolved
+	// from it, and it cannot be outside of Dir.
+	Dir string
+	// Env is the additional environment variables such as `KEY=value`.
+	Env []string
+	// InheritEnv passes the whole environment of the current process to the
+	// blocks. Otherwise, only PATH, HOME and TMPDIR are passed.
+	InheritEnv bool
+}
+
+// NewEvaluator creates a new Evaluator object which executes the languages
+// of executors.
+func NewEvaluator(executors map[string]Executor) Evaluator {
+	return Evaluator{Executors: executors, Timeout: defaultEvalTimeout}
+}
+
+// DefaultExecutors returns executors for sh, bash and go which run the local
+// toolchains.
+func DefaultExecutors() map[string]Executor {
+	return map[string]Executor{
+		"sh":   ShellExecutor{Shell: "sh"},
+		"bash": ShellExecutor{Shell: "bash"},
+		"go":   GoExecutor{},
+	}
+}
+
+// Evaluate executes the source blocks of supported languages and returns the
+// nodes whose SourceBlock.Results are replaced with the output. Blocks with
+// `:eval no` or `:eval never` are skipped, and the output of blocks with
+// `:results silent` or `:results none` is discarded.
+func (e Evaluator) Evaluate(ctx context.Context, nodes []Node) ([]Node, error) {
+	ret := make([]Node, len(nodes))
+	copy(ret, nodes)
+	for i := range ret {
+		var err error
+		switch n := ret[i].(type) {
+		case Block:
+			n.Children, err = e.Evaluate(ctx, n.Children)
+			ret[i] = n
+		case Drawer:
+			n.Children, err = e.Evaluate(ctx, n.Children)
+			ret[i] = n
+		}
+		if err != nil {
+			return nil, err
+		}
+		block, ok := ret[i].(SourceBlock)
+		if !ok {
+			continue
+		}
+		executor, ok := e.Executors[block.Language]
+		if !ok {
+			continue
+		}
+		args := block.HeaderArgs()
+		if eval, _ := args.Get("eval"); eval == "no" || eval == "never" {
+			continue
+		}
+
+		out, err := e.execute(ctx, executor, block, args)
+		if err != nil {
+			return nil, fmt.Errorf("fail to evaluate %v block at line %d: %w", block.Language, block.Pos.Line, err)
+		}
+		if hasResultsParam(args, "silent") || hasResultsParam(args, "none") {
+			continue
+		}
+		results := &Results{Name: block.Name}
+		if block.Results != nil {
+			results.Pos = block.Results.Pos
+		}
+		if out = strings.TrimRight(out, "\n"); out != "" {
+			results.Value = Nodes{FixedWidth{Content: out}}
+		}
+		block.Results = results
+		ret[i] = block
+	}
+	return ret, nil
+}
+
+func (e Evaluator) execute(ctx context.Context, executor Executor, block SourceBlock, args HeaderArgs) (string, error) {
+	timeout := e.Timeout
+	if timeout <= 0 {
+		timeout = defaultEvalTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	dir, err := e.workDir(args)
+	if err != nil {
+		return "", err
+	}
+	return executor.Execute(ctx, ExecRequest{
+		Code:       block.SourceCode,
+		Vars:       args.Vars,
+		Dir:        dir,
+		Env:        e.Env,
+		InheritEnv: e.InheritEnv,
+	})
+}
+
+// workDir returns the working directory of the `:dir` header argument. It
+// returns an error if the directory is outside of Dir, including the case
+// where a symbolic link points outside.
+func (e Evaluator) workDir(args HeaderArgs) (string, error) {
+	d, ok := args.Get("dir")
+	if !ok || d == "" {
+		return e.Dir, nil
+	}
+	d = unquote(d)
+	base, err := filepath.Abs(e.Dir)
+	if err != nil {
+		return "", err
+	}
+	dir := d
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(base, dir)
+	}
+	rel, ok := relativeDir(base, dir)
+	if !ok {
+		return "", fmt.Errorf("dir is outside of the working directory: %q", d)
+	}
+	realBase, errBase := filepath.EvalSymlinks(base)
+	realDir, errDir := filepath.EvalSymlinks(dir)
+	if errBase == nil && errDir == nil {
+		if _, ok := relativeDir(realBase, realDir); !ok {
+			return "", fmt.Errorf("dir is outside of the working directory: %q", d)
+		}
+	}
+	return filepath.Join(e.Dir, rel), nil
+}
+
+// relativeDir returns the path of dir relative to base. It returns false if
+// dir is not in base.
+func relativeDir(base, dir string) (string, bool) {
+	rel, err := filepath.Rel(base, dir)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return rel, true
+}
+
+func hasResultsParam(args HeaderArgs, param string) bool {
+	for _, r := range args.Results {
+		if r == param {
+			return true
+		}
+	}
+	return false
+}
+
+// ShellExecutor executes the code with the shell. Variables are assigned
+// before the code.
+type ShellExecutor struct {
+	Shell string
+}
+
+func (s ShellExecutor) Execute(ctx context.Context, req ExecRequest) (string, error) {
+	var code strings.Builder
+	for _, v := range req.Vars {
+		parts := strings.SplitN(v, "=", 2)
+		if len(parts) != 2 {
+			return "", fmt.Errorf("invalid variable: %q", v)
+		}
+		fmt.Fprintf(&code, "%s=%s\n", strings.TrimSpace(parts[0]), shellQuote(unquote(strings.TrimSpace(parts[1]))))
+	}
+	code.WriteString(req.Code)
+
+	cmd := exec.Command(s.Shell)
+	cmd.Stdin = strings.NewReader(code.String())
+	return runCommand(ctx, cmd, req)
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// GoExecutor executes the code with `go run`. The code without the package
+// clause is wrapped by the main function. Variables are not supported.
+type GoExecutor struct{}
+
+func (GoExecutor) Execute(ctx context.Context, req ExecRequest) (string, error) {
+	code := req.Code
+	if !strings.Contains(code, "package ") {
+		var imports string
+		if strings.Contains(code, "fmt.") {
+			imports = "import \"fmt\"\n\n"
+		}
+		code = "package main\n\n" + imports + "func main() {\n" + code + "\n}\n"
+	}
+
+	tmp, err := os.MkdirTemp("", "org2html-eval-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmp)
+	file := filepath.Join(tmp, "main.go")
+	if err := os.WriteFile(file, []byte(code), 0o644); err != nil {
+		return "", err
+	}
+	return runCommand(ctx, exec.Command("go", "run", file), req)
+}
+
+// sandboxEnv is the environment variables which are passed to the commands
+// without ExecRequest.InheritEnv.
+var sandboxEnv = []string{"PATH", "HOME", "TMPDIR"}
+
+// runCommand runs the command in the environment of req and returns stdout.
+// The command runs in its own process group, and the whole group is killed
+// when ctx is done because child processes such as the binary of `go run`
+// keep stdout open.
+func runCommand(ctx context.Context, cmd *exec.Cmd, req ExecRequest) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd.Dir = req.Dir
+	cmd.Env = commandEnv(req)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	setProcessGroup(cmd)
+	if err := cmd.Start(); err != nil {
+		return "", err
+	}
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			killProcessGroup(cmd)
+		case <-done:
+		}
+	}()
+	err := cmd.Wait()
+	close(done)
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+	return stdout.String(), nil
+}
+
+// commandEnv returns the environment of the command for req.
+func commandEnv(req ExecRequest) []string {
+	if req.InheritEnv {
+		return append(os.Environ(), req.Env...)
+	}
+	var env []string
+	for _, key := range sandboxEnv {
+		if v, ok := os.LookupEnv(key); ok {
+			env = append(env, key+"="+v)
+		}
+	}
+	return append(env, req.Env...)
+}
@@ -0,0 +1,228 @@
+package org_test
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/Ladicle/org2html/org"
+)
+
+// upperExecutor is an Executor which returns the code in upper case.
+type upperExecutor struct {
+	reqs *[]ExecRequest
+}
+
+func (e upperExecutor) Execute(ctx context.Context, req ExecRequest) (string, error) {
+	*e.reqs = append(*e.reqs, req)
+	return strings.ToUpper(req.Code) + "\n", nil
+}
+
+func TestEvaluator(t *testing.T) {
+	input := `#+begin_src text :var x=1 :dir sub
+hello
+#+end_src
+
+#+RESULTS:
+: old
+
+#+NAME: named
+#+begin_src text
+world
+#+end_src
+
+#+begin_src text :eval no
+skipped
+#+end_src
+
+#+begin_src text :results silent
+silent
+#+end_src
+
+#+begin_src sh
+unsupported
+#+end_src
+`
+	var reqs []ExecRequest
+	e := Evaluator{
+		Executors: map[string]Executor{"text": upperExecutor{reqs: &reqs}},
+		Dir:       "/work",
+		Env:       []string{"A=1"},
+	}
+	nodes, err := e.Evaluate(context.Background(), parseString(t, input))
+	if err != nil {
+		t.Fatalf("unexpected error: err=%v", err)
+	}
+
+	wantReqs := []ExecRequest{
+		{Code: "hello", Vars: []string{"x=1"}, Dir: "/work/sub", Env: []string{"A=1"}},
+		{Code: "world", Dir: "/work", Env: []string{"A=1"}},
+		{Code: "silent", Dir: "/work", Env: []string{"A=1"}},
+	}
+	if !reflect.DeepEqual(reqs, wantReqs) {
+		t.Errorf("unexpected requests:\ngot=%#v\nwant=%#v", reqs, wantReqs)
+	}
+	var results []*Results
+	for _, node := range withoutPos(nodes) {
+		if b, ok := node.(SourceBlock); ok {
+			results = append(results, b.Results)
+		}
+	}
+	wantResults := []*Results{
+		{Value: Nodes{FixedWidth{Content: "HELLO"}}},
+		{Name: "named", Value: Nodes{FixedWidth{Content: "WORLD"}}},
+		nil,
+		nil,
+		nil,
+	}
+	if !reflect.DeepEqual(results, wantResults) {
+		t.Errorf("unexpected results:\ngot=%#v\nwant=%#v", results, wantResults)
+	}
+}
+
+func TestShellExecutor(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not found")
+	}
+	var tests = []struct {
+		desc      string
+		req       ExecRequest
+		wantOut   string
+		wantError error
+	}{
+		{
+			desc:    "variables and environment",
+			req:     ExecRequest{Code: `echo "$x $B"`, Vars: []string{`x="it's"`}, Env: []string{"B=env"}},
+			wantOut: "it's env\n",
+		},
+		{
+			desc:    "environment is not inherited",
+			req:     ExecRequest{Code: `echo "${ORG2HTML_SECRET-unset}"`},
+			wantOut: "unset\n",
+		},
+		{
+			desc:    "inherited environment",
+			req:     ExecRequest{Code: `echo "$ORG2HTML_SECRET"`, InheritEnv: true},
+			wantOut: "secret\n",
+		},
+		{
+			desc:      "failure",
+			req:       ExecRequest{Code: "echo oops >&2; exit 1"},
+			wantError: errors.New("exit status 1: oops"),
+		},
+	}
+	t.Setenv("ORG2HTML_SECRET", "secret")
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			out, err := ShellExecutor{Shell: "sh"}.Execute(context.Background(), tt.req)
+			if err != nil {
+				if tt.wantError == nil || err.Error() != tt.wantError.Error() {
+					t.Fatalf("unexpected error: err=%v, want=%v", err, tt.wantError)
+				}
+				return
+			} else if tt.wantError != nil {
+				t.Fatalf("expect error but not occurred: want=%v", tt.wantError)
+			}
+			if out != tt.wantOut {
+				t.Errorf("unexpected output: got=%q, want=%q", out, tt.wantOut)
+			}
+		})
+	}
+}
+
+func TestExecutorTimeout(t *testing.T) {
+	var tests = []struct {
+		desc     string
+		command  string
+		executor Executor
+		code     string
+		timeout  time.Duration
+	}{
+		{
+			desc:     "child process of shell",
+			command:  "sh",
+			executor: ShellExecutor{Shell: "sh"},
+			code:     "sleep 20; echo hi",
+			timeout:  200 * time.Millisecond,
+		},
+		{
+			desc:     "binary of go run",
+			command:  "go",
+			executor: GoExecutor{},
+			code:     "for {\n}",
+			timeout:  5 * time.Second,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			if _, err := exec.LookPath(tt.command); err != nil {
+				t.Skipf("%s is not found", tt.command)
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), tt.timeout)
+			defer cancel()
+			start := time.Now()
+			_, err := tt.executor.Execute(ctx, ExecRequest{Code: tt.code})
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("unexpected error: err=%v, want=%v", err, context.DeadlineExceeded)
+			}
+			if elapsed := time.Since(start); elapsed > tt.timeout+2*time.Second {
+				t.Errorf("command is not killed by the timeout: elapsed=%v, timeout=%v", elapsed, tt.timeout)
+			}
+		})
+	}
+}
+
+func TestEvaluatorDir(t *testing.T) {
+	var tests = []struct {
+		desc      string
+		dir       string
+		wantDir   string
+		wantError error
+	}{
+		{
+			desc:    "subdirectory",
+			dir:     "sub/../lib",
+			wantDir: "/work/lib",
+		},
+		{
+			desc:    "absolute path in the directory",
+			dir:     "/work/sub",
+			wantDir: "/work/sub",
+		},
+		{
+			desc:      "parent directory",
+			dir:       "../etc",
+			wantError: errors.New(`fail to evaluate text block at line 1: dir is outside of the working directory: "../etc"`),
+		},
+		{
+			desc:      "absolute path outside",
+			dir:       "/etc",
+			wantError: errors.New(`fail to evaluate text block at line 1: dir is outside of the working directory: "/etc"`),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var reqs []ExecRequest
+			e := Evaluator{
+				Executors: map[string]Executor{"text": upperExecutor{reqs: &reqs}},
+				Dir:       "/work",
+			}
+			_, err := e.Evaluate(context.Background(), parseString(t, "#+begin_src text :dir "+tt.dir+"\nhello\n#+end_src\n"))
+			if err != nil {
+				if tt.wantError == nil || err.Error() != tt.wantError.Error() {
+					t.Fatalf("unexpected error: err=%v, want=%v", err, tt.wantError)
+				}
+				return
+			} else if tt.wantError != nil {
+				t.Fatalf("expect error but not occurred: want=%v", tt.wantError)
+			}
+			if len(reqs) != 1 || reqs[0].Dir != tt.wantDir {
+				t.Errorf("unexpected requests: got=%#v, want dir=%v", reqs, tt.wantDir)
+			}
+		})
+	}
+}
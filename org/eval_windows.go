@@ -0,0 +1,11 @@
+package org
+
+import "os/exec"
+
+// setProcessGroup does nothing because process groups are not supported.
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// killProcessGroup kills the command.
+func killProcessGroup(cmd *exec.Cmd) {
+	cmd.Process.Kill()
+}
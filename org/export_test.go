@@ -0,0 +1,8 @@
+package org
+
+// TokenAt returns the token with the position and line which Tokenizer sets.
+func TokenAt(t Token, line int, raw string) Token {
+	t.pos = Pos{Line: line}
+	t.raw = raw
+	return t
+}
@@ -0,0 +1,334 @@
+package org
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"time"
+)
+
+// FeedFormat is the format of the feed.
+type FeedFormat string
+
+const (
+	// FeedAtom is the Atom Syndication Format. https://www.rfc-editor.org/rfc/rfc4287
+	FeedAtom FeedFormat = "atom"
+	// FeedRSS is RSS 2.0. https://www.rssboard.org/rss-specification
+	FeedRSS FeedFormat = "rss"
+)
+
+// Feed is the settings of the feed of the published documents such as a blog.
+type Feed struct {
+	// Format is the format of the feed. FeedAtom is used if it is empty.
+	Format FeedFormat
+	// FileName is the slash separated path of the feed in the published
+	// directory. "atom.xml" or "rss.xml" is used if it is empty.
+	FileName string
+	// Title is the title of the feed.
+	Title string
+	// Description is the subtitle of the feed.
+	Description string
+	// Author is the name of the author of the feed.
+	Author string
+	// Headlines makes entries from the headlines which have the PUBDATE
+	// property or the CLOSED timestamp instead of the documents with #+DATE.
+	Headlines bool
+	// Limit is the maximum number of the entries. All entries are written if
+	// it is zero.
+	Limit int
+}
+
+func (f Feed) format() FeedFormat {
+	if f.Format == "" {
+		return FeedAtom
+	}
+	return f.Format
+}
+
+// fileName returns FileName or the default name of the format.
+func (f Feed) fileName() string {
+	if f.FileName != "" {
+		return f.FileName
+	}
+	return string(f.format()) + ".xml"
+}
+
+// FeedEntry is an entry of the feed.
+type FeedEntry struct {
+	// ID is the unique and permanent ID of the entry.
+	ID    string
+	Title string
+	// URL is the absolute URL of the entry.
+	URL         string
+	Date        time.Time
+	Description string
+	// Content is the HTML of the entry whose links are absolute URLs.
+	Content string
+}
+
+// Entries returns the entries of the documents from the newest one. Each
+// document with #+DATE is an entry with #+TITLE and #+DESCRIPTION. If
+// Headlines is true, each headline with the PUBDATE property such as
+// `<2022-01-30 Sun>` or the CLOSED timestamp is an entry of its subtree.
+// The links in the content are resolved by links and made absolute in
+// baseURL.
+func (f Feed) Entries(docs []Document, links *ProjectLinks, hw HTMLWriter, baseURL string) ([]FeedEntry, error) {
+	var entries []FeedEntry
+	for _, doc := range docs {
+		docURL := absoluteURL(baseURL, doc.HTMLPath())
+		hw := hw
+		hw.BaseURL = docURL
+		hw.ResolveLink = func(target string) (string, bool) {
+			return links.Resolve(doc.Path, target)
+		}
+
+		if !f.Headlines {
+			date, ok := doc.Date()
+			if !ok {
+				continue
+			}
+			content, err := feedContent(hw, doc.Nodes)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", doc.Path, err)
+			}
+			entries = append(entries, FeedEntry{
+				ID:          docURL,
+				Title:       doc.Title(),
+				URL:         docURL,
+				Date:        date,
+				Description: doc.Keyword("DESCRIPTION"),
+				Content:     content,
+			})
+			continue
+		}
+
+		for i := range doc.Nodes {
+			entry, ok, err := headlineFeedEntry(doc.Nodes, i, hw, docURL)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", doc.Path, err)
+			}
+			if ok {
+				entries = append(entries, entry)
+			}
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Date.After(entries[j].Date) })
+	if f.Limit > 0 && len(entries) > f.Limit {
+		entries = entries[:f.Limit]
+	}
+	return entries, nil
+}
+
+// headlineFeedEntry returns the entry of the headline nodes[i]. It returns
+// false if nodes[i] is not a headline or it is not published.
+func headlineFeedEntry(nodes []Node, i int, hw HTMLWriter, docURL string) (FeedEntry, bool, error) {
+	hl, ok := nodes[i].(Headline)
+	if !ok {
+		return FeedEntry{}, false, nil
+	}
+	props, _ := HeadlineProperties(nodes, i)
+	date, ok := headlinePubDate(nodes, i, props)
+	if !ok {
+		return FeedEntry{}, false, nil
+	}
+
+	entry := FeedEntry{
+		ID:    docURL + "#" + url.PathEscape(hl.Title),
+		Title: hl.Title,
+		URL:   docURL,
+		Date:  date,
+	}
+	if id, ok := headlineID(nodes, i); ok {
+		entry.URL = docURL + "#" + id
+		entry.ID = entry.URL
+	}
+	entry.Description, _ = props.Get("DESCRIPTION")
+
+	// the content starts after the agenda and property drawer.
+	start := i + 1
+	for ; start < len(nodes); start++ {
+		switch nodes[start].(type) {
+		case Agenda, PropertyDrawer:
+			continue
+		}
+		break
+	}
+	var err error
+	entry.Content, err = feedContent(hw, nodes[start:subtreeEnd(nodes, i)])
+	return entry, true, err
+}
+
+// headlinePubDate returns the PUBDATE property or the CLOSED timestamp of the
+// headline nodes[i].
+func headlinePubDate(nodes []Node, i int, props PropertyDrawer) (time.Time, bool) {
+	if v, ok := props.Get("PUBDATE"); ok {
+		if ts, err := ParseTimestampLiteral(v); err == nil {
+			return ts.Time, true
+		}
+	}
+	if i+1 < len(nodes) {
+		if agenda, ok := nodes[i+1].(Agenda); ok {
+			if closed, ok := agenda.Logs[AgendaClosed]; ok {
+				return closed.Time, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+func feedContent(hw HTMLWriter, nodes []Node) (string, error) {
+	var buf bytes.Buffer
+	if err := hw.writeExpanded(nodes, &buf); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// atomFeed is the XML of the Atom feed.
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	ID       string      `xml:"id"`
+	Updated  string      `xml:"updated"`
+	Links    []atomLink  `xml:"link"`
+	Author   *atomAuthor `xml:"author,omitempty"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomEntry struct {
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Link    atomLink    `xml:"link"`
+	Summary string      `xml:"summary,omitempty"`
+	Content atomContent `xml:"content"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+// rssFeed is the XML of the RSS 2.0 feed.
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	GUID        rssGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate"`
+	Description string  `xml:"description"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	ID          string `xml:",chardata"`
+}
+
+// Write writes the feed of the entries. baseURL is the URL of the site.
+func (f Feed) Write(w io.Writer, entries []FeedEntry, baseURL string) error {
+	var v interface{}
+	switch f.format() {
+	case FeedAtom:
+		v = f.atom(entries, baseURL)
+	case FeedRSS:
+		v = f.rss(entries, baseURL)
+	default:
+		return fmt.Errorf("unsupported feed format: %q", f.Format)
+	}
+	io.WriteString(w, xml.Header)
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func (f Feed) atom(entries []FeedEntry, baseURL string) atomFeed {
+	feed := atomFeed{
+		Title:    f.Title,
+		Subtitle: f.Description,
+		ID:       absoluteURL(baseURL, f.fileName()),
+		Updated:  latestFeedDate(entries).Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: absoluteURL(baseURL, f.fileName()), Rel: "self"},
+			{Href: absoluteURL(baseURL, "")},
+		},
+	}
+	if f.Author != "" {
+		feed.Author = &atomAuthor{Name: f.Author}
+	}
+	for _, e := range entries {
+		feed.Entries = append(feed.Entries, atomEntry{
+			Title:   e.Title,
+			ID:      e.ID,
+			Updated: e.Date.Format(time.RFC3339),
+			Link:    atomLink{Href: e.URL},
+			Summary: e.Description,
+			Content: atomContent{Type: "html", Body: e.Content},
+		})
+	}
+	return feed
+}
+
+func (f Feed) rss(entries []FeedEntry, baseURL string) rssFeed {
+	feed := rssFeed{
+		Version: "2.0",
+		Channel: rssChannel{
+			Title:       f.Title,
+			Link:        absoluteURL(baseURL, ""),
+			Description: f.Description,
+		},
+	}
+	if len(entries) > 0 {
+		feed.Channel.LastBuildDate = latestFeedDate(entries).Format(time.RFC1123Z)
+	}
+	for _, e := range entries {
+		feed.Channel.Items = append(feed.Channel.Items, rssItem{
+			Title:       e.Title,
+			Link:        e.URL,
+			GUID:        rssGUID{IsPermaLink: e.ID == e.URL, ID: e.ID},
+			PubDate:     e.Date.Format(time.RFC1123Z),
+			Description: e.Content,
+		})
+	}
+	return feed
+}
+
+// latestFeedDate returns the date of the newest entry.
+func latestFeedDate(entries []FeedEntry) time.Time {
+	var latest time.Time
+	for _, e := range entries {
+		if e.Date.After(latest) {
+			latest = e.Date
+		}
+	}
+	return latest
+}
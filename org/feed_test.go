@@ -0,0 +1,189 @@
+package org_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/Ladicle/org2html/org"
+)
+
+func feedDocuments(t *testing.T) []Document {
+	return []Document{
+		{Path: "index.org", Nodes: parseString(t, "#+TITLE: home\n")},
+		{Path: "posts/a.org", Nodes: parseString(t, "#+TITLE: First\n#+DATE: <2022-01-30 Sun>\n#+DESCRIPTION: the first post\nSee [[file:b.org][b]].\n")},
+		{Path: "posts/b.org", Nodes: parseString(t, "#+DATE: 2022-02-03\n[[file:img/b.png]]\n")},
+		{Path: "log.org", Nodes: parseString(t, "* DONE Released\nCLOSED: [2022-02-01 Tue 10:00]\nv1\n"+
+			"* Announce\n:PROPERTIES:\n:CUSTOM_ID: announce\n:PUBDATE: <2022-01-15 Sat>\n:DESCRIPTION: news\n:END:\nhello\n** Details\n"+
+			"* Draft\nnot yet\n")},
+	}
+}
+
+func TestFeedEntries(t *testing.T) {
+	var tests = []struct {
+		desc        string
+		feed        Feed
+		wantEntries []FeedEntry
+	}{
+		{
+			desc: "documents",
+			feed: Feed{},
+			wantEntries: []FeedEntry{
+				{
+					ID:      "https://example.com/posts/b.html",
+					Title:   "b",
+					URL:     "https://example.com/posts/b.html",
+					Date:    time.Date(2022, 2, 3, 0, 0, 0, 0, time.UTC),
+					Content: `<p><img src="https://example.com/posts/img/b.png" alt="b.png"></p>` + "\n",
+				},
+				{
+					ID:          "https://example.com/posts/a.html",
+					Title:       "First",
+					URL:         "https://example.com/posts/a.html",
+					Date:        time.Date(2022, 1, 30, 0, 0, 0, 0, time.UTC),
+					Description: "the first post",
+					Content:     `<p>See <a href="https://example.com/posts/b.html">b</a>.</p>` + "\n",
+				},
+			},
+		},
+		{
+			desc: "headlines",
+			feed: Feed{Headlines: true},
+			wantEntries: []FeedEntry{
+				{
+					ID:      "https://example.com/log.html#Released",
+					Title:   "Released",
+					URL:     "https://example.com/log.html",
+					Date:    time.Date(2022, 2, 1, 10, 0, 0, 0, time.UTC),
+					Content: "<p>v1</p>\n",
+				},
+				{
+					ID:          "https://example.com/log.html#announce",
+					Title:       "Announce",
+					URL:         "https://example.com/log.html#announce",
+					Date:        time.Date(2022, 1, 15, 0, 0, 0, 0, time.UTC),
+					Description: "news",
+					Content:     "<p>hello</p>\n<h2 class=\"org-headline\">\nDetails\n</h2>\n",
+				},
+			},
+		},
+		{
+			desc: "limit",
+			feed: Feed{Limit: 1},
+			wantEntries: []FeedEntry{
+				{
+					ID:      "https://example.com/posts/b.html",
+					Title:   "b",
+					URL:     "https://example.com/posts/b.html",
+					Date:    time.Date(2022, 2, 3, 0, 0, 0, 0, time.UTC),
+					Content: `<p><img src="https://example.com/posts/img/b.png" alt="b.png"></p>` + "\n",
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			docs := feedDocuments(t)
+			entries, err := tt.feed.Entries(docs, NewProjectLinks(docs), HTMLWriter{}, "https://example.com/")
+			if err != nil {
+				t.Fatalf("unexpected error: err=%v", err)
+			}
+			if len(entries) != len(tt.wantEntries) {
+				t.Fatalf("unexpected number of entries: got=%v, want=%v", len(entries), len(tt.wantEntries))
+			}
+			for i := range entries {
+				if entries[i] != tt.wantEntries[i] {
+					t.Errorf("unexpected entry:\ngot=%#v\nwant=%#v", entries[i], tt.wantEntries[i])
+				}
+			}
+		})
+	}
+}
+
+func TestFeedWrite(t *testing.T) {
+	entries := []FeedEntry{
+		{
+			ID:          "https://example.com/a.html",
+			Title:       "A & B",
+			URL:         "https://example.com/a.html",
+			Date:        time.Date(2022, 1, 30, 0, 0, 0, 0, time.UTC),
+			Description: "about a",
+			Content:     "<p>a</p>\n",
+		},
+	}
+	var tests = []struct {
+		desc      string
+		feed      Feed
+		wantOut   string
+		wantError error
+	}{
+		{
+			desc: "atom",
+			feed: Feed{Title: "Blog", Author: "Ladicle"},
+			wantOut: `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>Blog</title>
+  <id>https://example.com/atom.xml</id>
+  <updated>2022-01-30T00:00:00Z</updated>
+  <link href="https://example.com/atom.xml" rel="self"></link>
+  <link href="https://example.com/"></link>
+  <author>
+    <name>Ladicle</name>
+  </author>
+  <entry>
+    <title>A &amp; B</title>
+    <id>https://example.com/a.html</id>
+    <updated>2022-01-30T00:00:00Z</updated>
+    <link href="https://example.com/a.html"></link>
+    <summary>about a</summary>
+    <content type="html">&lt;p&gt;a&lt;/p&gt;&#xA;</content>
+  </entry>
+</feed>
+`,
+		},
+		{
+			desc: "rss",
+			feed: Feed{Format: FeedRSS, Title: "Blog", Description: "notes"},
+			wantOut: `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0">
+  <channel>
+    <title>Blog</title>
+    <link>https://example.com/</link>
+    <description>notes</description>
+    <lastBuildDate>Sun, 30 Jan 2022 00:00:00 +0000</lastBuildDate>
+    <item>
+      <title>A &amp; B</title>
+      <link>https://example.com/a.html</link>
+      <guid isPermaLink="true">https://example.com/a.html</guid>
+      <pubDate>Sun, 30 Jan 2022 00:00:00 +0000</pubDate>
+      <description>&lt;p&gt;a&lt;/p&gt;&#xA;</description>
+    </item>
+  </channel>
+</rss>
+`,
+		},
+		{
+			desc:      "unsupported format",
+			feed:      Feed{Format: "json"},
+			wantError: errors.New(`unsupported feed format: "json"`),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var out bytes.Buffer
+			err := tt.feed.Write(&out, entries, "https://example.com")
+			if err != nil {
+				if tt.wantError == nil || err.Error() != tt.wantError.Error() {
+					t.Fatalf("unexpected error: err=%v, want=%v", err, tt.wantError)
+				}
+				return
+			} else if tt.wantError != nil {
+				t.Fatalf("expect error but not occurred: want=%v", tt.wantError)
+			}
+			if got := out.String(); got != tt.wantOut {
+				t.Errorf("unexpected output:\ngot=%v\nwant=%v", got, tt.wantOut)
+			}
+		})
+	}
+}
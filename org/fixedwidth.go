@@ -0,0 +1,52 @@
+package org
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+var _ Node = FixedWidth{}
+
+// FixedWidth is a Node to describe fixed-width areas such as `: text`. The
+// whitespace of lines is preserved.
+type FixedWidth struct {
+	Pos     Pos    `json:"pos"`
+	Content string `json:"content"`
+}
+
+func (f FixedWidth) Write(w io.Writer) error {
+	fmt.Fprintln(w, `<pre class="example">`)
+	fmt.Fprintln(w, codeEscaper.Replace(f.Content))
+	fmt.Fprintln(w, "</pre>")
+	return nil
+}
+
+var fixedWidthRegexp = regexp.MustCompile(`^\s*:(?: (.*)|$)`)
+
+// LexFixedWidth lexes the line which starts with a colon followed by a space
+// or the end of line. The value is the rest of the line as is.
+func LexFixedWidth(line string) (Token, bool) {
+	if m := fixedWidthRegexp.FindStringSubmatch(line); m != nil {
+		return NewToken(KindFixedWidth, 1, m[1:]), true
+	}
+	return Token{}, false
+}
+
+func ParseFixedWidth(p *Parser, i int) (int, Node, error) {
+	var (
+		lines []string
+		start = i
+	)
+	for ; i < len(p.tokens) && p.tokens[i].kind == KindFixedWidth; i++ {
+		if len(p.tokens[i].vals) != 1 {
+			return 0, nil, fmt.Errorf("fixed-width token[%d] does not have any values", i)
+		}
+		lines = append(lines, p.tokens[i].vals[0])
+	}
+	return i - start, FixedWidth{
+		Pos:     p.tokens[start].pos,
+		Content: strings.Join(lines, "\n"),
+	}, nil
+}
@@ -0,0 +1,91 @@
+package org_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	. "github.com/Ladicle/org2html/org"
+)
+
+func TestLexFixedWidth(t *testing.T) {
+	var tests = []struct {
+		desc      string
+		line      string
+		wantToken Token
+		wantFlag  bool
+	}{
+		{
+			desc: "empty line",
+			line: "",
+		},
+		{
+			desc:      "fixed-width line",
+			line:      "  : hello  world ",
+			wantFlag:  true,
+			wantToken: NewToken(KindFixedWidth, 1, []string{"hello  world "}),
+		},
+		{
+			desc:      "indented content",
+			line:      ":    indented",
+			wantFlag:  true,
+			wantToken: NewToken(KindFixedWidth, 1, []string{"   indented"}),
+		},
+		{
+			desc:      "only colon",
+			line:      ":",
+			wantFlag:  true,
+			wantToken: NewToken(KindFixedWidth, 1, []string{""}),
+		},
+		{
+			desc: "drawer",
+			line: ":END:",
+		},
+		{
+			desc: "colon without space",
+			line: ":text",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			token, flag := LexFixedWidth(tt.line)
+			if flag != tt.wantFlag {
+				t.Errorf("unexpected flag: got=%v, want=%v", flag, tt.wantFlag)
+			}
+			if !reflect.DeepEqual(token, tt.wantToken) {
+				t.Errorf("unexpected token:\ngot=%#v\nwant=%#v", token, tt.wantToken)
+			}
+		})
+	}
+}
+
+func TestParseFixedWidth(t *testing.T) {
+	tokens := []Token{
+		NewToken(KindFixedWidth, 1, []string{"$ ls"}),
+		NewToken(KindFixedWidth, 1, []string{""}),
+		NewToken(KindFixedWidth, 1, []string{"  a  b"}),
+		NewToken(KindText, 1, []string{"text"}),
+	}
+	parser := DefaultParser(tokens)
+	consumed, node, err := ParseFixedWidth(&parser, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: err=%v", err)
+	}
+	if want := 3; consumed != want {
+		t.Errorf("unexpected consumed: got=%v, want=%v", consumed, want)
+	}
+	if want := (FixedWidth{Content: "$ ls\n\n  a  b"}); !reflect.DeepEqual(node, want) {
+		t.Errorf("unexpected node:\ngot=%#v\nwant=%#v", node, want)
+	}
+}
+
+func TestFixedWidthWriter(t *testing.T) {
+	var out bytes.Buffer
+	if err := (FixedWidth{Content: "if a < b {\n    return\n}"}).Write(&out); err != nil {
+		t.Fatalf("unexpected error: err=%v", err)
+	}
+	want := "<pre class=\"example\">\nif a &lt; b {\n    return\n}\n</pre>\n"
+	if got := out.String(); got != want {
+		t.Errorf("unexpected output: got=%v, want=%v", got, want)
+	}
+}
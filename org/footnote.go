@@ -0,0 +1,269 @@
+package org
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const printFootnotesKey = "PRINT_FOOTNOTES"
+
+var _ Node = FootnoteDefinition{}
+
+// FootnoteDefinition is a Node to describe footnote definitions such as
+// `[fn:1] text`. Children is the parsed Content. Definitions are written in
+// the footnotes section instead of their position.
+type FootnoteDefinition struct {
+	Pos      Pos    `json:"pos"`
+	Label    string `json:"label"`
+	Content  string `json:"content"`
+	Children Nodes  `json:"children,omitempty"`
+}
+
+func (d FootnoteDefinition) Write(w io.Writer) error {
+	// noop
+	return nil
+}
+
+var (
+	footnoteDefinitionRegexp = regexp.MustCompile(`^\[fn:([\w-]+)\](?:\s+(.*?))?\s*$`)
+	// footnoteRefRegexp matches `[fn:label]`, `[fn:label: definition]` and
+	// `[fn:: definition]`. The definition can contain brackets such as links.
+	footnoteRefRegexp = regexp.MustCompile(`\[fn:([\w-]*)(?::((?:[^\[\]]|\[[^\[\]]*\])*))?\]`)
+)
+
+// LexFootnoteDefinition lexes the first line of footnote definitions, which
+// must start at the beginning of the line.
+func LexFootnoteDefinition(line string) (Token, bool) {
+	if m := footnoteDefinitionRegexp.FindStringSubmatch(line); m != nil {
+		return NewToken(KindFootnoteDefinition, 1, m[1:]), true
+	}
+	return Token{}, false
+}
+
+// ParseFootnoteDefinition parses the footnote definition. It ends at the next
+// footnote definition, the next headline or two consecutive blank lines.
+func ParseFootnoteDefinition(p *Parser, i int) (int, Node, error) {
+	if len(p.tokens[i].vals) != 2 {
+		return 0, nil, fmt.Errorf("footnote token[%d] does not have 2 values: got=%d", i, len(p.tokens[i].vals))
+	}
+	end := i + 1
+	for ; end < len(p.tokens); end++ {
+		if kind := p.tokens[end].kind; kind == KindHeadline || kind == KindFootnoteDefinition {
+			break
+		}
+		if isBlankToken(p.tokens[end]) && end+1 < len(p.tokens) && isBlankToken(p.tokens[end+1]) {
+			break
+		}
+	}
+	last := end
+	for last > i+1 && isBlankToken(p.tokens[last-1]) {
+		last--
+	}
+
+	// the rest of the first line is the first paragraph.
+	first := NewToken(KindText, 1, []string{p.tokens[i].vals[1]})
+	first.pos = p.tokens[i].pos
+	tokens := append([]Token{first}, p.tokens[i+1:last]...)
+	children, err := (&Parser{tokens: tokens, parseFns: p.parseFns, doc: p.document()}).Parse()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	lines := []string{p.tokens[i].vals[1]}
+	for _, t := range p.tokens[i+1 : last] {
+		lines = append(lines, strings.TrimRight(t.text(), " \t"))
+	}
+	return end - i, FootnoteDefinition{
+		Pos:      p.tokens[i].pos,
+		Label:    p.tokens[i].vals[0],
+		Content:  strings.Join(lines, "\n"),
+		Children: children,
+	}, nil
+}
+
+func isBlankToken(t Token) bool {
+	return t.kind == KindText && len(t.vals) > 0 && t.vals[0] == ""
+}
+
+// footnoteDefinitions returns the footnote definitions in the document order.
+func footnoteDefinitions(nodes []Node) []FootnoteDefinition {
+	var defs []FootnoteDefinition
+	for _, node := range nodes {
+		switch n := node.(type) {
+		case FootnoteDefinition:
+			defs = append(defs, n)
+			defs = append(defs, footnoteDefinitions(n.Children)...)
+		case Block:
+			defs = append(defs, footnoteDefinitions(n.Children)...)
+		case Drawer:
+			defs = append(defs, footnoteDefinitions(n.Children)...)
+		case DynamicBlock:
+			defs = append(defs, footnoteDefinitions(n.Children)...)
+		}
+	}
+	return defs
+}
+
+// footnote is the definition of a footnote. Labelled definitions have nodes,
+// and inline definitions have text.
+type footnote struct {
+	nodes Nodes
+	text  string
+}
+
+// footnotes is the state of footnotes while writing a document. Footnotes are
+// numbered in order of the first reference.
+type footnotes struct {
+	defs map[string]footnote
+	// labels is the referenced labels. The footnote number is the index + 1.
+	labels  []string
+	numbers map[string]int
+	refs    map[string]int
+	// anonymous is the number of anonymous footnotes.
+	anonymous int
+	// written is the number of footnotes written in the footnotes section.
+	written int
+}
+
+func newFootnotes(nodes []Node) *footnotes {
+	f := &footnotes{
+		defs:    make(map[string]footnote),
+		numbers: make(map[string]int),
+		refs:    make(map[string]int),
+	}
+	for _, def := range footnoteDefinitions(nodes) {
+		if _, ok := f.defs[def.Label]; !ok {
+			f.defs[def.Label] = footnote{nodes: def.Children}
+		}
+	}
+	walkInline(nodes, func(_ Pos, text string) {
+		for _, m := range footnoteRefRegexp.FindAllStringSubmatch(text, -1) {
+			if _, ok := f.defs[m[1]]; !ok && m[1] != "" && m[2] != "" {
+				f.defs[m[1]] = footnote{text: strings.TrimSpace(m[2])}
+			}
+		}
+	})
+	return f
+}
+
+// reference returns the number of the footnote and the number of its
+// references so far. It returns false if the footnote is not defined.
+func (f *footnotes) reference(label, def string) (n, count int, ok bool) {
+	if label == "" {
+		if def == "" {
+			return 0, 0, false
+		}
+		// anonymous labels cannot conflict with the others.
+		f.anonymous++
+		label = "anonymous." + strconv.Itoa(f.anonymous)
+		f.defs[label] = footnote{text: def}
+	}
+	if _, ok := f.defs[label]; !ok {
+		return 0, 0, false
+	}
+	n, ok = f.numbers[label]
+	if !ok {
+		f.labels = append(f.labels, label)
+		n = len(f.labels)
+		f.numbers[label] = n
+	}
+	f.refs[label]++
+	return n, f.refs[label], true
+}
+
+// footnotes returns the footnotes state. Nodes written without HTMLWriter.Write
+// have their own state.
+func (ctx *htmlContext) footnotes() *footnotes {
+	if ctx.notes == nil {
+		ctx.notes = newFootnotes(nil)
+	}
+	return ctx.notes
+}
+
+// writeFootnoteRef writes the footnote reference which links to the
+// definition. Undefined footnotes are written as text.
+func writeFootnoteRef(w io.Writer, ctx *htmlContext, m []string) {
+	n, count, ok := ctx.footnotes().reference(m[1], strings.TrimSpace(m[2]))
+	if !ok {
+		io.WriteString(w, codeEscaper.Replace(m[0]))
+		return
+	}
+	id := "fnr." + strconv.Itoa(n)
+	if count > 1 {
+		id += "." + strconv.Itoa(count)
+	}
+	fmt.Fprintf(w, `<sup><a id="%s" class="footref" href="#fn.%d" role="doc-backlink">%d</a></sup>`, id, n, n)
+}
+
+// writeFootnotes writes the footnotes section of the footnotes which are
+// referenced but not written yet.
+func (ctx *htmlContext) writeFootnotes(w io.Writer) error {
+	f := ctx.footnotes()
+	if f.written == len(f.labels) {
+		return nil
+	}
+	fmt.Fprintln(w, `<div id="footnotes">`)
+	fmt.Fprintln(w, `<h2 class="footnotes">Footnotes</h2>`)
+	// definitions can reference other footnotes, which are appended to labels.
+	for ; f.written < len(f.labels); f.written++ {
+		n := f.written + 1
+		def := f.defs[f.labels[f.written]]
+		fmt.Fprintf(w, "<div class=\"footdef\"><sup><a id=\"fn.%d\" class=\"footnum\" href=\"#fnr.%d\" role=\"doc-backlink\">%d</a></sup>\n", n, n, n)
+		fmt.Fprintln(w, `<div class="footpara" role="doc-footnote">`)
+		if def.nodes != nil {
+			if err := ctx.writeAll(w, def.nodes); err != nil {
+				return err
+			}
+		} else {
+			fmt.Fprint(w, "<p>")
+			ctx.writeInline(w, def.text)
+			fmt.Fprintln(w, "</p>")
+		}
+		fmt.Fprintln(w, "</div>")
+		fmt.Fprintln(w, "</div>")
+	}
+	fmt.Fprintln(w, "</div>")
+	return nil
+}
+
+// footnoteDiagnostics reports undefined, unreferenced and duplicated footnotes.
+func footnoteDiagnostics(nodes []Node) []Diagnostic {
+	var (
+		diags      []Diagnostic
+		defined    = make(map[string]bool)
+		referenced = make(map[string]bool)
+	)
+	for _, def := range footnoteDefinitions(nodes) {
+		if defined[def.Label] {
+			diags = append(diags, Diagnostic{Pos: def.Pos, Message: fmt.Sprintf("footnote %q is defined more than once", def.Label)})
+		}
+		defined[def.Label] = true
+	}
+	walkInline(nodes, func(_ Pos, text string) {
+		for _, m := range footnoteRefRegexp.FindAllStringSubmatch(text, -1) {
+			if m[1] != "" && m[2] != "" {
+				defined[m[1]] = true
+			}
+		}
+	})
+	walkInline(nodes, func(pos Pos, text string) {
+		for _, m := range footnoteRefRegexp.FindAllStringSubmatch(text, -1) {
+			if m[1] == "" {
+				continue
+			}
+			if !defined[m[1]] {
+				diags = append(diags, Diagnostic{Pos: pos, Message: fmt.Sprintf("footnote %q is not defined", m[1])})
+			}
+			referenced[m[1]] = true
+		}
+	})
+	for _, def := range footnoteDefinitions(nodes) {
+		if !referenced[def.Label] {
+			diags = append(diags, Diagnostic{Pos: def.Pos, Message: fmt.Sprintf("footnote %q is not referenced", def.Label)})
+		}
+	}
+	return diags
+}
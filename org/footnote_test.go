@@ -0,0 +1,162 @@
+package org_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	. "github.com/Ladicle/org2html/org"
+)
+
+func TestParseFootnoteDefinition(t *testing.T) {
+	var tests = []struct {
+		desc      string
+		input     string
+		wantNodes []Node
+	}{
+		{
+			desc:  "multiple paragraphs",
+			input: "[fn:1] first\nline\n\n- item\n\n\nafter\n",
+			wantNodes: []Node{
+				FootnoteDefinition{
+					Label:   "1",
+					Content: "first\nline\n\n- item",
+					Children: Nodes{
+						Section{Paragraphs: []string{"first\nline"}},
+						List{Items: []ListItem{{Bullet: "-", Content: "item"}}},
+					},
+				},
+				Section{Paragraphs: []string{"after"}},
+			},
+		},
+		{
+			desc:  "next definition and headline",
+			input: "[fn:a]\ntext\n[fn:b] b\n* headline\n",
+			wantNodes: []Node{
+				FootnoteDefinition{Label: "a", Content: "\ntext", Children: Nodes{Section{Paragraphs: []string{"text"}}}},
+				FootnoteDefinition{Label: "b", Content: "b", Children: Nodes{Section{Paragraphs: []string{"b"}}}},
+				Headline{Starts: 1, Title: "headline"},
+			},
+		},
+		{
+			desc:      "indented definition",
+			input:     "  [fn:1] text\n",
+			wantNodes: []Node{Section{Paragraphs: []string{"[fn:1] text"}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			if got := withoutPos(parseString(t, tt.input)); !reflect.DeepEqual(got, tt.wantNodes) {
+				t.Errorf("unexpected nodes:\ngot=%#v\nwant=%#v", got, tt.wantNodes)
+			}
+		})
+	}
+}
+
+func TestFootnoteWriter(t *testing.T) {
+	var tests = []struct {
+		desc    string
+		input   string
+		wantOut string
+	}{
+		{
+			desc:  "numbered by references",
+			input: "a[fn:b] b[fn:a] c[fn:b] <d>[fn:: anonymous]\n\n[fn:a] A\n\n[fn:b] B[fn:nested: N]\n",
+			wantOut: `<p>a<sup><a id="fnr.1" class="footref" href="#fn.1" role="doc-backlink">1</a></sup> ` +
+				`b<sup><a id="fnr.2" class="footref" href="#fn.2" role="doc-backlink">2</a></sup> ` +
+				`c<sup><a id="fnr.1.2" class="footref" href="#fn.1" role="doc-backlink">1</a></sup> ` +
+				`&lt;d&gt;<sup><a id="fnr.3" class="footref" href="#fn.3" role="doc-backlink">3</a></sup></p>
+<div id="footnotes">
+<h2 class="footnotes">Footnotes</h2>
+<div class="footdef"><sup><a id="fn.1" class="footnum" href="#fnr.1" role="doc-backlink">1</a></sup>
+<div class="footpara" role="doc-footnote">
+<p>B<sup><a id="fnr.4" class="footref" href="#fn.4" role="doc-backlink">4</a></sup></p>
+</div>
+</div>
+<div class="footdef"><sup><a id="fn.2" class="footnum" href="#fnr.2" role="doc-backlink">2</a></sup>
+<div class="footpara" role="doc-footnote">
+<p>A</p>
+</div>
+</div>
+<div class="footdef"><sup><a id="fn.3" class="footnum" href="#fnr.3" role="doc-backlink">3</a></sup>
+<div class="footpara" role="doc-footnote">
+<p>anonymous</p>
+</div>
+</div>
+<div class="footdef"><sup><a id="fn.4" class="footnum" href="#fnr.4" role="doc-backlink">4</a></sup>
+<div class="footpara" role="doc-footnote">
+<p>N</p>
+</div>
+</div>
+</div>
+`,
+		},
+		{
+			desc:  "print footnotes and undefined footnote",
+			input: "- a[fn:1] [fn:x]\n\n#+PRINT_FOOTNOTES:\n\n| end |\n\n[fn:1] one\n",
+			wantOut: `<ul class="org-list">
+<li>a<sup><a id="fnr.1" class="footref" href="#fn.1" role="doc-backlink">1</a></sup> [fn:x]</li>
+</ul>
+<div id="footnotes">
+<h2 class="footnotes">Footnotes</h2>
+<div class="footdef"><sup><a id="fn.1" class="footnum" href="#fnr.1" role="doc-backlink">1</a></sup>
+<div class="footpara" role="doc-footnote">
+<p>one</p>
+</div>
+</div>
+</div>
+<table class="org-table">
+<tbody>
+<tr><td>end</td></tr>
+</tbody>
+</table>
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := Write(parseString(t, tt.input), &out); err != nil {
+				t.Fatalf("unexpected error: err=%v", err)
+			}
+			if got := out.String(); got != tt.wantOut {
+				t.Errorf("unexpected output:\ngot=%v\nwant=%v", got, tt.wantOut)
+			}
+		})
+	}
+}
+
+func TestDiagnoseFootnotes(t *testing.T) {
+	nodes := parseString(t, `text[fn:1][fn:x][fn:inline: def]
+
+[fn:1] used
+
+[fn:unused] unused
+
+[fn:1] duplicated
+`)
+	want := []string{
+		`1: footnote "x" is not defined`,
+		`5: footnote "unused" is not referenced`,
+		`7: footnote "1" is defined more than once`,
+	}
+	var got []string
+	for _, d := range Diagnose(nodes) {
+		got = append(got, d.String())
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected diagnostics:\ngot=%#v\nwant=%#v", got, want)
+	}
+}
+
+func TestWriteMarkdownFootnotes(t *testing.T) {
+	input := "text[fn:1] and[fn:: anonymous]\n\n[fn:1] one\n\ntwo\n"
+	want := "text[^1] and[^anonymous-1-1]\n\n[^anonymous-1-1]: anonymous\n\n[^1]: one\n\n    two\n"
+	var out bytes.Buffer
+	if err := WriteMarkdown(parseString(t, input), &out); err != nil {
+		t.Fatalf("unexpected error: err=%v", err)
+	}
+	if got := out.String(); got != want {
+		t.Errorf("unexpected output:\ngot=%q\nwant=%q", got, want)
+	}
+}
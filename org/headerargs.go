@@ -0,0 +1,187 @@
+package org
+
+import (
+	"strings"
+)
+
+// Values of the :exports header argument.
+const (
+	ExportsCode    = "code"
+	ExportsResults = "results"
+	ExportsBoth    = "both"
+	ExportsNone    = "none"
+)
+
+const (
+	propertyKey   = "PROPERTY"
+	headerKey     = "HEADER"
+	headerArgsKey = "header-args"
+)
+
+// HeaderArgs is the header arguments of a source block such as
+// `:exports both :results output`.
+type HeaderArgs struct {
+	// Exports is one of code, results, both and none.
+	Exports string
+	Results []string
+	Tangle  string
+	Noweb   string
+	Session string
+	// Vars is the list of `:var` assignments such as `x=1`.
+	Vars []string
+	// Other is the other header arguments such as `:details`.
+	Other map[string]string
+}
+
+// Get returns the value of the header argument without the colon.
+func (h HeaderArgs) Get(name string) (string, bool) {
+	switch name {
+	case "exports":
+		return h.Exports, true
+	case "results":
+		return strings.Join(h.Results, " "), h.Results != nil
+	case "tangle":
+		return h.Tangle, true
+	case "noweb":
+		return h.Noweb, true
+	case "session":
+		return h.Session, h.Session != ""
+	case "var":
+		return strings.Join(h.Vars, " "), h.Vars != nil
+	}
+	val, ok := h.Other[name]
+	return val, ok
+}
+
+// enabled reports whether the header argument is set to a non-nil value such
+// as `:details t`.
+func (h HeaderArgs) enabled(name string) bool {
+	val, ok := h.Get(name)
+	return ok && val != "nil" && val != "no"
+}
+
+// exportsCode reports whether the source code is exported.
+func (h HeaderArgs) exportsCode() bool {
+	return h.Exports == ExportsCode || h.Exports == ExportsBoth
+}
+
+// exportsResults reports whether the results are exported.
+func (h HeaderArgs) exportsResults() bool {
+	return h.Exports == ExportsResults || h.Exports == ExportsBoth
+}
+
+// parseHeaderArgs merges header arguments such as `exports both`. The later
+// arguments overwrite the former ones except `:var`.
+func parseHeaderArgs(args ...[]string) HeaderArgs {
+	h := HeaderArgs{
+		Exports: ExportsCode,
+		Tangle:  "no",
+		Noweb:   "no",
+	}
+	for _, list := range args {
+		for _, arg := range list {
+			parts := strings.SplitN(strings.TrimPrefix(arg, ":"), " ", 2)
+			name := strings.ToLower(parts[0])
+			var val string
+			if len(parts) == 2 {
+				val = strings.TrimSpace(parts[1])
+			}
+			switch name {
+			case "exports":
+				h.Exports = val
+			case "results":
+				h.Results = strings.Fields(val)
+			case "tangle":
+				h.Tangle = val
+			case "noweb":
+				h.Noweb = val
+			case "session":
+				h.Session = val
+			case "var":
+				h.Vars = append(h.Vars, val)
+			default:
+				if h.Other == nil {
+					h.Other = make(map[string]string)
+				}
+				h.Other[name] = val
+			}
+		}
+	}
+	return h
+}
+
+// splitHeaderArgs splits the header arguments such as `:var x=1 :exports both`
+// into `var x=1` and `exports both`. An argument starts with a colon after
+// spaces, and colons in double quotes and parentheses such as
+// `:var s="a :b"` are a part of the value.
+func splitHeaderArgs(s string) []string {
+	var (
+		args  []string
+		start int
+		quote bool
+		depth int
+	)
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote:
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				quote = false
+			}
+		case c == '"':
+			quote = true
+		case c == '(':
+			depth++
+		case c == ')':
+			if depth > 0 {
+				depth--
+			}
+		case c == ':' && depth == 0 && (i == 0 || s[i-1] == ' ' || s[i-1] == '\t'):
+			if arg := strings.TrimSpace(s[start:i]); arg != "" {
+				args = append(args, arg)
+			}
+			start = i + 1
+		}
+	}
+	if arg := strings.TrimSpace(s[start:]); arg != "" {
+		args = append(args, arg)
+	}
+	return args
+}
+
+// inheritedHeaderArgs returns the header arguments of the source block
+// p.tokens[i] from `#+PROPERTY: header-args` keywords in the document and
+// `#+HEADER:` keywords just before the block. `#+PROPERTY: header-args`
+// replaces the former arguments, and `#+PROPERTY: header-args+` appends to
+// them.
+func (p *Parser) inheritedHeaderArgs(i int, lang string) []string {
+	var global, local []string
+	for _, t := range p.document() {
+		if t.kind != KindKeyword || len(t.vals) != 2 || !strings.EqualFold(t.vals[0], propertyKey) {
+			continue
+		}
+		parts := strings.SplitN(strings.TrimSpace(t.vals[1]), " ", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		key, args := parts[0], splitHeaderArgs(parts[1])
+		switch strings.TrimSuffix(key, "+") {
+		case headerArgsKey:
+			global = mergeHeaderArgs(global, key, args)
+		case headerArgsKey + ":" + lang:
+			local = mergeHeaderArgs(local, key, args)
+		}
+	}
+	args := append(global, local...)
+	return append(args, p.affiliatedValues(i, headerKey, splitHeaderArgs)...)
+}
+
+// mergeHeaderArgs appends the arguments of the property key with the trailing
+// `+`, or replaces the former arguments with them.
+func mergeHeaderArgs(former []string, key string, args []string) []string {
+	if strings.HasSuffix(key, "+") {
+		return append(former, args...)
+	}
+	return args
+}
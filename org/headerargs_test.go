@@ -0,0 +1,100 @@
+package org_test
+
+import (
+	"reflect"
+	"testing"
+
+	. "github.com/Ladicle/org2html/org"
+)
+
+func TestHeaderArgs(t *testing.T) {
+	var tests = []struct {
+		desc     string
+		input    string
+		wantArgs HeaderArgs
+	}{
+		{
+			desc:  "default",
+			input: "#+begin_src go\n#+end_src\n",
+			wantArgs: HeaderArgs{
+				Exports: ExportsCode,
+				Tangle:  "no",
+				Noweb:   "no",
+			},
+		},
+		{
+			desc:  "block arguments",
+			input: "#+begin_src sh :exports both :results output silent :var a=1 :var b=2 :details t\n#+end_src\n",
+			wantArgs: HeaderArgs{
+				Exports: ExportsBoth,
+				Results: []string{"output", "silent"},
+				Tangle:  "no",
+				Noweb:   "no",
+				Vars:    []string{"a=1", "b=2"},
+				Other:   map[string]string{"details": "t"},
+			},
+		},
+		{
+			desc: "inherited arguments",
+			input: `#+PROPERTY: header-args :exports none :session s1
+#+PROPERTY: header-args:sh :tangle yes
+#+PROPERTY: header-args:go :tangle main.go
+#+NAME: hello
+#+HEADER: :noweb yes
+#+HEADER: :session s2
+#+begin_src sh :exports results
+#+end_src
+`,
+			wantArgs: HeaderArgs{
+				Exports: ExportsResults,
+				Tangle:  "yes",
+				Noweb:   "yes",
+				Session: "s2",
+			},
+		},
+		{
+			desc:  "quoted values",
+			input: "#+begin_src sh :var s=\"a :b\" :var l=(list \"x\" :y) :dir /tmp\n#+end_src\n",
+			wantArgs: HeaderArgs{
+				Exports: ExportsCode,
+				Tangle:  "no",
+				Noweb:   "no",
+				Vars:    []string{`s="a :b"`, `l=(list "x" :y)`},
+				Other:   map[string]string{"dir": "/tmp"},
+			},
+		},
+		{
+			desc: "replaced and appended properties",
+			input: `#+PROPERTY: header-args :session s1 :noweb yes
+#+PROPERTY: header-args :exports none
+#+PROPERTY: header-args+ :var x=1
+#+PROPERTY: header-args:sh :var y=2
+#+PROPERTY: header-args:sh+ :tangle yes
+#+begin_src sh
+#+end_src
+`,
+			wantArgs: HeaderArgs{
+				Exports: ExportsNone,
+				Tangle:  "yes",
+				Noweb:   "no",
+				Vars:    []string{"x=1", "y=2"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var block *SourceBlock
+			for _, node := range parseString(t, tt.input) {
+				if b, ok := node.(SourceBlock); ok {
+					block = &b
+				}
+			}
+			if block == nil {
+				t.Fatalf("source block is not found")
+			}
+			if got := block.HeaderArgs(); !reflect.DeepEqual(got, tt.wantArgs) {
+				t.Errorf("unexpected header args:\ngot=%#v\nwant=%#v", got, tt.wantArgs)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package org
 
 import (
 	"fmt"
+	"html"
 	"io"
 	"regexp"
 	"strings"
@@ -10,11 +11,12 @@ import (
 var _ Node = Headline{}
 
 type Headline struct {
-	Starts   int
-	Title    string
-	Keyword  string   // optional
-	Priority string   // optional
-	Tags     []string // optional
+	Pos      Pos      `json:"pos"`
+	Starts   int      `json:"starts"`
+	Title    string   `json:"title"`
+	Keyword  string   `json:"keyword,omitempty"`  // optional
+	Priority string   `json:"priority,omitempty"` // optional
+	Tags     []string `json:"tags,omitempty"`     // optional
 
 	// TODO: additional fields
 
@@ -28,6 +30,12 @@ type Headline struct {
 // Write writes headline data as HTML elements to the specified writer.
 // NOTE: headline tags are ignored.
 func (h Headline) Write(w io.Writer) error {
+	return h.writeHTML(w, &htmlContext{HTMLWriter: DefaultHTMLWriter()})
+}
+
+// writeHTML writes the headline with the id attribute from the CUSTOM_ID or
+// ID property.
+func (h Headline) writeHTML(w io.Writer, ctx *htmlContext) error {
 	if h.Starts == 0 {
 		return fmt.Errorf("invalid number of starts: %#v", h)
 	}
@@ -35,7 +43,11 @@ func (h Headline) Write(w io.Writer) error {
 		return fmt.Errorf("title is empty: %#v", h)
 	}
 
-	fmt.Fprintf(w, "<h%d class=\"org-headline\">\n", h.Starts) // TODO: add ID
+	if id, ok := ctx.anchors[ctx.index]; ok {
+		fmt.Fprintf(w, "<h%d id=\"%s\" class=\"org-headline\">\n", h.Starts, html.EscapeString(id))
+	} else {
+		fmt.Fprintf(w, "<h%d class=\"org-headline\">\n", h.Starts)
+	}
 	if h.Keyword != "" {
 		fmt.Fprintf(w, "<span class=\"hl-kwd kwd-%s\">%s</span>\n", strings.ToLower(h.Keyword), h.Keyword)
 	}
@@ -70,6 +82,7 @@ func ParseHeadline(p *Parser, i int) (int, Node, error) {
 	}
 
 	hl := Headline{
+		Pos:      p.tokens[i].pos,
 		Starts:   len(p.tokens[i].vals[0]),
 		Keyword:  m[1], // TODO: validation
 		Priority: m[2],
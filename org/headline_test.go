@@ -176,3 +176,20 @@ this is test headline
 		})
 	}
 }
+
+func TestHeadlineAnchors(t *testing.T) {
+	// the nodes are built without positions.
+	nodes := []Node{
+		Headline{Starts: 1, Title: "with id"},
+		PropertyDrawer{Properties: []Property{{Key: "CUSTOM_ID", Value: "top"}}},
+		Headline{Starts: 1, Title: "without id"},
+	}
+	var out bytes.Buffer
+	if err := DefaultHTMLWriter().Write(nodes, &out); err != nil {
+		t.Fatalf("unexpected error: err=%v", err)
+	}
+	want := "<h1 id=\"top\" class=\"org-headline\">\nwith id\n</h1>\n<h1 class=\"org-headline\">\nwithout id\n</h1>\n"
+	if got := out.String(); got != want {
+		t.Errorf("unexpected output:\ngot=%v\nwant=%v", got, want)
+	}
+}
@@ -0,0 +1,274 @@
+package org
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Classes of the highlighted tokens.
+const (
+	HighlightKeyword  = "keyword"
+	HighlightBuiltin  = "builtin"
+	HighlightString   = "string"
+	HighlightNumber   = "number"
+	HighlightComment  = "comment"
+	HighlightVariable = "variable"
+)
+
+// DefaultHighlightStyle is the inline CSS of the highlighted tokens for
+// HTMLWriter.HighlightStyle.
+var DefaultHighlightStyle = map[string]string{
+	HighlightKeyword:  "color:#cf222e",
+	HighlightBuiltin:  "color:#8250df",
+	HighlightString:   "color:#0a3069",
+	HighlightNumber:   "color:#0550ae",
+	HighlightComment:  "color:#6e7781;font-style:italic",
+	HighlightVariable: "color:#953800",
+}
+
+// Highlighter splits the source code into tokens to be highlighted.
+type Highlighter interface {
+	// Highlight returns the tokens of the code. It returns false if the
+	// language is not supported.
+	Highlight(lang, code string) ([]CodeToken, bool)
+}
+
+// CodeToken is a piece of the source code. Class is empty for plain text.
+type CodeToken struct {
+	Class string
+	Text  string
+}
+
+// DefaultHighlighter returns the built-in Highlighter which supports Go,
+// shell script, Python, JavaScript, JSON and Emacs Lisp.
+func DefaultHighlighter() Highlighter {
+	return defaultHighlighter
+}
+
+// codeLexer highlights the code with regexp rules. Identifiers which do not
+// match any rules are classified by the keyword and builtin sets.
+type codeLexer struct {
+	rules    []codeRule
+	ident    *regexp.Regexp
+	keywords map[string]bool
+	builtins map[string]bool
+}
+
+type codeRule struct {
+	class string
+	re    *regexp.Regexp
+}
+
+// lexerHighlighter is a Highlighter which has a codeLexer for each language.
+type lexerHighlighter map[string]*codeLexer
+
+func (h lexerHighlighter) Highlight(lang, code string) ([]CodeToken, bool) {
+	lexer, ok := h[strings.ToLower(lang)]
+	if !ok {
+		return nil, false
+	}
+	return lexer.lex(code), true
+}
+
+func (l *codeLexer) lex(code string) []CodeToken {
+	var tokens []CodeToken
+	add := func(class, text string) {
+		// merge plain text to reduce spans.
+		if n := len(tokens); class == "" && n > 0 && tokens[n-1].Class == "" {
+			tokens[n-1].Text += text
+			return
+		}
+		tokens = append(tokens, CodeToken{Class: class, Text: text})
+	}
+next:
+	for pos := 0; pos < len(code); {
+		rest := code[pos:]
+		for _, rule := range l.rules {
+			if loc := rule.re.FindStringIndex(rest); loc != nil && loc[1] > 0 {
+				add(rule.class, rest[:loc[1]])
+				pos += loc[1]
+				continue next
+			}
+		}
+		if loc := l.ident.FindStringIndex(rest); loc != nil && loc[1] > 0 {
+			word := rest[:loc[1]]
+			switch {
+			case l.keywords[word]:
+				add(HighlightKeyword, word)
+			case l.builtins[word]:
+				add(HighlightBuiltin, word)
+			default:
+				add("", word)
+			}
+			pos += loc[1]
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(rest)
+		add("", rest[:size])
+		pos += size
+	}
+	return tokens
+}
+
+func codeRules(pairs ...string) []codeRule {
+	rules := make([]codeRule, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		rules = append(rules, codeRule{
+			class: pairs[i],
+			re:    regexp.MustCompile(`^(?:` + pairs[i+1] + `)`),
+		})
+	}
+	return rules
+}
+
+func wordSet(words string) map[string]bool {
+	set := make(map[string]bool)
+	for _, w := range strings.Fields(words) {
+		set[w] = true
+	}
+	return set
+}
+
+var (
+	identRegexp     = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*`)
+	lispIdentRegexp = regexp.MustCompile(`^[^\s()\[\]'"` + "`" + `,;]+`)
+	numberRule      = `0[xX][0-9a-fA-F_]+|\d[\d_]*(?:\.\d+)?(?:[eE][+-]?\d+)?`
+
+	goLexer = &codeLexer{
+		rules: codeRules(
+			HighlightComment, `//[^\n]*|(?s:/\*.*?\*/)`,
+			HighlightString, `"(?:[^"\\\n]|\\.)*"|`+"`[^`]*`"+`|'(?:[^'\\\n]|\\.)*'`,
+			HighlightNumber, numberRule,
+		),
+		ident: identRegexp,
+		keywords: wordSet(`break case chan const continue default defer else fallthrough
+			for func go goto if import interface map package range return select struct
+			switch type var true false nil iota`),
+		builtins: wordSet(`append cap close complex copy delete imag len make new panic
+			print println real recover bool byte complex64 complex128 error float32
+			float64 int int8 int16 int32 int64 rune string uint uint8 uint16 uint32
+			uint64 uintptr`),
+	}
+	shellLexer = &codeLexer{
+		rules: codeRules(
+			HighlightComment, `#[^\n]*`,
+			HighlightString, `"(?:[^"\\]|\\.)*"|'[^']*'`,
+			HighlightVariable, `\$(?:\{[^}\n]*\}|[A-Za-z_][A-Za-z0-9_]*|[#?@*$!0-9-])`,
+			HighlightNumber, `\d+\b`,
+		),
+		ident: identRegexp,
+		keywords: wordSet(`if then else elif fi for while until do done case esac in
+			function return select local export declare readonly break continue`),
+		builtins: wordSet(`echo cd printf read set unset source exit test eval exec
+			shift trap alias pwd`),
+	}
+	pythonLexer = &codeLexer{
+		rules: codeRules(
+			HighlightComment, `#[^\n]*`,
+			HighlightString, `(?s:[rRbBuUfF]{0,2}(?:""".*?"""|'''.*?'''))|[rRbBuUfF]{0,2}(?:"(?:[^"\\\n]|\\.)*"|'(?:[^'\\\n]|\\.)*')`,
+			HighlightNumber, numberRule,
+		),
+		ident: identRegexp,
+		keywords: wordSet(`and as assert async await break class continue def del elif
+			else except finally for from global if import in is lambda nonlocal not or
+			pass raise return try while with yield True False None`),
+		builtins: wordSet(`abs all any bool dict enumerate filter float format int
+			isinstance len list map max min open print range repr set sorted str sum
+			super tuple type zip self`),
+	}
+	javaScriptLexer = &codeLexer{
+		rules: codeRules(
+			HighlightComment, `//[^\n]*|(?s:/\*.*?\*/)`,
+			HighlightString, `"(?:[^"\\\n]|\\.)*"|'(?:[^'\\\n]|\\.)*'|`+"`(?:[^`\\\\]|\\\\.)*`",
+			HighlightNumber, numberRule,
+		),
+		ident: regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*`),
+		keywords: wordSet(`async await break case catch class const continue debugger
+			default delete do else export extends finally for function if import in
+			instanceof let new of return static super switch this throw try typeof var
+			void while yield true false null undefined interface type enum implements`),
+		builtins: wordSet(`Array Boolean Date Error JSON Map Math Number Object Promise
+			RegExp Set String Symbol console document window`),
+	}
+	jsonLexer = &codeLexer{
+		rules: codeRules(
+			HighlightString, `"(?:[^"\\\n]|\\.)*"`,
+			HighlightNumber, `-?\d+(?:\.\d+)?(?:[eE][+-]?\d+)?`,
+		),
+		ident:    identRegexp,
+		keywords: wordSet(`true false null`),
+	}
+	lispLexer = &codeLexer{
+		rules: codeRules(
+			HighlightComment, `;[^\n]*`,
+			HighlightString, `"(?:[^"\\]|\\.)*"`,
+			HighlightNumber, `-?\d+(?:\.\d+)?`,
+			HighlightVariable, `:[^\s()\[\]'"]+`,
+		),
+		ident: lispIdentRegexp,
+		keywords: wordSet(`defun defmacro defvar defcustom defconst lambda let let*
+			if when unless cond progn while dolist dotimes and or not setq setf
+			interactive require provide nil t`),
+		builtins: wordSet(`car cdr cons list append mapcar message format concat
+			funcall apply length`),
+	}
+
+	defaultHighlighter = lexerHighlighter{
+		"go":         goLexer,
+		"golang":     goLexer,
+		"sh":         shellLexer,
+		"bash":       shellLexer,
+		"shell":      shellLexer,
+		"zsh":        shellLexer,
+		"python":     pythonLexer,
+		"py":         pythonLexer,
+		"javascript": javaScriptLexer,
+		"js":         javaScriptLexer,
+		"typescript": javaScriptLexer,
+		"ts":         javaScriptLexer,
+		"json":       jsonLexer,
+		"emacs-lisp": lispLexer,
+		"elisp":      lispLexer,
+		"lisp":       lispLexer,
+	}
+)
+
+var codeEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// writeCodeTokens writes tokens as HTML spans. The tokens are written with the
+// inline CSS if style is not nil.
+func writeCodeTokens(w io.Writer, tokens []CodeToken, style map[string]string) {
+	for _, t := range tokens {
+		text := codeEscaper.Replace(t.Text)
+		switch css, ok := style[t.Class]; {
+		case t.Class == "":
+			fmt.Fprint(w, text)
+		case style == nil:
+			fmt.Fprintf(w, "<span class=\"hl-%s\">%s</span>", t.Class, text)
+		case ok:
+			fmt.Fprintf(w, "<span style=\"%s\">%s</span>", css, text)
+		default:
+			fmt.Fprint(w, text)
+		}
+	}
+}
+
+// splitCodeLines splits tokens into lines. Tokens across multiple lines such
+// as block comments are split into each line.
+func splitCodeLines(tokens []CodeToken) [][]CodeToken {
+	lines := [][]CodeToken{nil}
+	for _, t := range tokens {
+		for i, text := range strings.Split(t.Text, "\n") {
+			if i > 0 {
+				lines = append(lines, nil)
+			}
+			if text != "" {
+				lines[len(lines)-1] = append(lines[len(lines)-1], CodeToken{Class: t.Class, Text: text})
+			}
+		}
+	}
+	return lines
+}
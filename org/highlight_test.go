@@ -0,0 +1,113 @@
+package org_test
+
+import (
+	"reflect"
+	"testing"
+
+	. "github.com/Ladicle/org2html/org"
+)
+
+func TestDefaultHighlighter(t *testing.T) {
+	var tests = []struct {
+		desc       string
+		lang       string
+		code       string
+		wantTokens []CodeToken
+		wantFlag   bool
+	}{
+		{
+			desc:     "unknown language",
+			lang:     "cobol",
+			code:     "DISPLAY 'hello'.",
+			wantFlag: false,
+		},
+		{
+			desc:     "go",
+			lang:     "go",
+			code:     "func f() int { return len(\"a\") }",
+			wantFlag: true,
+			wantTokens: []CodeToken{
+				{Class: HighlightKeyword, Text: "func"},
+				{Text: " f() "},
+				{Class: HighlightBuiltin, Text: "int"},
+				{Text: " { "},
+				{Class: HighlightKeyword, Text: "return"},
+				{Text: " "},
+				{Class: HighlightBuiltin, Text: "len"},
+				{Text: "("},
+				{Class: HighlightString, Text: "\"a\""},
+				{Text: ") }"},
+			},
+		},
+		{
+			desc:     "identifiers containing keywords",
+			lang:     "Go",
+			code:     "iffy x1",
+			wantFlag: true,
+			wantTokens: []CodeToken{
+				{Text: "iffy x1"},
+			},
+		},
+		{
+			desc:     "shell",
+			lang:     "bash",
+			code:     "#!/bin/bash\nif [ -n \"$1\" ]; then echo ${HOME}; fi",
+			wantFlag: true,
+			wantTokens: []CodeToken{
+				{Class: HighlightComment, Text: "#!/bin/bash"},
+				{Text: "\n"},
+				{Class: HighlightKeyword, Text: "if"},
+				{Text: " [ -n "},
+				{Class: HighlightString, Text: "\"$1\""},
+				{Text: " ]; "},
+				{Class: HighlightKeyword, Text: "then"},
+				{Text: " "},
+				{Class: HighlightBuiltin, Text: "echo"},
+				{Text: " "},
+				{Class: HighlightVariable, Text: "${HOME}"},
+				{Text: "; "},
+				{Class: HighlightKeyword, Text: "fi"},
+			},
+		},
+		{
+			desc:     "python",
+			lang:     "python",
+			code:     "def f():\n    \"\"\"doc\n    \"\"\"\n    return None  # none",
+			wantFlag: true,
+			wantTokens: []CodeToken{
+				{Class: HighlightKeyword, Text: "def"},
+				{Text: " f():\n    "},
+				{Class: HighlightString, Text: "\"\"\"doc\n    \"\"\""},
+				{Text: "\n    "},
+				{Class: HighlightKeyword, Text: "return"},
+				{Text: " "},
+				{Class: HighlightKeyword, Text: "None"},
+				{Text: "  "},
+				{Class: HighlightComment, Text: "# none"},
+			},
+		},
+		{
+			desc:     "emacs lisp",
+			lang:     "emacs-lisp",
+			code:     "(setq-default x 1) ; one",
+			wantFlag: true,
+			wantTokens: []CodeToken{
+				{Text: "(setq-default x "},
+				{Class: HighlightNumber, Text: "1"},
+				{Text: ") "},
+				{Class: HighlightComment, Text: "; one"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			tokens, flag := DefaultHighlighter().Highlight(tt.lang, tt.code)
+			if flag != tt.wantFlag {
+				t.Errorf("unexpected flag: got=%v, want=%v", flag, tt.wantFlag)
+			}
+			if !reflect.DeepEqual(tokens, tt.wantTokens) {
+				t.Errorf("unexpected tokens:\ngot=%#v\nwant=%#v", tokens, tt.wantTokens)
+			}
+		})
+	}
+}
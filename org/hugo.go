@@ -0,0 +1,381 @@
+package org
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// FrontMatterFormat is a format of the Hugo front matter.
+type FrontMatterFormat string
+
+const (
+	TOMLFrontMatter FrontMatterFormat = "toml"
+	YAMLFrontMatter FrontMatterFormat = "yaml"
+)
+
+const (
+	hugoKeyPrefix       = "HUGO_"
+	exportKeyPrefix     = "EXPORT_"
+	exportFileNameKey   = "EXPORT_FILE_NAME"
+	hugoSectionKey      = "HUGO_SECTION"
+	hugoBaseDirKey      = "HUGO_BASE_DIR"
+	hugoCustomFrontKey  = "HUGO_CUSTOM_FRONT_MATTER"
+	defaultHugoSection  = "posts"
+	hugoContentDir      = "content"
+	hugoMarkdownFileExt = ".md"
+)
+
+var (
+	// hugoListKeys are front matter keys whose value is a space separated list.
+	hugoListKeys = map[string]bool{
+		"tags": true, "categories": true, "aliases": true, "keywords": true, "series": true,
+	}
+	hugoBoolKeys = map[string]bool{"draft": true, "headless": true}
+	hugoIntKeys  = map[string]bool{"weight": true}
+)
+
+// FrontMatter is an ordered set of the Hugo front matter fields.
+type FrontMatter []FrontMatterField
+
+// FrontMatterField is a field of the front matter. The value type is string,
+// bool, int, []string or Timestamp.
+type FrontMatterField struct {
+	Key   string
+	Value interface{}
+}
+
+// Get returns the value of the field.
+func (fm FrontMatter) Get(key string) (interface{}, bool) {
+	for _, f := range fm {
+		if f.Key == key {
+			return f.Value, true
+		}
+	}
+	return nil, false
+}
+
+// set overwrites the field value if the field exists, otherwise appends it.
+func (fm *FrontMatter) set(key string, val interface{}) {
+	for i := range *fm {
+		if (*fm)[i].Key == key {
+			(*fm)[i].Value = val
+			return
+		}
+	}
+	*fm = append(*fm, FrontMatterField{Key: key, Value: val})
+}
+
+// setOrg converts the org keyword or property value into the front matter
+// value and sets it.
+func (fm *FrontMatter) setOrg(key, val string) error {
+	switch {
+	case key == "date" || key == "lastmod" || key == "publishdate" || key == "expirydate":
+		// the date may be written without brackets or in other formats.
+		if t, err := ParseTimestampLiteral(val); err == nil {
+			fm.set(key, t)
+		} else if t, err := ParseTimestampLiteral("<" + val + ">"); err == nil {
+			fm.set(key, t)
+		} else {
+			fm.set(key, val)
+		}
+	case hugoListKeys[key]:
+		fm.set(key, strings.Fields(val))
+	case hugoBoolKeys[key]:
+		switch strings.ToLower(val) {
+		case "t", "true", "yes":
+			fm.set(key, true)
+		case "nil", "false", "no":
+			fm.set(key, false)
+		default:
+			return fmt.Errorf("invalid %v value: %q", key, val)
+		}
+	case hugoIntKeys[key]:
+		n, err := strconv.Atoi(val)
+		if err != nil {
+			return fmt.Errorf("invalid %v value: %q", key, val)
+		}
+		fm.set(key, n)
+	default:
+		fm.set(key, val)
+	}
+	return nil
+}
+
+// setCustom sets the custom front matter such as `:key1 value1 :key2 value2`.
+func (fm *FrontMatter) setCustom(val string) error {
+	for _, kv := range strings.Split(strings.TrimSpace(val), ":") {
+		if kv = strings.TrimSpace(kv); kv == "" {
+			continue
+		}
+		parts := strings.SplitN(kv, " ", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("custom front matter does not have value: %q", kv)
+		}
+		if err := fm.setOrg(strings.ToLower(parts[0]), strings.TrimSpace(parts[1])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Write writes the front matter with the delimiters in the specified format.
+func (fm FrontMatter) Write(w io.Writer, format FrontMatterFormat) error {
+	var delim, sep string
+	switch format {
+	case TOMLFrontMatter:
+		delim, sep = "+++", " = "
+	case YAMLFrontMatter:
+		delim, sep = "---", ": "
+	default:
+		return fmt.Errorf("unknown front matter format: %q", format)
+	}
+	fmt.Fprintln(w, delim)
+	for _, f := range fm {
+		val, err := formatFrontMatterValue(f.Value)
+		if err != nil {
+			return fmt.Errorf("front matter %q: %w", f.Key, err)
+		}
+		fmt.Fprintf(w, "%s%s%s\n", f.Key, sep, val)
+	}
+	fmt.Fprintln(w, delim)
+	return nil
+}
+
+// formatFrontMatterValue formats the value in the syntax which both of TOML
+// and YAML flow style accept.
+func formatFrontMatterValue(val interface{}) (string, error) {
+	switch v := val.(type) {
+	case string:
+		return quoteFrontMatterString(v)
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case Timestamp:
+		if v.IsDate {
+			return v.Time.Format("2006-01-02"), nil
+		}
+		return v.Time.Format("2006-01-02T15:04:05"), nil
+	case []string:
+		items := make([]string, len(v))
+		for i := range v {
+			s, err := quoteFrontMatterString(v[i])
+			if err != nil {
+				return "", err
+			}
+			items[i] = s
+		}
+		return "[" + strings.Join(items, ", ") + "]", nil
+	}
+	return "", fmt.Errorf("unsupported value type: %T", val)
+}
+
+// quoteFrontMatterString quotes the string with the JSON escape sequences which
+// are valid in both TOML basic strings and YAML double-quoted strings.
+func quoteFrontMatterString(s string) (string, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(s); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// HugoPost is a content file of Hugo exported from the org document.
+type HugoPost struct {
+	// Path is the slash-separated file path relative to the Hugo base directory.
+	Path        string
+	FrontMatter FrontMatter
+	// Nodes is the body whose macros are expanded with the whole document.
+	Nodes []Node
+}
+
+// Write writes the post with the front matter and the Markdown body.
+func (p HugoPost) Write(w io.Writer, format FrontMatterFormat) error {
+	if err := p.FrontMatter.Write(w, format); err != nil {
+		return err
+	}
+	if len(p.Nodes) == 0 {
+		return nil
+	}
+	fmt.Fprintln(w)
+	return writeMarkdownNodes(p.Nodes, w)
+}
+
+// HugoPosts converts the org document to Hugo posts in the same way as ox-hugo.
+// If some headlines have the EXPORT_FILE_NAME property, each subtree is
+// exported as a post. Otherwise the whole document is exported as a post and
+// the file name is taken from the #+EXPORT_FILE_NAME keyword or orgFile.
+// Macros are expanded before splitting the document.
+func HugoPosts(nodes []Node, orgFile string) ([]HugoPost, error) {
+	nodes, err := ExpandMacros(nodes, MacroEnv{InputFile: orgFile})
+	if err != nil {
+		return nil, err
+	}
+	var (
+		baseDir = "."
+		section = defaultHugoSection
+		file    = FrontMatter{}
+		name    = strings.TrimSuffix(path.Base(orgFile), path.Ext(orgFile))
+	)
+	for i := range nodes {
+		kwd, ok := nodes[i].(Keyword)
+		if !ok {
+			continue
+		}
+		switch key := kwd.Key; {
+		case key == hugoBaseDirKey:
+			baseDir = kwd.Value
+		case key == hugoSectionKey:
+			section = kwd.Value
+		case key == exportFileNameKey:
+			name = kwd.Value
+		case key == hugoCustomFrontKey:
+			if err := file.setCustom(kwd.Value); err != nil {
+				return nil, err
+			}
+		case strings.HasSuffix(key, "[]"):
+			// Hugo style array keyword such as #+tags[]: a b
+			file.set(strings.ToLower(strings.TrimSuffix(key, "[]")), strings.Fields(kwd.Value))
+		case strings.HasPrefix(key, hugoKeyPrefix):
+			if err := file.setOrg(strings.ToLower(strings.TrimPrefix(key, hugoKeyPrefix)), kwd.Value); err != nil {
+				return nil, err
+			}
+		case key == "TITLE" || key == "DATE" || key == "DESCRIPTION" || key == "AUTHOR":
+			if err := file.setOrg(strings.ToLower(key), kwd.Value); err != nil {
+				return nil, err
+			}
+		case key == "FILETAGS":
+			file.set("tags", strings.FieldsFunc(kwd.Value, func(r rune) bool { return r == ':' }))
+		}
+	}
+
+	var posts []HugoPost
+	for i := range nodes {
+		hl, ok := nodes[i].(Headline)
+		if !ok {
+			continue
+		}
+		props, _ := HeadlineProperties(nodes, i)
+		if _, ok := props.Get(exportFileNameKey); !ok {
+			continue
+		}
+		post, err := hugoSubtreePost(nodes, i, hl, props, baseDir, section)
+		if err != nil {
+			return nil, err
+		}
+		posts = append(posts, post)
+	}
+	if len(posts) > 0 {
+		return posts, nil
+	}
+
+	if name == "" {
+		return nil, fmt.Errorf("export file name is empty")
+	}
+	return []HugoPost{{
+		Path:        path.Join(baseDir, hugoContentDir, section, name+hugoMarkdownFileExt),
+		FrontMatter: file,
+		Nodes:       nodes,
+	}}, nil
+}
+
+// hugoSubtreePost converts the subtree of the headline nodes[i] to the post.
+func hugoSubtreePost(nodes []Node, i int, hl Headline, props PropertyDrawer, baseDir, section string) (HugoPost, error) {
+	fm := FrontMatter{{Key: "title", Value: hl.Title}}
+
+	var tags, categories []string
+	for _, tag := range hl.Tags {
+		if strings.HasPrefix(tag, "@") {
+			categories = append(categories, strings.TrimPrefix(tag, "@"))
+		} else {
+			tags = append(tags, tag)
+		}
+	}
+
+	// the post body starts after the agenda and property drawer.
+	start := i + 1
+	for ; start < len(nodes); start++ {
+		if agenda, ok := nodes[start].(Agenda); ok {
+			if closed, ok := agenda.Logs[AgendaClosed]; ok {
+				fm.set("date", closed)
+			}
+			continue
+		}
+		if _, ok := nodes[start].(PropertyDrawer); ok {
+			continue
+		}
+		break
+	}
+	if len(tags) > 0 {
+		fm.set("tags", tags)
+	}
+	if len(categories) > 0 {
+		fm.set("categories", categories)
+	}
+	if hl.Keyword != "" && hl.Keyword != "DONE" {
+		fm.set("draft", true)
+	}
+
+	var (
+		name string
+		seen = make(map[string]bool)
+	)
+	for _, prop := range props.Properties {
+		// the values of `:KEY+:` are merged into KEY by Get.
+		key := strings.ToUpper(strings.TrimSuffix(prop.Key, "+"))
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		value, _ := props.Get(key)
+		switch {
+		case key == exportFileNameKey:
+			name = value
+		case key == exportKeyPrefix+hugoSectionKey:
+			section = value
+		case key == exportKeyPrefix+hugoBaseDirKey:
+			baseDir = value
+		case key == exportKeyPrefix+hugoCustomFrontKey:
+			if err := fm.setCustom(value); err != nil {
+				return HugoPost{}, err
+			}
+		case strings.HasPrefix(key, exportKeyPrefix+hugoKeyPrefix):
+			if err := fm.setOrg(strings.ToLower(strings.TrimPrefix(key, exportKeyPrefix+hugoKeyPrefix)), value); err != nil {
+				return HugoPost{}, err
+			}
+		case strings.HasPrefix(key, exportKeyPrefix):
+			if err := fm.setOrg(strings.ToLower(strings.TrimPrefix(key, exportKeyPrefix)), value); err != nil {
+				return HugoPost{}, err
+			}
+		}
+	}
+	if name == "" {
+		return HugoPost{}, fmt.Errorf("export file name of %q is empty", hl.Title)
+	}
+
+	// sub headlines are shifted to start from level 2 because the title is
+	// the level 1 heading.
+	var body []Node
+	for _, node := range nodes[start:] {
+		if sub, ok := node.(Headline); ok {
+			if sub.Starts <= hl.Starts {
+				break
+			}
+			sub.Starts = sub.Starts - hl.Starts + 1
+			node = sub
+		}
+		body = append(body, node)
+	}
+	return HugoPost{
+		Path:        path.Join(baseDir, hugoContentDir, section, name+hugoMarkdownFileExt),
+		FrontMatter: fm,
+		Nodes:       body,
+	}, nil
+}
@@ -0,0 +1,139 @@
+package org_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	. "github.com/Ladicle/org2html/org"
+)
+
+func parseString(t *testing.T, input string) []Node {
+	t.Helper()
+	tokens, err := DefaultTokenizer().Tokenize(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("fail to tokenize: err=%v", err)
+	}
+	nodes, err := DefaultParser(tokens).Parse()
+	if err != nil {
+		t.Fatalf("fail to parse: err=%v", err)
+	}
+	return nodes
+}
+
+func TestHugoPosts(t *testing.T) {
+	type post struct {
+		path string
+		out  string
+	}
+	var tests = []struct {
+		desc      string
+		input     string
+		format    FrontMatterFormat
+		wantPosts []post
+	}{
+		{
+			desc: "file mode",
+			input: `#+title: 2022/02/03 (Thu)
+#+date: [2022-02-03 Thu 11:19]
+#+tags[]: org-mode html
+#+hugo_section: notes
+#+hugo_draft: t
+#+hugo_custom_front_matter: :weight 10 :slug hello
+
+hello "world"
+`,
+			format: TOMLFrontMatter,
+			wantPosts: []post{{
+				path: "content/notes/index.md",
+				out: `+++
+title = "2022/02/03 (Thu)"
+date = 2022-02-03T11:19:00
+tags = ["org-mode", "html"]
+draft = true
+weight = 10
+slug = "hello"
++++
+
+hello "world"
+`,
+			}},
+		},
+		{
+			desc: "subtree mode",
+			input: `#+hugo_base_dir: ../site
+#+author: me
+
+* Blog
+** DONE First post                                              :go:@tech:
+   CLOSED: [2022-01-31 Mon 11:12]
+   :PROPERTIES:
+   :EXPORT_FILE_NAME: first-post
+   :EXPORT_HUGO_WEIGHT: 2
+   :END:
+first body
+*** Sub heading
+** TODO Second post
+   :PROPERTIES:
+   :EXPORT_FILE_NAME: second
+   :EXPORT_HUGO_SECTION: drafts
+   :EXPORT_DATE: 2022-02-01
+   :EXPORT_DESCRIPTION: second
+   :EXPORT_DESCRIPTION+: post
+   :END:
+* Other
+`,
+			format: YAMLFrontMatter,
+			wantPosts: []post{
+				{
+					path: "../site/content/posts/first-post.md",
+					out: `---
+title: "First post"
+date: 2022-01-31T11:12:00
+tags: ["go"]
+categories: ["tech"]
+weight: 2
+---
+
+first body
+
+## Sub heading
+`,
+				},
+				{
+					path: "../site/content/drafts/second.md",
+					out: `---
+title: "Second post"
+draft: true
+date: 2022-02-01
+description: "second post"
+---
+`,
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			posts, err := HugoPosts(parseString(t, tt.input), "notes/index.org")
+			if err != nil {
+				t.Fatalf("unexpected error: err=%v", err)
+			}
+			if got, want := len(posts), len(tt.wantPosts); got != want {
+				t.Fatalf("unexpected number of posts: got=%v, want=%v", got, want)
+			}
+			for i, want := range tt.wantPosts {
+				if posts[i].Path != want.path {
+					t.Errorf("unexpected path: got=%v, want=%v", posts[i].Path, want.path)
+				}
+				var out bytes.Buffer
+				if err := posts[i].Write(&out, tt.format); err != nil {
+					t.Fatalf("unexpected error: err=%v", err)
+				}
+				if got := out.String(); got != want.out {
+					t.Errorf("unexpected output:\ngot=%v\nwant=%v", got, want.out)
+				}
+			}
+		})
+	}
+}
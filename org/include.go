@@ -0,0 +1,382 @@
+package org
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Loader reads Org files from the file system and resolves #+INCLUDE and
+// #+SETUPFILE keywords before tokenizing them.
+type Loader struct {
+	// FS is the file system which contains the documents. The paths of
+	// #+INCLUDE are relative to the including file, and absolute paths are
+	// relative to the root of FS.
+	FS fs.FS
+	// HomeFS is the file system of the home directory for the paths which
+	// start with `~/`. They cannot be resolved if it is nil.
+	HomeFS fs.FS
+	// OnRead is called with the path of each file read such as the included
+	// files if it is set. The paths in HomeFS start with `~/`.
+	OnRead func(name string)
+}
+
+// Load reads the file and parses it with the included files. The positions of
+// the nodes are the line numbers in the expanded text, so they are shifted
+// from the file after #+INCLUDE and #+SETUPFILE keywords. Use LoadLines to
+// find the lines of the file. Errors of the keywords refer to the lines of
+// the files which contain them.
+func (l Loader) Load(name string) ([]Node, error) {
+	nodes, _, err := l.LoadLines(name)
+	return nodes, err
+}
+
+// LoadLines is the same as Load, but also returns the line numbers of the
+// file for the lines of the expanded text. lines[n-1] is the line of the file
+// for the position n of the nodes, and the included lines have the line of
+// the #+INCLUDE or #+SETUPFILE keyword.
+func (l Loader) LoadLines(name string) (nodes []Node, lines []int, err error) {
+	text, err := l.readLines(path.Clean(name), nil, &lines)
+	if err != nil {
+		return nil, nil, err
+	}
+	tokens, err := DefaultTokenizer().Tokenize(strings.NewReader(joinFileLines(text)))
+	if err != nil {
+		return nil, nil, err
+	}
+	nodes, err = DefaultParser(tokens).Parse()
+	return nodes, lines, err
+}
+
+// ReadFile returns the contents of the file whose #+INCLUDE keywords are
+// replaced with the included contents recursively, and #+SETUPFILE keywords
+// are replaced with the settings of the setup files.
+func (l Loader) ReadFile(name string) ([]byte, error) {
+	lines, err := l.readLines(path.Clean(name), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(joinFileLines(lines)), nil
+}
+
+func joinFileLines(lines []string) string {
+	return strings.Join(lines, "\n") + "\n"
+}
+
+var includeRegexp = regexp.MustCompile(`(?i)^\s*#\+INCLUDE:\s*(.*?)\s*$`)
+
+// readLines returns the lines of the file with the included files. stack is
+// the names of the including files to detect cycles. The line numbers of the
+// file for the returned lines are appended to origins if it is not nil.
+func (l Loader) readLines(name string, stack []string, origins *[]int) ([]string, error) {
+	for _, s := range stack {
+		if s == name {
+			return nil, fmt.Errorf("include cycle: %s -> %s", strings.Join(stack, " -> "), name)
+		}
+	}
+	src, err := l.readFile(name)
+	if err != nil {
+		return nil, err
+	}
+	stack = append(stack, name)
+
+	var (
+		lines []string
+		// verbatim is the name of the block such as SRC which contains the
+		// line. Keywords in the blocks except greater blocks are not expanded.
+		verbatim string
+	)
+	srcLines := splitLines(string(src))
+	for i, line := range srcLines {
+		// the lines appended for the previous line come from the line i.
+		addOrigins(origins, len(lines), i)
+		if verbatim != "" {
+			if m := endBlockRegexp.FindStringSubmatch(line); m != nil && strings.EqualFold(m[1], verbatim) {
+				verbatim = ""
+			}
+			lines = append(lines, line)
+			continue
+		}
+		if m := beginBlockRegexp.FindStringSubmatch(line); m != nil && !isGreaterBlock(strings.ToUpper(m[1])) {
+			verbatim = m[1]
+			lines = append(lines, line)
+			continue
+		}
+		if m := setupfileRegexp.FindStringSubmatch(line); m != nil {
+			settings, err := l.setupLines(resolvePath(name, unquote(m[1])), stack)
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", name, i+1, err)
+			}
+			lines = append(lines, settings...)
+			continue
+		}
+		m := includeRegexp.FindStringSubmatch(line)
+		if m == nil {
+			lines = append(lines, line)
+			continue
+		}
+		inc, err := parseInclude(m[1])
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", name, i+1, err)
+		}
+		included, err := l.include(resolvePath(name, inc.file), inc, stack)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", name, i+1, err)
+		}
+		lines = append(lines, included...)
+	}
+	addOrigins(origins, len(lines), len(srcLines))
+	return lines, nil
+}
+
+// addOrigins appends the line to origins until it has n lines.
+func addOrigins(origins *[]int, n, line int) {
+	for origins != nil && len(*origins) < n {
+		*origins = append(*origins, line)
+	}
+}
+
+// include returns the lines of the #+INCLUDE keyword.
+func (l Loader) include(name string, inc include, stack []string) ([]string, error) {
+	var (
+		lines []string
+		err   error
+	)
+	if inc.block == "" {
+		lines, err = l.readLines(name, stack, nil)
+	} else {
+		var src []byte
+		src, err = l.readFile(name)
+		lines = splitLines(string(src))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if inc.search != "" {
+		if lines, err = searchSubtree(lines, inc.search); err != nil {
+			return nil, err
+		}
+	}
+	if inc.lines != "" {
+		if lines, err = selectLines(lines, inc.lines); err != nil {
+			return nil, err
+		}
+	}
+	if inc.minlevel > 0 {
+		lines = shiftHeadlines(lines, inc.minlevel)
+	}
+	if inc.block == "" {
+		return lines, nil
+	}
+
+	begin := "#+BEGIN_" + strings.ToUpper(inc.block)
+	if inc.lang != "" {
+		begin += " " + inc.lang
+	}
+	content := escapeBlockContent(strings.Join(lines, "\n"))
+	return append([]string{begin}, append(splitLines(content), "#+END_"+strings.ToUpper(inc.block))...), nil
+}
+
+// include is the parameters of the #+INCLUDE keyword such as
+// `"file.org::*Heading" :lines "5-10" :minlevel 2` or `"main.go" src go`.
+type include struct {
+	file     string
+	search   string
+	block    string
+	lang     string
+	lines    string
+	minlevel int
+}
+
+func parseInclude(value string) (include, error) {
+	fields := splitQuotedFields(value)
+	if len(fields) == 0 || fields[0] == "" {
+		return include{}, fmt.Errorf("include file is empty: %q", value)
+	}
+	var inc include
+	inc.file = fields[0]
+	if i := strings.Index(inc.file, "::"); i >= 0 {
+		inc.file, inc.search = inc.file[:i], inc.file[i+2:]
+	}
+	for i := 1; i < len(fields); i++ {
+		switch f := fields[i]; {
+		case f == ":lines" && i+1 < len(fields):
+			i++
+			inc.lines = fields[i]
+		case f == ":minlevel" && i+1 < len(fields):
+			i++
+			n, err := strconv.Atoi(fields[i])
+			if err != nil || n < 1 {
+				return include{}, fmt.Errorf("invalid minlevel: %q", fields[i])
+			}
+			inc.minlevel = n
+		case strings.HasPrefix(f, ":"):
+			// unsupported parameters have a value.
+			i++
+		case inc.block == "":
+			inc.block = strings.ToLower(f)
+			if inc.block != "src" && inc.block != "example" && inc.block != "export" {
+				return include{}, fmt.Errorf("unsupported include block: %q", f)
+			}
+		case inc.lang == "":
+			inc.lang = f
+		}
+	}
+	return inc, nil
+}
+
+// splitQuotedFields splits s by spaces. Fields can be quoted by double quotes.
+func splitQuotedFields(s string) []string {
+	var fields []string
+	for s = strings.TrimSpace(s); s != ""; s = strings.TrimSpace(s) {
+		if s[0] == '"' {
+			if end := strings.Index(s[1:], `"`); end >= 0 {
+				fields = append(fields, s[1:end+1])
+				s = s[end+2:]
+				continue
+			}
+		}
+		end := strings.IndexAny(s, " \t")
+		if end < 0 {
+			end = len(s)
+		}
+		fields = append(fields, s[:end])
+		s = s[end:]
+	}
+	return fields
+}
+
+// readFile reads the file from FS, or HomeFS if the name starts with `~/`.
+func (l Loader) readFile(name string) ([]byte, error) {
+	if l.OnRead != nil {
+		l.OnRead(name)
+	}
+	if strings.HasPrefix(name, homePrefix) {
+		if l.HomeFS == nil {
+			return nil, fmt.Errorf("home directory is not available: %s", name)
+		}
+		return fs.ReadFile(l.HomeFS, strings.TrimPrefix(name, homePrefix))
+	}
+	return fs.ReadFile(l.FS, name)
+}
+
+const homePrefix = "~/"
+
+// resolvePath returns the path of the file referred from the file base.
+// Paths in the home directory keep the `~/` prefix.
+func resolvePath(base, file string) string {
+	if strings.HasPrefix(file, homePrefix) {
+		return homePrefix + path.Clean(strings.TrimPrefix(file, homePrefix))
+	}
+	if strings.HasPrefix(file, "/") {
+		return path.Clean(strings.TrimPrefix(file, "/"))
+	}
+	return path.Join(path.Dir(base), file)
+}
+
+func splitLines(s string) []string {
+	return strings.Split(strings.TrimSuffix(strings.ReplaceAll(s, "\r\n", "\n"), "\n"), "\n")
+}
+
+// searchSubtree returns the subtree of the headline such as `*Heading` or
+// `#custom-id`.
+func searchSubtree(lines []string, search string) ([]string, error) {
+	start, level := -1, 0
+	for i, line := range lines {
+		m := headlineRegexp.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		if start >= 0 {
+			if len(m[1]) <= level {
+				return lines[start:i], nil
+			}
+			continue
+		}
+		var ok bool
+		switch {
+		case strings.HasPrefix(search, "*"):
+			ok = hlDataRegexp.FindStringSubmatch(m[2])[3] == strings.TrimPrefix(search, "*")
+		case strings.HasPrefix(search, "#"):
+			ok = headlineCustomID(lines[i+1:]) == strings.TrimPrefix(search, "#")
+		default:
+			return nil, fmt.Errorf("unsupported include search: %q", search)
+		}
+		if ok {
+			start, level = i, len(m[1])
+		}
+	}
+	if start < 0 {
+		return nil, fmt.Errorf("headline is not found: %q", search)
+	}
+	return lines[start:], nil
+}
+
+var customIDRegexp = regexp.MustCompile(`(?i)^\s*:CUSTOM_ID:\s*(\S+)`)
+
+// headlineCustomID returns the CUSTOM_ID property of the property drawer at
+// the beginning of lines.
+func headlineCustomID(lines []string) string {
+	for _, line := range lines {
+		if headlineRegexp.MatchString(line) || strings.EqualFold(strings.TrimSpace(line), ":END:") {
+			break
+		}
+		if m := customIDRegexp.FindStringSubmatch(line); m != nil {
+			return m[1]
+		}
+	}
+	return ""
+}
+
+var linesRegexp = regexp.MustCompile(`^(\d*)-(\d*)$`)
+
+// selectLines returns the lines of the range such as `5-10`, where the first
+// line is 1 and the end is excluded. Either of them can be omitted.
+func selectLines(lines []string, r string) ([]string, error) {
+	m := linesRegexp.FindStringSubmatch(r)
+	if m == nil {
+		return nil, fmt.Errorf("invalid lines: %q", r)
+	}
+	start, end := 0, len(lines)
+	if m[1] != "" {
+		n, _ := strconv.Atoi(m[1])
+		if n > 0 {
+			start = n - 1
+		}
+	}
+	if m[2] != "" {
+		n, _ := strconv.Atoi(m[2])
+		if n-1 < end {
+			end = n - 1
+		}
+	}
+	if start > end {
+		start = end
+	}
+	return lines[start:end], nil
+}
+
+// shiftHeadlines shifts the levels of headlines so that the top level is
+// minlevel.
+func shiftHeadlines(lines []string, minlevel int) []string {
+	top := 0
+	for _, line := range lines {
+		if m := headlineRegexp.FindStringSubmatch(line); m != nil && (top == 0 || len(m[1]) < top) {
+			top = len(m[1])
+		}
+	}
+	if top == 0 || top == minlevel {
+		return lines
+	}
+	ret := make([]string, len(lines))
+	for i, line := range lines {
+		if m := headlineRegexp.FindStringSubmatch(line); m != nil {
+			line = strings.Repeat("*", len(m[1])-top+minlevel) + line[len(m[1]):]
+		}
+		ret[i] = line
+	}
+	return ret
+}
@@ -0,0 +1,117 @@
+package org_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	. "github.com/Ladicle/org2html/org"
+)
+
+func TestLoaderReadFile(t *testing.T) {
+	fsys := fstest.MapFS{
+		"lines.txt":        {Data: []byte("1\n2\n3\n4\n5\n")},
+		"doc/chapter.org":  {Data: []byte("* Intro\nintro\n** Detail\n#+INCLUDE: \"../lines.txt\" :lines \"2-3\"\n* Usage\n:PROPERTIES:\n:CUSTOM_ID: usage\n:END:\nusage\n* TODO Last :tag:\nlast\n")},
+		"doc/main.go":      {Data: []byte("package main\n// * not headline\n")},
+		"cycle/a.org":      {Data: []byte("#+INCLUDE: b.org\n")},
+		"cycle/b.org":      {Data: []byte("#+include: \"/cycle/a.org\"\n")},
+		"invalid/root.org": {Data: []byte("text\n#+INCLUDE: \"x.org\" :minlevel zero\n")},
+	}
+	var tests = []struct {
+		desc      string
+		input     string
+		wantOut   string
+		wantError error
+	}{
+		{
+			desc:    "nested include with lines",
+			input:   "* Top\n#+INCLUDE: \"doc/chapter.org::*Intro\" :minlevel 2\nafter\n",
+			wantOut: "* Top\n** Intro\nintro\n*** Detail\n2\nafter\n",
+		},
+		{
+			desc:    "custom id and lines from the end",
+			input:   "#+INCLUDE: \"/doc/chapter.org::#usage\"\n#+INCLUDE: \"lines.txt\" :lines \"5-\"\n#+INCLUDE: doc/chapter.org::*Last :lines \"-2\"\n",
+			wantOut: "* Usage\n:PROPERTIES:\n:CUSTOM_ID: usage\n:END:\nusage\n5\n* TODO Last :tag:\n",
+		},
+		{
+			desc:    "source block",
+			input:   "#+INCLUDE: \"doc/main.go\" src go\n#+INCLUDE: \"lines.txt\" example :lines \"1-2\"\n",
+			wantOut: "#+BEGIN_SRC go\npackage main\n// * not headline\n#+END_SRC\n#+BEGIN_EXAMPLE\n1\n#+END_EXAMPLE\n",
+		},
+		{
+			desc:    "escaped source block",
+			input:   "#+INCLUDE: \"doc/chapter.org::#usage\" src org\n",
+			wantOut: "#+BEGIN_SRC org\n,* Usage\n:PROPERTIES:\n:CUSTOM_ID: usage\n:END:\nusage\n#+END_SRC\n",
+		},
+		{
+			desc:    "keywords in verbatim blocks",
+			input:   "#+begin_src org\n#+INCLUDE: \"lines.txt\"\n#+end_src\n#+BEGIN_EXAMPLE\n#+SETUPFILE: lines.txt\n#+END_EXAMPLE\n#+BEGIN_QUOTE\n#+INCLUDE: \"lines.txt\" :lines \"1-2\"\n#+END_QUOTE\n",
+			wantOut: "#+begin_src org\n#+INCLUDE: \"lines.txt\"\n#+end_src\n#+BEGIN_EXAMPLE\n#+SETUPFILE: lines.txt\n#+END_EXAMPLE\n#+BEGIN_QUOTE\n1\n#+END_QUOTE\n",
+		},
+		{
+			desc:      "cycle",
+			input:     "#+INCLUDE: \"cycle/a.org\"\n",
+			wantError: errors.New("input.org:1: cycle/a.org:1: cycle/b.org:1: include cycle: input.org -> cycle/a.org -> cycle/b.org -> cycle/a.org"),
+		},
+		{
+			desc:      "headline not found",
+			input:     "#+INCLUDE: \"doc/chapter.org::*Unknown\"\n",
+			wantError: errors.New(`input.org:1: headline is not found: "*Unknown"`),
+		},
+		{
+			desc:      "invalid minlevel",
+			input:     "#+INCLUDE: \"invalid/root.org\"\n",
+			wantError: errors.New(`input.org:1: invalid/root.org:2: invalid minlevel: "zero"`),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			fsys["input.org"] = &fstest.MapFile{Data: []byte(tt.input)}
+			got, err := Loader{FS: fsys}.ReadFile("input.org")
+			if err != nil {
+				if tt.wantError == nil || err.Error() != tt.wantError.Error() {
+					t.Fatalf("unexpected error: err=%v, want=%v", err, tt.wantError)
+				}
+				return
+			} else if tt.wantError != nil {
+				t.Fatalf("expect error but not occurred: want=%v", tt.wantError)
+			}
+			if string(got) != tt.wantOut {
+				t.Errorf("unexpected output:\ngot=%q\nwant=%q", got, tt.wantOut)
+			}
+		})
+	}
+}
+
+func TestLoaderLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"index.org":    {Data: []byte("#+INCLUDE: \"sub/part.org\" :minlevel 2\n")},
+		"sub/part.org": {Data: []byte("* Part\ntext\n")},
+	}
+	nodes, err := Loader{FS: fsys}.Load("index.org")
+	if err != nil {
+		t.Fatalf("unexpected error: err=%v", err)
+	}
+	want := []Node{
+		Headline{Starts: 2, Title: "Part"},
+		Section{Paragraphs: []string{"text"}},
+	}
+	if got := withoutPos(nodes); !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected nodes:\ngot=%#v\nwant=%#v", got, want)
+	}
+}
+
+func TestLoaderLoadLines(t *testing.T) {
+	fsys := fstest.MapFS{
+		"index.org": {Data: []byte("first\n#+INCLUDE: part.org\nlast\n")},
+		"part.org":  {Data: []byte("one\ntwo\n")},
+	}
+	_, lines, err := Loader{FS: fsys}.LoadLines("index.org")
+	if err != nil {
+		t.Fatalf("unexpected error: err=%v", err)
+	}
+	if want := []int{1, 2, 2, 3}; !reflect.DeepEqual(lines, want) {
+		t.Errorf("unexpected lines: got=%v, want=%v", lines, want)
+	}
+}
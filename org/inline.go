@@ -0,0 +1,223 @@
+package org
+
+import (
+	"io"
+	"regexp"
+	"unicode/utf8"
+)
+
+// inlineObject is an object in paragraphs such as footnote references.
+type inlineObject struct {
+	pattern *regexp.Regexp
+	// write writes the object of the submatches m as HTML.
+	write func(w io.Writer, ctx *htmlContext, m []string)
+	// enabled reports whether the match is converted. Otherwise, the text is
+	// scanned again from the next character of the match. nil means always.
+	enabled func(ctx *htmlContext, m []string) bool
+}
+
+// inlineObjects expresses currently supported inline objects. The earliest
+// match is used, and objects listed first have priority at the same position.
+var inlineObjects = []inlineObject{
+	{linkRegexp, writeLink, nil},
+	{footnoteRefRegexp, writeFootnoteRef, nil},
+	{mathDollarsRegexp, writeDisplayMath, nil},
+	{mathDollarRegexp, writeInlineMath, nil},
+	{mathParenRegexp, writeInlineMath, nil},
+	{mathBracketRegexp, writeDisplayMath, nil},
+	{lineBreakRegexp, writeLineBreak, nil},
+	{entityRegexp, writeEntity, nil},
+	{specialStringRegexp, writeSpecialString, nil},
+	{scriptRegexp, writeScript, scriptEnabled},
+}
+
+// writeInline writes the text of paragraphs, list items and table cells as
+// HTML. Inline objects are converted and the rest is escaped.
+func (ctx *htmlContext) writeInline(w io.Writer, text string) {
+	// locs is the next match of each object from the offset. A match is found
+	// again only after the offset passes its start, because the patterns do
+	// not depend on the text before the offset.
+	var (
+		locs     = make([][]int, len(inlineObjects))
+		searched = make([]bool, len(inlineObjects))
+		off      int
+	)
+	for off < len(text) {
+		var (
+			loc []int
+			obj inlineObject
+		)
+		for i, o := range inlineObjects {
+			if !searched[i] || (locs[i] != nil && locs[i][0] < off) {
+				locs[i] = shiftLoc(o.pattern.FindStringSubmatchIndex(text[off:]), off)
+				searched[i] = true
+			}
+			if l := locs[i]; l != nil && (loc == nil || l[0] < loc[0]) {
+				loc, obj = l, o
+			}
+		}
+		if loc == nil {
+			break
+		}
+		m := make([]string, len(loc)/2)
+		for i := range m {
+			if loc[2*i] >= 0 {
+				m[i] = text[loc[2*i]:loc[2*i+1]]
+			}
+		}
+		if obj.enabled != nil && !obj.enabled(ctx, m) {
+			_, size := utf8.DecodeRuneInString(text[loc[0]:])
+			io.WriteString(w, codeEscaper.Replace(text[off:loc[0]+size]))
+			off = loc[0] + size
+			continue
+		}
+		io.WriteString(w, codeEscaper.Replace(text[off:loc[0]]))
+		obj.write(w, ctx, m)
+		off = loc[1]
+	}
+	io.WriteString(w, codeEscaper.Replace(text[off:]))
+}
+
+// shiftLoc adds the offset to the indexes of the submatches.
+func shiftLoc(loc []int, off int) []int {
+	for i := range loc {
+		if loc[i] >= 0 {
+			loc[i] += off
+		}
+	}
+	return loc
+}
+
+// walkInline calls fn with the text which can contain inline objects in the
+// document order. pos is the position of the element of the text.
+func walkInline(nodes []Node, fn func(pos Pos, text string)) {
+	for _, node := range nodes {
+		switch n := node.(type) {
+		case Section:
+			for _, p := range n.Paragraphs {
+				fn(n.Pos, p)
+			}
+		case List:
+			walkInlineList(n, fn)
+		case Table:
+			for _, row := range n.Rows {
+				for _, cell := range row {
+					fn(n.Pos, cell)
+				}
+			}
+		case Block:
+			walkInline(n.Children, fn)
+		case Drawer:
+			walkInline(n.Children, fn)
+		case DynamicBlock:
+			walkInline(n.Children, fn)
+		case FootnoteDefinition:
+			walkInline(n.Children, fn)
+		case SourceBlock:
+			if n.Results != nil {
+				walkInline(n.Results.Value, fn)
+			}
+		case Results:
+			walkInline(n.Value, fn)
+		}
+	}
+}
+
+func walkInlineList(l List, fn func(pos Pos, text string)) {
+	for _, item := range l.Items {
+		fn(item.Pos, item.Content)
+		if item.Sublist != nil {
+			walkInlineList(*item.Sublist, fn)
+		}
+	}
+}
+
+// mapInline returns the nodes whose text is replaced by fn. The text is the
+// same as walkInline and headline titles. The nodes are not modified.
+func mapInline(nodes []Node, fn func(pos Pos, text string) (string, error)) ([]Node, error) {
+	if nodes == nil {
+		return nil, nil
+	}
+	ret := make([]Node, len(nodes))
+	for i, node := range nodes {
+		var err error
+		switch n := node.(type) {
+		case Headline:
+			n.Title, err = fn(n.Pos, n.Title)
+			node = n
+		case Section:
+			n.Paragraphs = append([]string(nil), n.Paragraphs...)
+			for j := range n.Paragraphs {
+				if n.Paragraphs[j], err = fn(n.Pos, n.Paragraphs[j]); err != nil {
+					return nil, err
+				}
+			}
+			node = n
+		case List:
+			n, err = mapInlineList(n, fn)
+			node = n
+		case Table:
+			rows := make([][]string, len(n.Rows))
+			for j, row := range n.Rows {
+				if row == nil {
+					continue
+				}
+				rows[j] = make([]string, len(row))
+				for k := range row {
+					if rows[j][k], err = fn(n.Pos, row[k]); err != nil {
+						return nil, err
+					}
+				}
+			}
+			n.Rows = rows
+			node = n
+		case Block:
+			n.Children, err = mapInline(n.Children, fn)
+			node = n
+		case Drawer:
+			n.Children, err = mapInline(n.Children, fn)
+			node = n
+		case DynamicBlock:
+			n.Children, err = mapInline(n.Children, fn)
+			node = n
+		case FootnoteDefinition:
+			n.Children, err = mapInline(n.Children, fn)
+			node = n
+		case SourceBlock:
+			if n.Results != nil {
+				results := *n.Results
+				results.Value, err = mapInline(results.Value, fn)
+				n.Results = &results
+			}
+			node = n
+		case Results:
+			n.Value, err = mapInline(n.Value, fn)
+			node = n
+		}
+		if err != nil {
+			return nil, err
+		}
+		ret[i] = node
+	}
+	return ret, nil
+}
+
+func mapInlineList(l List, fn func(pos Pos, text string) (string, error)) (List, error) {
+	items := make([]ListItem, len(l.Items))
+	for i, item := range l.Items {
+		var err error
+		if item.Content, err = fn(item.Pos, item.Content); err != nil {
+			return List{}, err
+		}
+		if item.Sublist != nil {
+			sublist, err := mapInlineList(*item.Sublist, fn)
+			if err != nil {
+				return List{}, err
+			}
+			item.Sublist = &sublist
+		}
+		items[i] = item
+	}
+	l.Items = items
+	return l, nil
+}
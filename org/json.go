@@ -0,0 +1,121 @@
+package org
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+const (
+	jsonTypeKey       = "type"
+	timestampJSONType = "timestamp"
+)
+
+// jsonNodeTypes maps the type discriminator of JSON objects to Node types.
+var jsonNodeTypes = map[string]reflect.Type{
+	"agenda":             reflect.TypeOf(Agenda{}),
+	"block":              reflect.TypeOf(Block{}),
+	"clock":              reflect.TypeOf(Clock{}),
+	"comment":            reflect.TypeOf(Comment{}),
+	"drawer":             reflect.TypeOf(Drawer{}),
+	"dynamicBlock":       reflect.TypeOf(DynamicBlock{}),
+	"fixedWidth":         reflect.TypeOf(FixedWidth{}),
+	"footnoteDefinition": reflect.TypeOf(FootnoteDefinition{}),
+	"headline":           reflect.TypeOf(Headline{}),
+	"keyword":            reflect.TypeOf(Keyword{}),
+	"latexEnvironment":   reflect.TypeOf(LatexEnvironment{}),
+	"list":               reflect.TypeOf(List{}),
+	"propertyDrawer":     reflect.TypeOf(PropertyDrawer{}),
+	"results":            reflect.TypeOf(Results{}),
+	"section":            reflect.TypeOf(Section{}),
+	"sourceBlock":        reflect.TypeOf(SourceBlock{}),
+	"table":              reflect.TypeOf(Table{}),
+}
+
+// jsonTypeNames is the reverse map of jsonNodeTypes.
+var jsonTypeNames = func() map[reflect.Type]string {
+	names := make(map[reflect.Type]string, len(jsonNodeTypes))
+	for name, typ := range jsonNodeTypes {
+		names[typ] = name
+	}
+	return names
+}()
+
+// Nodes is a list of Node which can be encoded to and decoded from JSON.
+// Each node is encoded as a JSON object with the "type" discriminator.
+// See docs/json.md for the schema.
+type Nodes []Node
+
+// MarshalJSON implements json.Marshaler.
+func (ns Nodes) MarshalJSON() ([]byte, error) {
+	objs := make([]map[string]json.RawMessage, len(ns))
+	for i, node := range ns {
+		name, ok := jsonTypeNames[reflect.TypeOf(node)]
+		if !ok {
+			return nil, fmt.Errorf("node[%d] is unsupported type: %T", i, node)
+		}
+		data, err := json.Marshal(node)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &objs[i]); err != nil {
+			return nil, err
+		}
+		objs[i][jsonTypeKey], _ = json.Marshal(name)
+	}
+	return json.Marshal(objs)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (ns *Nodes) UnmarshalJSON(data []byte) error {
+	var objs []json.RawMessage
+	if err := json.Unmarshal(data, &objs); err != nil {
+		return err
+	}
+	nodes := make(Nodes, len(objs))
+	for i, obj := range objs {
+		var v struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(obj, &v); err != nil {
+			return err
+		}
+		typ, ok := jsonNodeTypes[v.Type]
+		if !ok {
+			return fmt.Errorf("node[%d] has unknown type: %q", i, v.Type)
+		}
+		ptr := reflect.New(typ)
+		if err := json.Unmarshal(obj, ptr.Interface()); err != nil {
+			return fmt.Errorf("node[%d]: %w", i, err)
+		}
+		nodes[i] = ptr.Elem().Interface().(Node)
+	}
+	*ns = nodes
+	return nil
+}
+
+// WriteJSON writes nodes as a JSON array to the specified writer.
+func WriteJSON(nodes []Node, out io.Writer) error {
+	data, err := Nodes(nodes).MarshalJSON()
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, data, "", "  "); err != nil {
+		return err
+	}
+	buf.WriteString("\n")
+	_, err = buf.WriteTo(out)
+	return err
+}
+
+// ReadJSON reads nodes from JSON written by WriteJSON.
+func ReadJSON(in io.Reader) ([]Node, error) {
+	var nodes Nodes
+	if err := json.NewDecoder(in).Decode(&nodes); err != nil {
+		return nil, err
+	}
+	return nodes, nil
+}
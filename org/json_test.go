@@ -0,0 +1,160 @@
+package org_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	. "github.com/Ladicle/org2html/org"
+)
+
+func TestWriteJSON(t *testing.T) {
+	var tests = []struct {
+		desc      string
+		nodes     []Node
+		wantOut   string
+		wantError error
+	}{
+		{
+			desc:    "no node",
+			wantOut: "[]\n",
+		},
+		{
+			desc: "keyword and agenda",
+			nodes: []Node{
+				Keyword{Pos: Pos{Line: 1}, Key: "TITLE", Value: "title"},
+				Agenda{Pos: Pos{Line: 2}, Logs: map[AgendaKey]Timestamp{
+					AgendaScheduled: mustParseDatestamp(t, "2022-01-30 Sun", "+1w"),
+				}},
+			},
+			wantOut: `[
+  {
+    "key": "TITLE",
+    "pos": {
+      "line": 1
+    },
+    "type": "keyword",
+    "value": "title"
+  },
+  {
+    "logs": {
+      "SCHEDULED": {
+        "type": "timestamp",
+        "time": "2022-01-30T00:00:00Z",
+        "isDate": true,
+        "interval": "+1w"
+      }
+    },
+    "pos": {
+      "line": 2
+    },
+    "type": "agenda"
+  }
+]
+`,
+		},
+		{
+			desc:      "unsupported node",
+			nodes:     []Node{testNode{}},
+			wantError: errors.New("node[0] is unsupported type: org_test.testNode"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var out bytes.Buffer
+			err := WriteJSON(tt.nodes, &out)
+			if err != nil {
+				if tt.wantError == nil || err.Error() != tt.wantError.Error() {
+					t.Fatalf("unexpected error: err=%v, want=%v", err, tt.wantError)
+				}
+				return
+			} else if tt.wantError != nil {
+				t.Fatalf("expect error but not occurred: want=%v", tt.wantError)
+			}
+			if got := out.String(); got != tt.wantOut {
+				t.Errorf("unexpected output:\ngot=%v\nwant=%v", got, tt.wantOut)
+			}
+		})
+	}
+}
+
+func TestReadJSON(t *testing.T) {
+	var tests = []struct {
+		desc      string
+		input     string
+		wantNodes []Node
+		wantError error
+	}{
+		{
+			desc:      "empty",
+			input:     "[]",
+			wantNodes: []Node{},
+		},
+		{
+			desc:  "headline",
+			input: `[{"type":"headline","pos":{"line":3},"starts":1,"title":"title","tags":["a"]}]`,
+			wantNodes: []Node{
+				Headline{Pos: Pos{Line: 3}, Starts: 1, Title: "title", Tags: []string{"a"}},
+			},
+		},
+		{
+			desc:      "unknown type",
+			input:     `[{"type":"unknown"}]`,
+			wantError: errors.New("node[0] has unknown type: \"unknown\""),
+		},
+		{
+			desc:      "invalid timestamp",
+			input:     `[{"type":"agenda","logs":{"CLOSED":{"type":"date"}}}]`,
+			wantError: errors.New("node[0]: unexpected timestamp type: \"date\""),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			nodes, err := ReadJSON(strings.NewReader(tt.input))
+			if err != nil {
+				if tt.wantError == nil || err.Error() != tt.wantError.Error() {
+					t.Fatalf("unexpected error: err=%v, want=%v", err, tt.wantError)
+				}
+				return
+			} else if tt.wantError != nil {
+				t.Fatalf("expect error but not occurred: want=%v", tt.wantError)
+			}
+			if !reflect.DeepEqual(nodes, tt.wantNodes) {
+				t.Errorf("unexpected nodes:\ngot=%#v\nwant=%#v", nodes, tt.wantNodes)
+			}
+		})
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "*", "*.org"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files = append(files, filepath.Join("..", "test", "fixtures", "input.org"))
+	for _, file := range files {
+		t.Run(file, func(t *testing.T) {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			nodes := parseString(t, string(data))
+
+			var out bytes.Buffer
+			if err := WriteJSON(nodes, &out); err != nil {
+				t.Fatalf("unexpected error: err=%v", err)
+			}
+			got, err := ReadJSON(&out)
+			if err != nil {
+				t.Fatalf("unexpected error: err=%v", err)
+			}
+			if !reflect.DeepEqual(got, nodes) {
+				t.Errorf("unexpected nodes:\ngot=%#v\nwant=%#v", got, nodes)
+			}
+		})
+	}
+}
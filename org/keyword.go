@@ -24,8 +24,9 @@ const (
 var _ Node = Keyword{}
 
 type Keyword struct {
-	Key   string
-	Value string
+	Pos   Pos    `json:"pos"`
+	Key   string `json:"key"`
+	Value string `json:"value"`
 }
 
 func (k Keyword) Write(w io.Writer) error {
@@ -33,6 +34,14 @@ func (k Keyword) Write(w io.Writer) error {
 	return nil
 }
 
+// writeHTML writes the footnotes section at #+PRINT_FOOTNOTES.
+func (k Keyword) writeHTML(w io.Writer, ctx *htmlContext) error {
+	if k.Key == printFootnotesKey {
+		return ctx.writeFootnotes(w)
+	}
+	return k.Write(w)
+}
+
 // org syntax supports optional, but I couldn't find out the use-cases.
 // Therefore, the optional value ignore here.
 // https://orgmode.org/worg/dev/org-syntax.html#Affiliated_keywords
@@ -55,10 +64,30 @@ func ParseKeyword(p *Parser, i int) (int, Node, error) {
 		key = strings.ToUpper(p.tokens[i].vals[0])
 		val = strings.TrimSpace(p.tokens[i].vals[1])
 	)
-	switch KeywordType(key) {
-	case "":
+	switch {
+	case key == "":
 		return 0, nil, errors.New("keyword key is empty")
+	case isResultsKey(key):
+		// results are parsed with the following element.
+		return parseResults(p, i)
 	default:
-		return 1, Keyword{Key: key, Value: val}, nil
+		return 1, Keyword{Pos: p.tokens[i].pos, Key: key, Value: val}, nil
+	}
+}
+
+// affiliatedValues returns the values of the affiliated keywords such as
+// #+NAME, which are placed just before the element p.tokens[i]. Each value is
+// converted by fn.
+func (p *Parser) affiliatedValues(i int, key string, fn func(string) []string) []string {
+	start := i
+	for start > 0 && p.tokens[start-1].kind == KindKeyword {
+		start--
+	}
+	var vals []string
+	for j := start; j < i; j++ {
+		if len(p.tokens[j].vals) == 2 && strings.EqualFold(p.tokens[j].vals[0], key) {
+			vals = append(vals, fn(p.tokens[j].vals[1])...)
+		}
 	}
+	return vals
 }
@@ -0,0 +1,171 @@
+package org
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// linkRegexp matches links such as `[[https://example.com][description]]`
+// and `[[file:notes.org]]`.
+var linkRegexp = regexp.MustCompile(`\[\[([^\[\]]+)\](?:\[([^\[\]]+)\])?\]`)
+
+// imageExtensions are the extensions of the links which are written as images
+// if they do not have descriptions.
+var imageExtensions = map[string]bool{
+	".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".svg": true, ".webp": true,
+}
+
+// writeLink writes the link as an anchor or an image.
+func writeLink(w io.Writer, ctx *htmlContext, m []string) {
+	target, desc := m[1], m[2]
+	url := ctx.linkURL(target)
+	if desc == "" && imageExtensions[strings.ToLower(path.Ext(url))] {
+		fmt.Fprintf(w, `<img src="%s" alt="%s">`, html.EscapeString(url), html.EscapeString(path.Base(url)))
+		return
+	}
+	if desc == "" {
+		desc = target
+	}
+	fmt.Fprintf(w, `<a href="%s">`, html.EscapeString(url))
+	writeEntities(w, ctx, desc)
+	io.WriteString(w, "</a>")
+}
+
+// linkURL returns the URL of the link target, which is resolved against
+// HTMLWriter.BaseURL.
+func (ctx *htmlContext) linkURL(target string) string {
+	u := ctx.resolveLink(target)
+	if ctx.BaseURL == "" {
+		return u
+	}
+	base, err := url.Parse(ctx.BaseURL)
+	if err != nil {
+		return u
+	}
+	ref, err := url.Parse(u)
+	if err != nil {
+		return u
+	}
+	return base.ResolveReference(ref).String()
+}
+
+// resolveLink returns the URL of the link target. Abbreviations of #+LINK are
+// expanded first, and then the target is resolved by HTMLWriter.ResolveLink.
+// Otherwise, links to Org files are converted to HTML files, and `id:` links
+// refer to the headlines in the document.
+func (ctx *htmlContext) resolveLink(target string) string {
+	target = ctx.expandLinkAbbrev(target)
+	if ctx.ResolveLink != nil {
+		if url, ok := ctx.ResolveLink(target); ok {
+			return url
+		}
+	}
+	switch {
+	case strings.HasPrefix(target, "file:"):
+		file, search := splitLinkSearch(strings.TrimPrefix(target, "file:"))
+		if strings.HasSuffix(file, ".org") {
+			file = strings.TrimSuffix(file, ".org") + ".html"
+		}
+		return file + linkFragment(search)
+	case strings.HasPrefix(target, "id:"):
+		return "#" + strings.TrimPrefix(target, "id:")
+	}
+	return target
+}
+
+// splitLinkSearch splits the file link such as `notes.org::#custom-id` into
+// the file and the search option.
+func splitLinkSearch(target string) (file, search string) {
+	if i := strings.Index(target, "::"); i >= 0 {
+		return target[:i], target[i+2:]
+	}
+	return target, ""
+}
+
+// linkFragment returns the URL fragment of the search option. Only custom IDs
+// such as `#custom-id` are supported.
+func linkFragment(search string) string {
+	if strings.HasPrefix(search, "#") {
+		return search
+	}
+	return ""
+}
+
+// expandLinkAbbrev expands the link abbreviation of #+LINK such as
+// `#+LINK: gh https://github.com/%s`. The tag is appended if the replacement
+// does not contain `%s`.
+func (ctx *htmlContext) expandLinkAbbrev(target string) string {
+	i := strings.Index(target, ":")
+	if i < 0 || ctx.links == nil {
+		return target
+	}
+	replacement, ok := ctx.links[target[:i]]
+	if !ok {
+		return target
+	}
+	if strings.Contains(replacement, "%s") {
+		return strings.Replace(replacement, "%s", target[i+1:], 1)
+	}
+	return replacement + target[i+1:]
+}
+
+// linkTargets returns the targets of the links in nodes whose abbreviations
+// are expanded.
+func linkTargets(nodes []Node) []string {
+	var (
+		ctx     = &htmlContext{links: linkAbbrevs(nodes)}
+		targets []string
+	)
+	walkInline(nodes, func(pos Pos, text string) {
+		for _, m := range linkRegexp.FindAllStringSubmatch(text, -1) {
+			targets = append(targets, ctx.expandLinkAbbrev(m[1]))
+		}
+	})
+	return targets
+}
+
+// linkAbbrevs returns the link abbreviations of #+LINK keywords in nodes.
+func linkAbbrevs(nodes []Node) map[string]string {
+	links := make(map[string]string)
+	for _, node := range nodes {
+		if k, ok := node.(Keyword); ok && k.Key == string(LinkKey) {
+			if fields := strings.Fields(k.Value); len(fields) == 2 {
+				links[fields[0]] = fields[1]
+			}
+		}
+	}
+	return links
+}
+
+// headlineAnchors returns the IDs of headlines from CUSTOM_ID or ID property,
+// which are written as the id attributes for links.
+func headlineAnchors(nodes []Node) map[int]string {
+	anchors := make(map[int]string)
+	for i := range nodes {
+		if id, ok := headlineID(nodes, i); ok {
+			anchors[i] = id
+		}
+	}
+	return anchors
+}
+
+// headlineID returns the CUSTOM_ID or ID property of the headline nodes[i].
+// It returns false if nodes[i] is not a headline.
+func headlineID(nodes []Node, i int) (string, bool) {
+	if _, ok := nodes[i].(Headline); !ok {
+		return "", false
+	}
+	props, ok := HeadlineProperties(nodes, i)
+	if !ok {
+		return "", false
+	}
+	if id, ok := props.Get("CUSTOM_ID"); ok {
+		return id, true
+	}
+	return props.Get("ID")
+}
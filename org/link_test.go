@@ -0,0 +1,59 @@
+package org_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	. "github.com/Ladicle/org2html/org"
+)
+
+func TestLinkWriter(t *testing.T) {
+	var tests = []struct {
+		desc    string
+		input   string
+		writer  HTMLWriter
+		wantOut string
+	}{
+		{
+			desc:    "links",
+			input:   "[[https://example.com/?a=1&b=2][Example \\alpha]] [[https://example.com/a_b]] [[file:img/a.PNG]] [[file:img/a.png][image]]\n",
+			wantOut: `<p><a href="https://example.com/?a=1&amp;b=2">Example &alpha;</a> <a href="https://example.com/a_b">https://example.com/a_b</a> <img src="img/a.PNG" alt="a.PNG"> <a href="img/a.png">image</a></p>` + "\n",
+		},
+		{
+			desc:    "org files and ids",
+			input:   "* Target\n:PROPERTIES:\n:CUSTOM_ID: target\n:END:\n[[file:notes.org::#usage][usage]] [[file:../a.org::*Heading]] [[id:target][target]]\n",
+			wantOut: "<h1 id=\"target\" class=\"org-headline\">\nTarget\n</h1>\n" + `<p><a href="notes.html#usage">usage</a> <a href="../a.html">file:../a.org::*Heading</a> <a href="#target">target</a></p>` + "\n",
+		},
+		{
+			desc:    "abbreviations",
+			input:   "#+LINK: gh https://github.com/%s\n#+LINK: wiki https://en.wikipedia.org/wiki/\n[[gh:Ladicle/org2html][repo]] [[wiki:Org-mode][wiki]]\n",
+			wantOut: `<p><a href="https://github.com/Ladicle/org2html">repo</a> <a href="https://en.wikipedia.org/wiki/Org-mode">wiki</a></p>` + "\n",
+		},
+		{
+			desc:  "resolve link",
+			input: "[[file:a.org][a]] [[file:b.org][b]]\n",
+			writer: HTMLWriter{ResolveLink: func(target string) (string, bool) {
+				return "/docs/" + strings.TrimPrefix(target, "file:"), target == "file:a.org"
+			}},
+			wantOut: `<p><a href="/docs/a.org">a</a> <a href="b.html">b</a></p>` + "\n",
+		},
+		{
+			desc:    "base url",
+			input:   "[[file:../a.org][a]] [[#top][top]] [[https://example.org/][ext]]\n",
+			writer:  HTMLWriter{BaseURL: "https://example.com/notes/b.html"},
+			wantOut: `<p><a href="https://example.com/a.html">a</a> <a href="https://example.com/notes/b.html#top">top</a> <a href="https://example.org/">ext</a></p>` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := tt.writer.Write(parseString(t, tt.input), &out); err != nil {
+				t.Fatalf("unexpected error: err=%v", err)
+			}
+			if got := out.String(); got != tt.wantOut {
+				t.Errorf("unexpected output:\ngot=%v\nwant=%v", got, tt.wantOut)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package org
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+var _ Node = List{}
+
+// List is a Node to describe plain lists. Nested lists are stored in the
+// Sublist of the parent item.
+type List struct {
+	Pos     Pos        `json:"pos"`
+	Ordered bool       `json:"ordered"`
+	Items   []ListItem `json:"items"`
+}
+
+type ListItem struct {
+	Pos    Pos    `json:"pos"`
+	Bullet string `json:"bullet"`
+	// Content is the text of the item. Continuation lines which are indented
+	// deeper than the bullet are separated by a newline, including the lines
+	// after the sublist.
+	Content string `json:"content"`
+	Sublist *List  `json:"sublist,omitempty"` // optional
+}
+
+func (l List) Write(w io.Writer) error {
+	return l.writeHTML(w, &htmlContext{HTMLWriter: DefaultHTMLWriter()})
+}
+
+func (l List) writeHTML(w io.Writer, ctx *htmlContext) error {
+	tag := "ul"
+	if l.Ordered {
+		tag = "ol"
+	}
+	fmt.Fprintf(w, "<%s class=\"org-list\">\n", tag)
+	for _, item := range l.Items {
+		fmt.Fprint(w, "<li>")
+		ctx.writeInline(w, item.Content)
+		if item.Sublist != nil {
+			fmt.Fprintln(w)
+			if err := item.Sublist.writeHTML(w, ctx); err != nil {
+				return err
+			}
+		}
+		fmt.Fprintln(w, "</li>")
+	}
+	fmt.Fprintf(w, "</%s>\n", tag)
+	return nil
+}
+
+var listItemRegexp = regexp.MustCompile(`^(\s*)([-+*]|\d+[.)])\s+(.*)`)
+
+func LexListItem(line string) (Token, bool) {
+	if m := listItemRegexp.FindStringSubmatch(line); m != nil {
+		return NewToken(KindListItem, 1, m[1:]), true
+	}
+	return Token{}, false
+}
+
+func ParseList(p *Parser, i int) (int, Node, error) {
+	consumed, list, err := parseList(p, i)
+	if err != nil {
+		return 0, nil, err
+	}
+	return consumed, list, nil
+}
+
+// parseList parses list items which have the same indentation as the first
+// item. More indented items are parsed as the sublist of the previous item.
+func parseList(p *Parser, i int) (int, List, error) {
+	var (
+		list   List
+		start  = i
+		indent = -1
+	)
+	for i < len(p.tokens) && p.tokens[i].kind == KindListItem {
+		vals := p.tokens[i].vals
+		if len(vals) != 3 {
+			return 0, List{}, fmt.Errorf("list item token[%d] does not have 3 values: got=%d", i, len(vals))
+		}
+		switch n := len(vals[0]); {
+		case indent < 0:
+			indent = n
+			list.Pos = p.tokens[i].pos
+			list.Ordered = !strings.ContainsAny(vals[1], "-+*")
+		case n < indent:
+			return i - start, list, nil
+		case n > indent:
+			consumed, sub, err := parseList(p, i)
+			if err != nil {
+				return 0, List{}, err
+			}
+			// items which are less indented than the existing sublist are
+			// also its items.
+			last := &list.Items[len(list.Items)-1]
+			if last.Sublist != nil {
+				sub.Pos, sub.Ordered = last.Sublist.Pos, last.Sublist.Ordered
+				sub.Items = append(append([]ListItem{}, last.Sublist.Items...), sub.Items...)
+			}
+			last.Sublist = &sub
+			i += consumed
+			for ; i < len(p.tokens) && isContinuationLine(p.tokens[i], indent); i++ {
+				last.Content += "\n" + p.tokens[i].vals[0]
+			}
+			continue
+		}
+		item := ListItem{
+			Pos:     p.tokens[i].pos,
+			Bullet:  vals[1],
+			Content: strings.TrimSpace(vals[2]),
+		}
+		i++
+		for ; i < len(p.tokens) && isContinuationLine(p.tokens[i], indent); i++ {
+			item.Content += "\n" + p.tokens[i].vals[0]
+		}
+		list.Items = append(list.Items, item)
+	}
+	return i - start, list, nil
+}
+
+// isContinuationLine reports whether the token is a non-blank text line which
+// is indented deeper than the bullet of the item at indent.
+func isContinuationLine(t Token, indent int) bool {
+	if t.kind != KindText || len(t.vals) == 0 || t.vals[0] == "" {
+		return false
+	}
+	line := t.text()
+	return len(line)-len(strings.TrimLeft(line, " \t")) > indent
+}
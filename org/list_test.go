@@ -0,0 +1,231 @@
+package org_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	. "github.com/Ladicle/org2html/org"
+)
+
+func TestLexListItem(t *testing.T) {
+	var tests = []struct {
+		desc      string
+		line      string
+		wantToken Token
+		wantFlag  bool
+	}{
+		{
+			desc:      "empty line",
+			line:      "",
+			wantToken: Token{},
+		},
+		{
+			desc:      "unordered item",
+			line:      "- item",
+			wantFlag:  true,
+			wantToken: NewToken(KindListItem, 1, []string{"", "-", "item"}),
+		},
+		{
+			desc:      "ordered item with indent",
+			line:      "  10) item",
+			wantFlag:  true,
+			wantToken: NewToken(KindListItem, 1, []string{"  ", "10)", "item"}),
+		},
+		{
+			desc:      "star item with indent",
+			line:      " * item",
+			wantFlag:  true,
+			wantToken: NewToken(KindListItem, 1, []string{" ", "*", "item"}),
+		},
+		{
+			desc:      "no space after bullet",
+			line:      "-item",
+			wantToken: Token{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			token, flag := LexListItem(tt.line)
+			if flag != tt.wantFlag {
+				t.Errorf("unexpected flag: got=%v, want=%v", flag, tt.wantFlag)
+			}
+			if !reflect.DeepEqual(token, tt.wantToken) {
+				t.Errorf("unexpected token:\ngot=%#v\nwant=%#v", token, tt.wantToken)
+			}
+		})
+	}
+}
+
+func TestParseList(t *testing.T) {
+	var tests = []struct {
+		desc         string
+		tokens       []Token
+		wantConsumed int
+		wantNode     Node
+	}{
+		{
+			desc: "one item",
+			tokens: []Token{
+				NewToken(KindListItem, 1, []string{"", "-", "item"}),
+			},
+			wantNode:     List{Items: []ListItem{{Bullet: "-", Content: "item"}}},
+			wantConsumed: 1,
+		},
+		{
+			desc: "ordered list followed by text",
+			tokens: []Token{
+				NewToken(KindListItem, 1, []string{"", "1.", "item1"}),
+				NewToken(KindListItem, 1, []string{"", "2.", "item2"}),
+				NewToken(KindText, 1, []string{"text"}),
+			},
+			wantNode: List{
+				Ordered: true,
+				Items:   []ListItem{{Bullet: "1.", Content: "item1"}, {Bullet: "2.", Content: "item2"}},
+			},
+			wantConsumed: 2,
+		},
+		{
+			desc: "nested list",
+			tokens: []Token{
+				NewToken(KindListItem, 1, []string{"", "-", "item1"}),
+				NewToken(KindListItem, 1, []string{"  ", "+", "sub1"}),
+				NewToken(KindListItem, 1, []string{"    ", "1)", "subsub1"}),
+				NewToken(KindListItem, 1, []string{"  ", "+", "sub2"}),
+				NewToken(KindListItem, 1, []string{"", "-", "item2"}),
+			},
+			wantNode: List{Items: []ListItem{
+				{Bullet: "-", Content: "item1", Sublist: &List{Items: []ListItem{
+					{Bullet: "+", Content: "sub1", Sublist: &List{
+						Ordered: true,
+						Items:   []ListItem{{Bullet: "1)", Content: "subsub1"}},
+					}},
+					{Bullet: "+", Content: "sub2"},
+				}}},
+				{Bullet: "-", Content: "item2"},
+			}},
+			wantConsumed: 5,
+		},
+		{
+			desc: "less indented sublist item",
+			tokens: []Token{
+				NewToken(KindListItem, 1, []string{"", "-", "a"}),
+				NewToken(KindListItem, 1, []string{"    ", "-", "b"}),
+				NewToken(KindListItem, 1, []string{"  ", "-", "c"}),
+				NewToken(KindListItem, 1, []string{"", "-", "d"}),
+			},
+			wantNode: List{Items: []ListItem{
+				{Bullet: "-", Content: "a", Sublist: &List{Items: []ListItem{{Bullet: "-", Content: "b"}, {Bullet: "-", Content: "c"}}}},
+				{Bullet: "-", Content: "d"},
+			}},
+			wantConsumed: 4,
+		},
+		{
+			desc: "less indented item ends list",
+			tokens: []Token{
+				NewToken(KindListItem, 1, []string{"  ", "-", "item1"}),
+				NewToken(KindListItem, 1, []string{"", "-", "item2"}),
+			},
+			wantNode:     List{Items: []ListItem{{Bullet: "-", Content: "item1"}}},
+			wantConsumed: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			parser := DefaultParser(tt.tokens)
+			consumed, node, err := ParseList(&parser, 0)
+			if err != nil {
+				t.Fatalf("unexpected error: err=%v", err)
+			}
+			if consumed != tt.wantConsumed {
+				t.Errorf("unexpected consumed: got=%v, want=%v", consumed, tt.wantConsumed)
+			}
+			if !reflect.DeepEqual(node, tt.wantNode) {
+				t.Errorf("unexpected node:\ngot=%#v\nwant=%#v", node, tt.wantNode)
+			}
+		})
+	}
+}
+
+func TestParseListText(t *testing.T) {
+	var tests = []struct {
+		desc      string
+		input     string
+		wantNodes []Node
+	}{
+		{
+			desc:  "continuation lines",
+			input: "- item one\n  continued line\n- item two\ntext\n",
+			wantNodes: []Node{
+				List{Items: []ListItem{{Bullet: "-", Content: "item one\ncontinued line"}, {Bullet: "-", Content: "item two"}}},
+				Section{Paragraphs: []string{"text"}},
+			},
+		},
+		{
+			desc:  "sublist items with different indentation",
+			input: "- a\n    - b\n  - c\n- d\n",
+			wantNodes: []Node{
+				List{Items: []ListItem{
+					{Bullet: "-", Content: "a", Sublist: &List{Items: []ListItem{{Bullet: "-", Content: "b"}, {Bullet: "-", Content: "c"}}}},
+					{Bullet: "-", Content: "d"},
+				}},
+			},
+		},
+		{
+			desc:  "continuation of nested item",
+			input: "1. item\n   - sub\n     more\n   text of item\n",
+			wantNodes: []Node{
+				List{Ordered: true, Items: []ListItem{{Bullet: "1.", Content: "item\ntext of item", Sublist: &List{
+					Items: []ListItem{{Bullet: "-", Content: "sub\nmore"}},
+				}}}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			if got := withoutPos(parseString(t, tt.input)); !reflect.DeepEqual(got, tt.wantNodes) {
+				t.Errorf("unexpected nodes:\ngot=%#v\nwant=%#v", got, tt.wantNodes)
+			}
+		})
+	}
+}
+
+func TestListWriter(t *testing.T) {
+	var tests = []struct {
+		desc    string
+		list    List
+		wantOut string
+	}{
+		{
+			desc:    "unordered list",
+			list:    List{Items: []ListItem{{Bullet: "-", Content: "item1"}, {Bullet: "-", Content: "item2"}}},
+			wantOut: "<ul class=\"org-list\">\n<li>item1</li>\n<li>item2</li>\n</ul>\n",
+		},
+		{
+			desc: "nested list",
+			list: List{Items: []ListItem{{Bullet: "-", Content: "item", Sublist: &List{
+				Ordered: true,
+				Items:   []ListItem{{Bullet: "1.", Content: "sub"}},
+			}}}},
+			wantOut: `<ul class="org-list">
+<li>item
+<ol class="org-list">
+<li>sub</li>
+</ol>
+</li>
+</ul>
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := tt.list.Write(&out); err != nil {
+				t.Fatalf("unexpected error: err=%v", err)
+			}
+			if got := out.String(); got != tt.wantOut {
+				t.Errorf("unexpected output: got=%v, want=%v", got, tt.wantOut)
+			}
+		})
+	}
+}
@@ -0,0 +1,318 @@
+package org
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// macroRegexp matches macro references such as `{{{name(arg1, arg2)}}}`.
+var macroRegexp = regexp.MustCompile(`(?s)\{\{\{([A-Za-z][-\w]*)(?:\((.*?)\))?\}\}\}`)
+
+// MacroEnv is the environment of the built-in macros which does not come from
+// the document.
+type MacroEnv struct {
+	// InputFile is the path of the document for {{{input-file}}}.
+	InputFile string
+	// ModTime is the modification time for {{{modification-time(FMT)}}}.
+	// The macro is expanded to an empty string if it is zero.
+	ModTime time.Time
+	// Now is the current time for {{{time(FMT)}}}. time.Now is used if it is
+	// zero.
+	Now time.Time
+}
+
+// builtinMacros are the macros which are defined without #+MACRO. They can be
+// overridden by #+MACRO.
+var builtinMacros = map[string]func(m *macros, args []string) string{
+	"title":  func(m *macros, args []string) string { return m.keyword("TITLE") },
+	"author": func(m *macros, args []string) string { return m.keyword("AUTHOR") },
+	"date": func(m *macros, args []string) string {
+		date := m.keyword("DATE")
+		if len(args) > 0 && args[0] != "" {
+			if ts, err := ParseTimestampLiteral(date); err == nil {
+				return formatTime(args[0], ts.Time)
+			}
+		}
+		return date
+	},
+	"time": func(m *macros, args []string) string {
+		now := m.env.Now
+		if now.IsZero() {
+			now = time.Now()
+		}
+		return formatTime(macroArg(args, 0), now)
+	},
+	"modification-time": func(m *macros, args []string) string {
+		if m.env.ModTime.IsZero() {
+			return ""
+		}
+		return formatTime(macroArg(args, 0), m.env.ModTime)
+	},
+	"input-file": func(m *macros, args []string) string {
+		if m.env.InputFile == "" {
+			return ""
+		}
+		return filepath.Base(m.env.InputFile)
+	},
+	"keyword": func(m *macros, args []string) string {
+		return m.keyword(strings.ToUpper(macroArg(args, 0)))
+	},
+	"property": func(m *macros, args []string) string {
+		if m.entry < 0 {
+			return ""
+		}
+		props, _ := HeadlineProperties(m.nodes, m.entry)
+		v, _ := props.Get(macroArg(args, 0))
+		return v
+	},
+	"n": func(m *macros, args []string) string {
+		name, action := macroArg(args, 0), macroArg(args, 1)
+		switch n, err := strconv.Atoi(action); {
+		case action == "-":
+		case err == nil:
+			m.counters[name] = n
+		case action != "":
+			m.counters[name] = 1
+		default:
+			m.counters[name]++
+		}
+		return strconv.Itoa(m.counters[name])
+	},
+}
+
+// macros is the state while expanding macros of a document.
+type macros struct {
+	nodes    []Node
+	env      MacroEnv
+	defs     map[string]string
+	keywords map[string][]string
+	counters map[string]int
+	// entry is the index of the headline which contains the current text. It
+	// is -1 before the first headline.
+	entry int
+}
+
+func newMacros(nodes []Node, env MacroEnv) *macros {
+	m := &macros{
+		nodes:    nodes,
+		env:      env,
+		defs:     make(map[string]string),
+		keywords: make(map[string][]string),
+		counters: make(map[string]int),
+		entry:    -1,
+	}
+	for _, node := range nodes {
+		k, ok := node.(Keyword)
+		if !ok {
+			continue
+		}
+		m.keywords[k.Key] = append(m.keywords[k.Key], k.Value)
+		if k.Key == string(MacroKey) {
+			fields := strings.SplitN(k.Value, " ", 2)
+			if len(fields) == 1 {
+				fields = append(fields, "")
+			}
+			m.defs[fields[0]] = strings.TrimSpace(fields[1])
+		}
+	}
+	return m
+}
+
+// defined reports whether the macro is defined by #+MACRO or built-in.
+func (m *macros) defined(name string) bool {
+	if _, ok := m.defs[name]; ok {
+		return true
+	}
+	_, ok := builtinMacros[name]
+	return ok
+}
+
+// keyword returns the values of the keyword joined with spaces.
+func (m *macros) keyword(key string) string {
+	return strings.Join(m.keywords[key], " ")
+}
+
+// expand expands the macros in the text. stack is the names of the macros
+// which are being expanded to detect recursion. Undefined macros are left.
+func (m *macros) expand(pos Pos, text string, stack []string) (string, error) {
+	var (
+		b    strings.Builder
+		last int
+	)
+	for _, loc := range macroRegexp.FindAllStringSubmatchIndex(text, -1) {
+		b.WriteString(text[last:loc[0]])
+		last = loc[1]
+
+		name := text[loc[2]:loc[3]]
+		var args []string
+		if loc[4] >= 0 {
+			args = splitMacroArgs(text[loc[4]:loc[5]])
+		}
+		def, ok := m.defs[name]
+		if !ok {
+			if fn, ok := builtinMacros[name]; ok {
+				b.WriteString(fn(m, args))
+			} else {
+				b.WriteString(text[loc[0]:loc[1]])
+			}
+			continue
+		}
+		for _, s := range stack {
+			if s == name {
+				return "", fmt.Errorf("macro %q at line %d is recursive", name, pos.Line)
+			}
+		}
+		value, err := m.expand(pos, substituteMacroArgs(def, args), append(stack, name))
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(value)
+	}
+	b.WriteString(text[last:])
+	return b.String(), nil
+}
+
+var macroPlaceholderRegexp = regexp.MustCompile(`\$(\d+)`)
+
+// substituteMacroArgs replaces $1..$n of the definition with the arguments.
+// Missing arguments are empty.
+func substituteMacroArgs(def string, args []string) string {
+	return macroPlaceholderRegexp.ReplaceAllStringFunc(def, func(s string) string {
+		n, _ := strconv.Atoi(s[1:])
+		return macroArg(args, n-1)
+	})
+}
+
+// splitMacroArgs splits the arguments by commas. Escaped commas `\,` are not
+// separators, and the arguments are trimmed.
+func splitMacroArgs(s string) []string {
+	var (
+		args []string
+		arg  strings.Builder
+	)
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && i+1 < len(s) && s[i+1] == ',':
+			arg.WriteByte(',')
+			i++
+		case s[i] == ',':
+			args = append(args, strings.TrimSpace(arg.String()))
+			arg.Reset()
+		default:
+			arg.WriteByte(s[i])
+		}
+	}
+	return append(args, strings.TrimSpace(arg.String()))
+}
+
+func macroArg(args []string, i int) string {
+	if i < 0 || i >= len(args) {
+		return ""
+	}
+	return args[i]
+}
+
+// ExpandMacros returns the nodes whose macros such as `{{{title}}}` are
+// expanded with the #+MACRO definitions and the built-in macros. Undefined
+// macros are left, which are reported by Diagnose. It returns an error if a
+// macro refers to itself. HTMLWriter and WriteMarkdown call it before
+// writing.
+func ExpandMacros(nodes []Node, env MacroEnv) ([]Node, error) {
+	m := newMacros(nodes, env)
+	ret := make([]Node, len(nodes))
+	for i := range nodes {
+		if _, ok := nodes[i].(Headline); ok {
+			m.entry = i
+		}
+		node, err := mapInline(nodes[i:i+1], func(pos Pos, text string) (string, error) {
+			return m.expand(pos, text, nil)
+		})
+		if err != nil {
+			return nil, err
+		}
+		ret[i] = node[0]
+	}
+	return ret, nil
+}
+
+// macroDiagnostics reports references to undefined macros.
+func macroDiagnostics(nodes []Node) []Diagnostic {
+	m := newMacros(nodes, MacroEnv{})
+	var diags []Diagnostic
+	mapInline(nodes, func(pos Pos, text string) (string, error) {
+		for _, match := range macroRegexp.FindAllStringSubmatch(text, -1) {
+			if !m.defined(match[1]) {
+				diags = append(diags, Diagnostic{Pos: pos, Message: fmt.Sprintf("macro %q is not defined", match[1])})
+			}
+		}
+		return text, nil
+	})
+	return diags
+}
+
+// formatTime formats the time with the format of strftime such as `%Y-%m-%d`,
+// which is used by Org mode. Unknown conversions are written as is.
+func formatTime(format string, t time.Time) string {
+	var b strings.Builder
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' || i+1 == len(format) {
+			b.WriteByte(format[i])
+			continue
+		}
+		i++
+		switch c := format[i]; c {
+		case 'Y':
+			b.WriteString(t.Format("2006"))
+		case 'y':
+			b.WriteString(t.Format("06"))
+		case 'm':
+			b.WriteString(t.Format("01"))
+		case 'd':
+			b.WriteString(t.Format("02"))
+		case 'e':
+			b.WriteString(t.Format("_2"))
+		case 'H':
+			b.WriteString(t.Format("15"))
+		case 'I':
+			b.WriteString(t.Format("03"))
+		case 'M':
+			b.WriteString(t.Format("04"))
+		case 'S':
+			b.WriteString(t.Format("05"))
+		case 'p':
+			b.WriteString(t.Format("PM"))
+		case 'a':
+			b.WriteString(t.Format("Mon"))
+		case 'A':
+			b.WriteString(t.Format("Monday"))
+		case 'b', 'h':
+			b.WriteString(t.Format("Jan"))
+		case 'B':
+			b.WriteString(t.Format("January"))
+		case 'Z':
+			b.WriteString(t.Format("MST"))
+		case 'z':
+			b.WriteString(t.Format("-0700"))
+		case 'j':
+			fmt.Fprintf(&b, "%03d", t.YearDay())
+		case 'F':
+			b.WriteString(t.Format("2006-01-02"))
+		case 'T':
+			b.WriteString(t.Format("15:04:05"))
+		case 'R':
+			b.WriteString(t.Format("15:04"))
+		case 's':
+			b.WriteString(strconv.FormatInt(t.Unix(), 10))
+		case '%':
+			b.WriteByte('%')
+		default:
+			b.WriteByte('%')
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,86 @@
+package org_test
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	. "github.com/Ladicle/org2html/org"
+)
+
+func TestExpandMacros(t *testing.T) {
+	var tests = []struct {
+		desc      string
+		input     string
+		env       MacroEnv
+		wantOut   string
+		wantError error
+	}{
+		{
+			desc:    "definitions",
+			input:   "#+MACRO: greet Hello, $1 and $2!\n#+MACRO: nested <{{{greet(x,y)}}}>\n{{{greet(A\\, B, C)}}} {{{greet}}}\n\n- {{{nested}}} {{{unknown(a)}}}\n",
+			wantOut: "#+macro: greet Hello, $1 and $2!\n#+macro: nested <{{{greet(x,y)}}}>\n\nHello, A, B and C! Hello,  and !\n\n- <Hello, x and y!> {{{unknown(a)}}}\n",
+		},
+		{
+			desc:  "built-in macros",
+			input: "#+TITLE: Notes\n#+AUTHOR: Alice\n#+DATE: <2022-01-30 Sun>\n#+EMAIL: a@example.com\n| {{{title}}} | {{{author}}} | {{{date}}} | {{{date(%Y/%m/%d)}}} |\n| {{{time(%F %T)}}} | {{{modification-time(%b %e %Y)}}} | {{{input-file}}} | {{{keyword(email)}}} |\n",
+			env: MacroEnv{
+				InputFile: "/notes/index.org",
+				ModTime:   time.Date(2022, 2, 3, 4, 5, 6, 0, time.UTC),
+				Now:       time.Date(2022, 3, 4, 5, 6, 7, 0, time.UTC),
+			},
+			wantOut: "#+title: Notes\n#+author: Alice\n#+date: <2022-01-30 Sun>\n#+email: a@example.com\n\n" +
+				"| Notes               | Alice       | <2022-01-30 Sun> | 2022/01/30    |\n" +
+				"| 2022-03-04 05:06:07 | Feb  3 2022 | index.org        | a@example.com |\n",
+		},
+		{
+			desc:    "zero modification time",
+			input:   "[{{{modification-time(%Y)}}}]\n",
+			wantOut: "[]\n",
+		},
+		{
+			desc:    "properties and counters",
+			input:   "{{{property(ID)}}}\n* Chapter {{{n}}}\n:PROPERTIES:\n:ID: one\n:END:\n{{{property(id)}}} {{{n(fig)}}} {{{n(fig)}}} {{{n(fig,-)}}} {{{n(fig,10)}}} {{{n(fig,reset)}}}\n* Chapter {{{n}}}\n{{{property(ID)}}}\n",
+			wantOut: "\n\n* Chapter 1\n:PROPERTIES:\n:ID:       one\n:END:\n\none 1 2 2 10 1\n\n* Chapter 2\n\n\n",
+		},
+		{
+			desc:      "recursive macros",
+			input:     "#+MACRO: a {{{b}}}\n#+MACRO: b {{{a}}}\n\n{{{a}}}\n",
+			wantError: errors.New(`macro "a" at line 4 is recursive`),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			nodes, err := ExpandMacros(parseString(t, tt.input), tt.env)
+			if err != nil {
+				if tt.wantError == nil || err.Error() != tt.wantError.Error() {
+					t.Fatalf("unexpected error: err=%v, want=%v", err, tt.wantError)
+				}
+				return
+			} else if tt.wantError != nil {
+				t.Fatalf("expect error but not occurred: want=%v", tt.wantError)
+			}
+			var out bytes.Buffer
+			if err := DefaultOrgWriter().Write(nodes, &out); err != nil {
+				t.Fatalf("unexpected error: err=%v", err)
+			}
+			if got := out.String(); got != tt.wantOut {
+				t.Errorf("unexpected output:\ngot=%q\nwant=%q", got, tt.wantOut)
+			}
+		})
+	}
+}
+
+func TestDiagnoseMacros(t *testing.T) {
+	nodes := parseString(t, "#+MACRO: defined x\n{{{defined}}} {{{title}}}\n\n* {{{undefined(a)}}}\n")
+	want := []string{`4: macro "undefined" is not defined`}
+	var got []string
+	for _, d := range Diagnose(nodes) {
+		got = append(got, d.String())
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected diagnostics:\ngot=%#v\nwant=%#v", got, want)
+	}
+}
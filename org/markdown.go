@@ -0,0 +1,292 @@
+package org
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteMarkdown writes nodes as a CommonMark document with the GitHub
+// Flavored Markdown extensions (tables) to the specified writer. Macros are
+// expanded with ExpandMacros.
+func WriteMarkdown(nodes []Node, out io.Writer) error {
+	nodes, err := ExpandMacros(nodes, MacroEnv{})
+	if err != nil {
+		return err
+	}
+	return writeMarkdownNodes(nodes, out)
+}
+
+// writeMarkdownNodes writes the nodes whose macros are already expanded, such
+// as the children of blocks.
+func writeMarkdownNodes(nodes []Node, out io.Writer) error {
+	var (
+		buf   bytes.Buffer
+		first = true
+	)
+	for i := range nodes {
+		buf.Reset()
+		if err := writeMarkdown(&buf, nodes[i]); err != nil {
+			return err
+		}
+		if buf.Len() == 0 {
+			continue
+		}
+		// markdown blocks must be separated by a blank line.
+		if !first {
+			fmt.Fprintln(out)
+		}
+		first = false
+		if _, err := buf.WriteTo(out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeMarkdown(w io.Writer, node Node) error {
+	switch n := node.(type) {
+	case Headline:
+		return writeMarkdownHeadline(w, n)
+	case Section:
+		var defs []string
+		for i := range n.Paragraphs {
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			fmt.Fprintln(w, markdownMath(markdownFootnoteRefs(joinLines(n.Paragraphs[i]), n.Pos, &defs)))
+		}
+		// inline footnote definitions are placed after the paragraphs.
+		if len(defs) > 0 {
+			fmt.Fprintf(w, "\n%s\n", strings.Join(defs, "\n"))
+		}
+	case FootnoteDefinition:
+		var buf bytes.Buffer
+		if err := writeMarkdownNodes(n.Children, &buf); err != nil {
+			return err
+		}
+		for i, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+			switch {
+			case i == 0:
+				fmt.Fprintf(w, "[^%s]: %s\n", n.Label, line)
+			case line == "":
+				fmt.Fprintln(w)
+			default:
+				// continuation blocks of the footnote are indented.
+				fmt.Fprintln(w, "    "+line)
+			}
+		}
+	case SourceBlock:
+		return writeMarkdownSourceBlock(w, n)
+	case Results:
+		for i := range n.Value {
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			if err := writeMarkdown(w, n.Value[i]); err != nil {
+				return err
+			}
+		}
+	case FixedWidth:
+		writeMarkdownFence(w, "", n.Content)
+	case LatexEnvironment:
+		fmt.Fprintf(w, "$$\n%s\n$$\n", n.Content)
+	case Block:
+		return writeMarkdownBlock(w, n)
+	case Agenda:
+		writeMarkdownAgenda(w, n)
+	case List:
+		writeMarkdownList(w, n, "")
+	case Table:
+		writeMarkdownTable(w, n)
+	case Comment:
+		if n.Message != "" {
+			fmt.Fprintf(w, "<!-- %s -->\n", n.Message)
+		}
+	case Drawer:
+		if n.Name != logbookDrawerName {
+			return writeMarkdownNodes(n.Children, w)
+		}
+	case DynamicBlock:
+		return writeMarkdownNodes(n.Children, w)
+	case Keyword, PropertyDrawer, Clock:
+		// noop
+	default:
+		return fmt.Errorf("markdown does not support the node: %T", node)
+	}
+	return nil
+}
+
+// markdownFootnoteRefs converts footnote references to the GFM footnotes such
+// as `[^label]`. Inline definitions are appended to defs, and anonymous
+// footnotes are labelled by the position of the paragraph.
+func markdownFootnoteRefs(text string, pos Pos, defs *[]string) string {
+	return footnoteRefRegexp.ReplaceAllStringFunc(text, func(ref string) string {
+		m := footnoteRefRegexp.FindStringSubmatch(ref)
+		label, def := m[1], strings.TrimSpace(m[2])
+		if label == "" {
+			if def == "" {
+				return ref
+			}
+			label = fmt.Sprintf("anonymous-%d-%d", pos.Line, len(*defs)+1)
+		}
+		if def != "" {
+			*defs = append(*defs, fmt.Sprintf("[^%s]: %s", label, def))
+		}
+		return "[^" + label + "]"
+	})
+}
+
+func writeMarkdownHeadline(w io.Writer, h Headline) error {
+	if h.Starts == 0 {
+		return fmt.Errorf("invalid number of starts: %#v", h)
+	}
+	if h.Title == "" {
+		return fmt.Errorf("title is empty: %#v", h)
+	}
+	fmt.Fprint(w, strings.Repeat("#", h.Starts), " ")
+	if h.Keyword != "" {
+		fmt.Fprint(w, h.Keyword, " ")
+	}
+	if h.Priority != "" {
+		fmt.Fprintf(w, "[#%s] ", h.Priority)
+	}
+	fmt.Fprintln(w, h.Title)
+	return nil
+}
+
+// writeMarkdownFence writes the code as a fenced code block. The fence is
+// longer than any backtick sequence in the code.
+func writeMarkdownFence(w io.Writer, lang, code string) {
+	fence := "```"
+	for strings.Contains(code, fence) {
+		fence += "`"
+	}
+	fmt.Fprintf(w, "%s%s\n", fence, lang)
+	if code != "" {
+		fmt.Fprintln(w, code)
+	}
+	fmt.Fprintln(w, fence)
+}
+
+// writeMarkdownSourceBlock writes the source block and its results according
+// to the :exports and :details header arguments. Markdown allows the raw
+// details element.
+func writeMarkdownSourceBlock(w io.Writer, c SourceBlock) error {
+	args := c.HeaderArgs()
+	code := args.exportsCode()
+	if code {
+		details := args.enabled("details")
+		if details {
+			fmt.Fprintf(w, "<details>\n<summary>%s</summary>\n\n", detailsSummary(args, c.Language))
+		}
+		writeMarkdownFence(w, c.Language, c.SourceCode)
+		if details {
+			fmt.Fprintln(w, "\n</details>")
+		}
+	}
+	if !args.exportsResults() || c.Results == nil || len(c.Results.Value) == 0 {
+		return nil
+	}
+	if code {
+		fmt.Fprintln(w)
+	}
+	return writeMarkdown(w, *c.Results)
+}
+
+func writeMarkdownBlock(w io.Writer, b Block) error {
+	switch b.Name {
+	case "QUOTE":
+		var buf bytes.Buffer
+		if err := writeMarkdownNodes(b.Children, &buf); err != nil {
+			return err
+		}
+		for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+			fmt.Fprintln(w, strings.TrimRight("> "+line, " "))
+		}
+	case "EXAMPLE":
+		writeMarkdownFence(w, "", b.Content)
+	case "VERSE":
+		// a backslash at the end of line is a hard line break.
+		fmt.Fprintln(w, strings.ReplaceAll(b.Content, "\n", "\\\n"))
+	case "EXPORT":
+		// Markdown allows raw HTML.
+		switch strings.ToLower(b.Parameters) {
+		case "md", "markdown", "html":
+			fmt.Fprintln(w, b.Content)
+		}
+	case "COMMENT":
+		// noop
+	default:
+		return writeMarkdownNodes(b.Children, w)
+	}
+	return nil
+}
+
+func writeMarkdownAgenda(w io.Writer, a Agenda) {
+	var items []string
+	for _, key := range []AgendaKey{AgendaClosed, AgendaDeadline, AgendaScheduled} {
+		if log, ok := a.Logs[key]; ok {
+			items = append(items, fmt.Sprintf("%v: %v", key, log))
+		}
+	}
+	if len(items) > 0 {
+		fmt.Fprintln(w, strings.Join(items, " "))
+	}
+}
+
+func writeMarkdownList(w io.Writer, l List, indent string) {
+	for i, item := range l.Items {
+		bullet := "-"
+		if l.Ordered {
+			bullet = fmt.Sprintf("%d.", i+1)
+		}
+		// continuation lines and sublists are indented to the content column
+		// of the item.
+		content := indent + strings.Repeat(" ", len(bullet)+1)
+		fmt.Fprintf(w, "%s%s %s\n", indent, bullet, strings.ReplaceAll(item.Content, "\n", "\n"+content))
+		if item.Sublist != nil {
+			writeMarkdownList(w, *item.Sublist, content)
+		}
+	}
+}
+
+// writeMarkdownTable writes the table as a GFM table. GFM tables always have
+// one header row, so the table without header gets an empty header.
+func writeMarkdownTable(w io.Writer, t Table) {
+	var (
+		header = t.header()
+		rows   [][]string
+		cols   int
+	)
+	for _, row := range t.Rows {
+		if row == nil {
+			continue
+		}
+		rows = append(rows, row)
+		if len(row) > cols {
+			cols = len(row)
+		}
+	}
+	if len(rows) == 0 {
+		return
+	}
+	if header == 0 {
+		rows = append([][]string{nil}, rows...)
+	}
+	for i, row := range rows {
+		fmt.Fprint(w, "|")
+		for j := 0; j < cols; j++ {
+			var cell string
+			if j < len(row) {
+				cell = strings.ReplaceAll(row[j], "|", `\|`)
+			}
+			fmt.Fprintf(w, " %s |", cell)
+		}
+		fmt.Fprintln(w)
+		if i == 0 {
+			fmt.Fprintln(w, "|"+strings.Repeat(" --- |", cols))
+		}
+	}
+}
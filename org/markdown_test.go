@@ -0,0 +1,133 @@
+package org_test
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/Ladicle/org2html/org"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func TestWriteMarkdown(t *testing.T) {
+	var tests = []struct {
+		desc    string
+		nodes   []Node
+		wantOut string
+	}{
+		{
+			desc:  "no node",
+			nodes: []Node{},
+		},
+		{
+			desc: "headline with meta",
+			nodes: []Node{
+				Headline{Starts: 2, Title: "this is test headline", Keyword: "TODO", Priority: "A", Tags: []string{"tag"}},
+			},
+			wantOut: "## TODO [#A] this is test headline\n",
+		},
+		{
+			desc: "macros expanded once",
+			nodes: []Node{
+				Keyword{Key: "RAW", Value: "{{{title}}}"},
+				Keyword{Key: "TITLE", Value: "title"},
+				Block{Name: "QUOTE", Children: Nodes{Section{Paragraphs: []string{"{{{keyword(raw)}}}"}}}},
+			},
+			wantOut: "> {{{title}}}\n",
+		},
+		{
+			desc: "separated by blank line",
+			nodes: []Node{
+				Keyword{Key: "TITLE", Value: "title"},
+				Section{Paragraphs: []string{"paragraph1", "paragraph2"}},
+				Keyword{Key: "AUTHOR", Value: "author"},
+				Comment{Message: "comment"},
+			},
+			wantOut: "paragraph1\n\nparagraph2\n\n<!-- comment -->\n",
+		},
+		{
+			desc:    "source block with backticks",
+			nodes:   []Node{SourceBlock{Language: "markdown", SourceCode: "```go\n```"}},
+			wantOut: "````markdown\n```go\n```\n````\n",
+		},
+		{
+			desc:    "quote block",
+			nodes:   []Node{Block{Name: "QUOTE", Children: Nodes{Section{Paragraphs: []string{"hello", "world"}}}}},
+			wantOut: "> hello\n>\n> world\n",
+		},
+		{
+			desc: "nested list",
+			nodes: []Node{List{Items: []ListItem{
+				{Bullet: "-", Content: "item1", Sublist: &List{
+					Ordered: true,
+					Items:   []ListItem{{Bullet: "1)", Content: "sub1"}, {Bullet: "1)", Content: "sub2"}},
+				}},
+				{Bullet: "-", Content: "item2"},
+			}}},
+			wantOut: "- item1\n  1. sub1\n  2. sub2\n- item2\n",
+		},
+		{
+			desc:    "table without header",
+			nodes:   []Node{Table{Rows: [][]string{{"a", "b|c"}, {"d"}}}},
+			wantOut: "|  |  |\n| --- | --- |\n| a | b\\|c |\n| d |  |\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := WriteMarkdown(tt.nodes, &out); err != nil {
+				t.Fatalf("unexpected error: err=%v", err)
+			}
+			if got := out.String(); got != tt.wantOut {
+				t.Errorf("unexpected output:\ngot=%q\nwant=%q", got, tt.wantOut)
+			}
+		})
+	}
+}
+
+func TestWriteMarkdownGolden(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "markdown", "*.org"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			f, err := os.Open(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+
+			tokens, err := DefaultTokenizer().Tokenize(f)
+			if err != nil {
+				t.Fatalf("fail to tokenize: err=%v", err)
+			}
+			nodes, err := DefaultParser(tokens).Parse()
+			if err != nil {
+				t.Fatalf("fail to parse: err=%v", err)
+			}
+			var out bytes.Buffer
+			if err := WriteMarkdown(nodes, &out); err != nil {
+				t.Fatalf("unexpected error: err=%v", err)
+			}
+
+			golden := strings.TrimSuffix(file, ".org") + ".md"
+			if *update {
+				if err := os.WriteFile(golden, out.Bytes(), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := out.String(); got != string(want) {
+				t.Errorf("unexpected output:\ngot=%v\nwant=%v", got, string(want))
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package org
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+var _ Node = LatexEnvironment{}
+
+// LatexEnvironment is a Node to describe LaTeX environments such as
+// `\begin{equation}...\end{equation}`. Content includes the begin and end
+// lines.
+type LatexEnvironment struct {
+	Pos     Pos    `json:"pos"`
+	Name    string `json:"name"`
+	Content string `json:"content"`
+}
+
+func (e LatexEnvironment) Write(w io.Writer) error {
+	return e.writeHTML(w, &htmlContext{HTMLWriter: DefaultHTMLWriter()})
+}
+
+func (e LatexEnvironment) writeHTML(w io.Writer, ctx *htmlContext) error {
+	fmt.Fprintln(w, `<div class="math display">`)
+	ctx.writeMath(w, e.Content, e.Content, true)
+	fmt.Fprintln(w, "\n</div>")
+	return nil
+}
+
+// MathRenderer converts LaTeX math to HTML such as MathML.
+type MathRenderer interface {
+	// RenderMath returns the HTML of the math without delimiters. Display is
+	// true for display math and environments. It returns false if the math is
+	// not supported.
+	RenderMath(tex string, display bool) (string, bool)
+}
+
+// writeMath writes the math with the MathRenderer. The math is written with
+// the delimiters for client-side MathJax or KaTeX if the renderer is nil or
+// does not support it.
+func (ctx *htmlContext) writeMath(w io.Writer, tex, delimited string, display bool) {
+	if ctx.MathRenderer != nil {
+		if html, ok := ctx.MathRenderer.RenderMath(tex, display); ok {
+			io.WriteString(w, html)
+			return
+		}
+	}
+	io.WriteString(w, codeEscaper.Replace(delimited))
+}
+
+var (
+	latexBeginRegexp = regexp.MustCompile(`^\s*\\begin\{([A-Za-z]+\*?)\}`)
+
+	// inline math fragments. `$...$` must not start or end with spaces, so
+	// prices such as `$5 and $10` are not math.
+	mathParenRegexp   = regexp.MustCompile(`(?s)\\\((.+?)\\\)`)
+	mathBracketRegexp = regexp.MustCompile(`(?s)\\\[(.+?)\\\]`)
+	mathDollarsRegexp = regexp.MustCompile(`(?s)\$\$(.+?)\$\$`)
+	mathDollarRegexp  = regexp.MustCompile(`\$([^\s$](?:[^$]*[^\s$])?)\$`)
+)
+
+// LexLatexEnvironment lexes the first line of LaTeX environments.
+func LexLatexEnvironment(line string) (Token, bool) {
+	if m := latexBeginRegexp.FindStringSubmatch(line); m != nil {
+		return NewToken(KindLatexEnvironment, 1, m[1:]), true
+	}
+	return Token{}, false
+}
+
+// ParseLatexEnvironment parses the LaTeX environment until the line which
+// ends with `\end{name}`. The unclosed environment is a paragraph.
+func ParseLatexEnvironment(p *Parser, i int) (int, Node, error) {
+	if len(p.tokens[i].vals) != 1 {
+		return 0, nil, fmt.Errorf("latex token[%d] does not have 1 value: got=%d", i, len(p.tokens[i].vals))
+	}
+	name := p.tokens[i].vals[0]
+	end := `\end{` + name + `}`
+	for j := i; j < len(p.tokens); j++ {
+		if j > i && p.tokens[j].kind == KindHeadline {
+			break
+		}
+		if strings.HasSuffix(strings.TrimSpace(p.tokens[j].text()), end) {
+			return j - i + 1, LatexEnvironment{
+				Pos:     p.tokens[i].pos,
+				Name:    name,
+				Content: blockContent(p.tokens[i : j+1]),
+			}, nil
+		}
+	}
+	return 1, Section{Pos: p.tokens[i].pos, Paragraphs: []string{strings.TrimSpace(p.tokens[i].text())}}, nil
+}
+
+func writeInlineMath(w io.Writer, ctx *htmlContext, m []string) {
+	fmt.Fprint(w, `<span class="math inline">`)
+	ctx.writeMath(w, m[1], `\(`+m[1]+`\)`, false)
+	fmt.Fprint(w, "</span>")
+}
+
+func writeDisplayMath(w io.Writer, ctx *htmlContext, m []string) {
+	fmt.Fprint(w, `<span class="math display">`)
+	ctx.writeMath(w, m[1], `\[`+m[1]+`\]`, true)
+	fmt.Fprint(w, "</span>")
+}
+
+// markdownMath converts `\(...\)` and `\[...\]` to `$...$` and `$$...$$`
+// because Markdown removes backslashes before punctuation.
+func markdownMath(text string) string {
+	text = mathBracketRegexp.ReplaceAllString(text, "$$$$$1$$$$")
+	return mathParenRegexp.ReplaceAllString(text, "$$$1$$")
+}
@@ -0,0 +1,110 @@
+package org_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	. "github.com/Ladicle/org2html/org"
+)
+
+func TestParseLatexEnvironment(t *testing.T) {
+	var tests = []struct {
+		desc      string
+		input     string
+		wantNodes []Node
+	}{
+		{
+			desc:  "environment",
+			input: "\\begin{align*}\na &= b \\\\\n\nc &= d\n\\end{align*}\nafter\n",
+			wantNodes: []Node{
+				LatexEnvironment{Name: "align*", Content: "\\begin{align*}\na &= b \\\\\n\nc &= d\n\\end{align*}"},
+				Section{Paragraphs: []string{"after"}},
+			},
+		},
+		{
+			desc:  "one line",
+			input: "  \\begin{equation} E = mc^2 \\end{equation}\n",
+			wantNodes: []Node{
+				LatexEnvironment{Name: "equation", Content: "\\begin{equation} E = mc^2 \\end{equation}"},
+			},
+		},
+		{
+			desc:  "unclosed environment",
+			input: "\\begin{equation}\nx\n* headline\n",
+			wantNodes: []Node{
+				Section{Paragraphs: []string{"\\begin{equation}"}},
+				Section{Paragraphs: []string{"x"}},
+				Headline{Starts: 1, Title: "headline"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			if got := withoutPos(parseString(t, tt.input)); !reflect.DeepEqual(got, tt.wantNodes) {
+				t.Errorf("unexpected nodes:\ngot=%#v\nwant=%#v", got, tt.wantNodes)
+			}
+		})
+	}
+}
+
+func TestMathWriter(t *testing.T) {
+	var tests = []struct {
+		desc    string
+		input   string
+		writer  HTMLWriter
+		wantOut string
+	}{
+		{
+			desc:    "delimited fragments",
+			input:   "\\(a<b\\), $x$, $$y$$ and \\[z\\] but $5 and $10\n",
+			wantOut: `<p><span class="math inline">\(a&lt;b\)</span>, <span class="math inline">\(x\)</span>, <span class="math display">\[y\]</span> and <span class="math display">\[z\]</span> but $5 and $10</p>` + "\n",
+		},
+		{
+			desc:    "delimited environment",
+			input:   "\\begin{equation}\nx < y\n\\end{equation}\n",
+			wantOut: "<div class=\"math display\">\n\\begin{equation}\nx &lt; y\n\\end{equation}\n</div>\n",
+		},
+		{
+			desc:   "mathml",
+			input:  "\\(\\frac{a}{\\sqrt{x}}\\) $x_1^2$ $$\\sum_{i=1}^n i$$ \\(\\unknown\\)\n",
+			writer: HTMLWriter{MathRenderer: DefaultMathRenderer()},
+			wantOut: `<p><span class="math inline"><math xmlns="http://www.w3.org/1998/Math/MathML"><mfrac><mi>a</mi><msqrt><mi>x</mi></msqrt></mfrac></math></span> ` +
+				`<span class="math inline"><math xmlns="http://www.w3.org/1998/Math/MathML"><msubsup><mi>x</mi><mn>1</mn><mn>2</mn></msubsup></math></span> ` +
+				`<span class="math display"><math xmlns="http://www.w3.org/1998/Math/MathML" display="block"><mrow><munderover><mo>∑</mo><mrow><mi>i</mi><mo>=</mo><mn>1</mn></mrow><mi>n</mi></munderover><mi>i</mi></mrow></math></span> ` +
+				`<span class="math inline">\(\unknown\)</span></p>` + "\n",
+		},
+		{
+			desc:   "mathml matrix",
+			input:  "\\begin{pmatrix} a & b \\\\ c & d \\end{pmatrix}\n",
+			writer: HTMLWriter{MathRenderer: DefaultMathRenderer()},
+			wantOut: "<div class=\"math display\">\n" +
+				`<math xmlns="http://www.w3.org/1998/Math/MathML" display="block"><mrow><mo fence="true">(</mo><mtable>` +
+				`<mtr><mtd><mi>a</mi></mtd><mtd><mi>b</mi></mtd></mtr><mtr><mtd><mi>c</mi></mtd><mtd><mi>d</mi></mtd></mtr>` +
+				`</mtable><mo fence="true">)</mo></mrow></math>` + "\n</div>\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := tt.writer.Write(parseString(t, tt.input), &out); err != nil {
+				t.Fatalf("unexpected error: err=%v", err)
+			}
+			if got := out.String(); got != tt.wantOut {
+				t.Errorf("unexpected output:\ngot=%v\nwant=%v", got, tt.wantOut)
+			}
+		})
+	}
+}
+
+func TestWriteMarkdownMath(t *testing.T) {
+	input := "\\(x\\) and \\[y\\]\n\n\\begin{equation}\nz\n\\end{equation}\n"
+	want := "$x$ and $$y$$\n\n$$\n\\begin{equation}\nz\n\\end{equation}\n$$\n"
+	var out bytes.Buffer
+	if err := WriteMarkdown(parseString(t, input), &out); err != nil {
+		t.Fatalf("unexpected error: err=%v", err)
+	}
+	if got := out.String(); got != want {
+		t.Errorf("unexpected output:\ngot=%q\nwant=%q", got, want)
+	}
+}
@@ -0,0 +1,453 @@
+package org
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultMathRenderer returns the built-in MathRenderer which converts a
+// subset of TeX to MathML, so pages can render math without JavaScript. It
+// supports fractions, roots, scripts, Greek letters, common symbols, text,
+// accents, delimiters and the equation, align, cases and matrix environments.
+func DefaultMathRenderer() MathRenderer {
+	return mathMLRenderer{}
+}
+
+type mathMLRenderer struct{}
+
+func (mathMLRenderer) RenderMath(tex string, display bool) (string, bool) {
+	p := &texParser{src: tex, display: display}
+	row, err := p.parseRow(nil)
+	if err != nil || p.pos < len(p.src) {
+		return "", false
+	}
+	attr := ""
+	if display {
+		attr = ` display="block"`
+	}
+	return fmt.Sprintf(`<math xmlns="http://www.w3.org/1998/Math/MathML"%s>%s</math>`, attr, mrow(row)), true
+}
+
+// texSymbols maps commands to the MathML elements of the symbols.
+var texSymbols = map[string][2]string{
+	"alpha": {"mi", "α"}, "beta": {"mi", "β"}, "gamma": {"mi", "γ"}, "delta": {"mi", "δ"},
+	"epsilon": {"mi", "ϵ"}, "varepsilon": {"mi", "ε"}, "zeta": {"mi", "ζ"}, "eta": {"mi", "η"},
+	"theta": {"mi", "θ"}, "vartheta": {"mi", "ϑ"}, "iota": {"mi", "ι"}, "kappa": {"mi", "κ"},
+	"lambda": {"mi", "λ"}, "mu": {"mi", "μ"}, "nu": {"mi", "ν"}, "xi": {"mi", "ξ"},
+	"pi": {"mi", "π"}, "varpi": {"mi", "ϖ"}, "rho": {"mi", "ρ"}, "varrho": {"mi", "ϱ"},
+	"sigma": {"mi", "σ"}, "varsigma": {"mi", "ς"}, "tau": {"mi", "τ"}, "upsilon": {"mi", "υ"},
+	"phi": {"mi", "ϕ"}, "varphi": {"mi", "φ"}, "chi": {"mi", "χ"}, "psi": {"mi", "ψ"},
+	"omega": {"mi", "ω"},
+	"Gamma": {"mi", "Γ"}, "Delta": {"mi", "Δ"}, "Theta": {"mi", "Θ"}, "Lambda": {"mi", "Λ"},
+	"Xi": {"mi", "Ξ"}, "Pi": {"mi", "Π"}, "Sigma": {"mi", "Σ"}, "Upsilon": {"mi", "Υ"},
+	"Phi": {"mi", "Φ"}, "Psi": {"mi", "Ψ"}, "Omega": {"mi", "Ω"},
+	"infty": {"mi", "∞"}, "partial": {"mi", "∂"}, "nabla": {"mi", "∇"}, "ell": {"mi", "ℓ"},
+	"hbar": {"mi", "ℏ"}, "emptyset": {"mi", "∅"}, "aleph": {"mi", "ℵ"},
+	"sum": {"mo", "∑"}, "prod": {"mo", "∏"}, "coprod": {"mo", "∐"}, "int": {"mo", "∫"},
+	"iint": {"mo", "∬"}, "oint": {"mo", "∮"}, "bigcup": {"mo", "⋃"}, "bigcap": {"mo", "⋂"},
+	"pm": {"mo", "±"}, "mp": {"mo", "∓"}, "times": {"mo", "×"}, "div": {"mo", "÷"},
+	"cdot": {"mo", "⋅"}, "ast": {"mo", "∗"}, "circ": {"mo", "∘"}, "bullet": {"mo", "∙"},
+	"cup": {"mo", "∪"}, "cap": {"mo", "∩"}, "setminus": {"mo", "∖"}, "oplus": {"mo", "⊕"},
+	"otimes": {"mo", "⊗"}, "wedge": {"mo", "∧"}, "land": {"mo", "∧"}, "vee": {"mo", "∨"},
+	"lor": {"mo", "∨"}, "neg": {"mo", "¬"}, "lnot": {"mo", "¬"},
+	"leq": {"mo", "≤"}, "le": {"mo", "≤"}, "geq": {"mo", "≥"}, "ge": {"mo", "≥"},
+	"neq": {"mo", "≠"}, "ne": {"mo", "≠"}, "approx": {"mo", "≈"}, "equiv": {"mo", "≡"},
+	"sim": {"mo", "∼"}, "simeq": {"mo", "≃"}, "cong": {"mo", "≅"}, "propto": {"mo", "∝"},
+	"ll": {"mo", "≪"}, "gg": {"mo", "≫"}, "in": {"mo", "∈"}, "notin": {"mo", "∉"},
+	"ni": {"mo", "∋"}, "subset": {"mo", "⊂"}, "supset": {"mo", "⊃"}, "subseteq": {"mo", "⊆"},
+	"supseteq": {"mo", "⊇"}, "forall": {"mo", "∀"}, "exists": {"mo", "∃"}, "mid": {"mo", "∣"},
+	"parallel": {"mo", "∥"}, "perp": {"mo", "⊥"},
+	"to": {"mo", "→"}, "rightarrow": {"mo", "→"}, "leftarrow": {"mo", "←"}, "gets": {"mo", "←"},
+	"leftrightarrow": {"mo", "↔"}, "Rightarrow": {"mo", "⇒"}, "Leftarrow": {"mo", "⇐"},
+	"Leftrightarrow": {"mo", "⇔"}, "implies": {"mo", "⟹"}, "iff": {"mo", "⟺"}, "mapsto": {"mo", "↦"},
+	"ldots": {"mo", "…"}, "cdots": {"mo", "⋯"}, "vdots": {"mo", "⋮"}, "ddots": {"mo", "⋱"},
+	"langle": {"mo", "⟨"}, "rangle": {"mo", "⟩"}, "lfloor": {"mo", "⌊"}, "rfloor": {"mo", "⌋"},
+	"lceil": {"mo", "⌈"}, "rceil": {"mo", "⌉"}, "vert": {"mo", "|"}, "Vert": {"mo", "‖"},
+	"{": {"mo", "{"}, "}": {"mo", "}"}, "|": {"mo", "‖"}, "%": {"mo", "%"}, "#": {"mo", "#"},
+	"&": {"mo", "&"}, "$": {"mo", "$"}, "_": {"mo", "_"},
+}
+
+// texFunctions are the commands written as upright identifiers.
+var texFunctions = map[string]bool{
+	"sin": true, "cos": true, "tan": true, "cot": true, "sec": true, "csc": true,
+	"arcsin": true, "arccos": true, "arctan": true, "sinh": true, "cosh": true, "tanh": true,
+	"log": true, "ln": true, "lg": true, "exp": true, "det": true, "dim": true, "ker": true,
+	"deg": true, "gcd": true, "arg": true, "max": true, "min": true, "sup": true, "inf": true,
+	"lim": true, "limsup": true, "liminf": true, "Pr": true,
+}
+
+// texLimits are the operators whose scripts are placed under and over them in
+// display math.
+var texLimits = map[string]bool{
+	"sum": true, "prod": true, "coprod": true, "bigcup": true, "bigcap": true,
+	"lim": true, "limsup": true, "liminf": true, "max": true, "min": true, "sup": true, "inf": true,
+}
+
+var texSpaces = map[string]string{
+	",": "0.167em", "!": "-0.167em", ":": "0.222em", ";": "0.278em", " ": "0.333em", "quad": "1em", "qquad": "2em",
+}
+
+var texAccents = map[string]string{
+	"hat": "^", "widehat": "^", "bar": "¯", "overline": "¯", "vec": "→", "dot": "˙",
+	"ddot": "¨", "tilde": "~", "widetilde": "~",
+}
+
+var texVariants = map[string]string{
+	"mathrm": "normal", "operatorname": "normal", "mathbf": "bold", "mathit": "italic",
+	"mathbb": "double-struck", "mathcal": "script", "mathfrak": "fraktur", "mathsf": "sans-serif",
+}
+
+// texMatrices maps the matrix environments to their delimiters.
+var texMatrices = map[string][2]string{
+	"matrix": {"", ""}, "pmatrix": {"(", ")"}, "bmatrix": {"[", "]"}, "Bmatrix": {"{", "}"},
+	"vmatrix": {"|", "|"}, "Vmatrix": {"‖", "‖"}, "cases": {"{", ""},
+}
+
+var errUnsupportedTeX = errors.New("unsupported TeX")
+
+// texParser converts TeX to MathML by recursive descent.
+type texParser struct {
+	src     string
+	pos     int
+	display bool
+}
+
+// peek returns the next token: a command such as `\alpha`, `\begin{name}`,
+// a number or a character. Spaces are skipped.
+func (p *texParser) peek() (tok string, end int) {
+	i := p.pos
+	for i < len(p.src) && unicode.IsSpace(rune(p.src[i])) {
+		i++
+	}
+	if i == len(p.src) {
+		return "", i
+	}
+	switch c := p.src[i]; {
+	case c == '\\':
+		j := i + 1
+		for j < len(p.src) && isASCIILetter(p.src[j]) {
+			j++
+		}
+		if j == i+1 && j < len(p.src) {
+			_, size := utf8.DecodeRuneInString(p.src[j:])
+			j += size
+		}
+		if cmd := p.src[i:j]; (cmd == `\begin` || cmd == `\end`) && strings.HasPrefix(p.src[j:], "{") {
+			if k := strings.IndexByte(p.src[j:], '}'); k >= 0 {
+				j += k + 1
+			}
+		}
+		return p.src[i:j], j
+	case '0' <= c && c <= '9':
+		j := i
+		for j < len(p.src) && ('0' <= p.src[j] && p.src[j] <= '9' || p.src[j] == '.') {
+			j++
+		}
+		return p.src[i:j], j
+	}
+	_, size := utf8.DecodeRuneInString(p.src[i:])
+	return p.src[i : i+size], i + size
+}
+
+func (p *texParser) next() string {
+	tok, end := p.peek()
+	p.pos = end
+	return tok
+}
+
+func isASCIILetter(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+// parseRow parses the elements until the end of the source or the stop
+// tokens, which are not consumed.
+func (p *texParser) parseRow(stop map[string]bool) ([]string, error) {
+	var row []string
+	for {
+		tok, _ := p.peek()
+		if tok == "" || stop[tok] {
+			return row, nil
+		}
+		if tok == "}" || strings.HasPrefix(tok, `\end{`) || tok == `\right` || tok == "&" || tok == `\\` {
+			return nil, errUnsupportedTeX
+		}
+		elem, err := p.parseScripts()
+		if err != nil {
+			return nil, err
+		}
+		row = append(row, elem)
+	}
+}
+
+// parseScripts parses the element with the subscript and superscript.
+func (p *texParser) parseScripts() (string, error) {
+	tok, _ := p.peek()
+	base, err := p.parseAtom()
+	if err != nil {
+		return "", err
+	}
+	var sub, sup string
+	for {
+		t, _ := p.peek()
+		if t != "_" && t != "^" {
+			break
+		}
+		p.next()
+		arg, err := p.parseAtom()
+		if err != nil {
+			return "", err
+		}
+		if t == "_" && sub == "" {
+			sub = arg
+		} else if t == "^" && sup == "" {
+			sup = arg
+		} else {
+			// double scripts such as x^a^b
+			return "", errUnsupportedTeX
+		}
+	}
+	under, over, both := "msub", "msup", "msubsup"
+	if p.display && texLimits[strings.TrimPrefix(tok, `\`)] {
+		under, over, both = "munder", "mover", "munderover"
+	}
+	switch {
+	case sub != "" && sup != "":
+		return fmt.Sprintf("<%s>%s%s%s</%s>", both, base, sub, sup, both), nil
+	case sub != "":
+		return fmt.Sprintf("<%s>%s%s</%s>", under, base, sub, under), nil
+	case sup != "":
+		return fmt.Sprintf("<%s>%s%s</%s>", over, base, sup, over), nil
+	}
+	return base, nil
+}
+
+// parseAtom parses an element without scripts.
+func (p *texParser) parseAtom() (string, error) {
+	tok := p.next()
+	switch {
+	case tok == "":
+		return "", errUnsupportedTeX
+	case tok == "{":
+		row, err := p.parseRow(map[string]bool{"}": true})
+		if err != nil || p.next() != "}" {
+			return "", errUnsupportedTeX
+		}
+		return mrow(row), nil
+	case '0' <= tok[0] && tok[0] <= '9':
+		return mathElem("mn", tok), nil
+	case isASCIILetter(tok[0]):
+		return mathElem("mi", tok), nil
+	case tok == "'":
+		return mathElem("mo", "′"), nil
+	case strings.HasPrefix(tok, `\begin{`):
+		return p.parseEnvironment(tok[len(`\begin{`) : len(tok)-1])
+	case tok[0] == '\\':
+		return p.parseCommand(tok[1:])
+	case strings.ContainsAny(tok, "+-=<>*/,;:!?|()[].~"):
+		return mathElem("mo", tok), nil
+	case tok == "}" || tok == "^" || tok == "_" || tok == "&":
+		return "", errUnsupportedTeX
+	}
+	return mathElem("mi", tok), nil
+}
+
+func (p *texParser) parseCommand(name string) (string, error) {
+	if s, ok := texSymbols[name]; ok {
+		return mathElem(s[0], s[1]), nil
+	}
+	if texFunctions[name] {
+		return mathElem("mi", name), nil
+	}
+	if width, ok := texSpaces[name]; ok {
+		return fmt.Sprintf(`<mspace width="%s"/>`, width), nil
+	}
+	if accent, ok := texAccents[name]; ok {
+		arg, err := p.parseAtom()
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf(`<mover accent="true">%s%s</mover>`, arg, mathElem("mo", accent)), nil
+	}
+	if variant, ok := texVariants[name]; ok {
+		text, err := p.rawGroup()
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf(`<mi mathvariant="%s">%s</mi>`, variant, codeEscaper.Replace(text)), nil
+	}
+	switch name {
+	case "frac", "dfrac", "tfrac", "binom":
+		num, err := p.parseAtom()
+		if err != nil {
+			return "", err
+		}
+		den, err := p.parseAtom()
+		if err != nil {
+			return "", err
+		}
+		if name == "binom" {
+			return fmt.Sprintf(`<mrow><mo>(</mo><mfrac linethickness="0">%s%s</mfrac><mo>)</mo></mrow>`, num, den), nil
+		}
+		return fmt.Sprintf("<mfrac>%s%s</mfrac>", num, den), nil
+	case "sqrt":
+		var index []string
+		if tok, _ := p.peek(); tok == "[" {
+			p.next()
+			row, err := p.parseRow(map[string]bool{"]": true})
+			if err != nil || p.next() != "]" {
+				return "", errUnsupportedTeX
+			}
+			index = row
+		}
+		arg, err := p.parseAtom()
+		if err != nil {
+			return "", err
+		}
+		if index != nil {
+			return fmt.Sprintf("<mroot>%s%s</mroot>", arg, mrow(index)), nil
+		}
+		return fmt.Sprintf("<msqrt>%s</msqrt>", arg), nil
+	case "text", "textrm", "mbox":
+		text, err := p.rawGroup()
+		if err != nil {
+			return "", err
+		}
+		return mathElem("mtext", text), nil
+	case "left":
+		opening := p.delimiter()
+		row, err := p.parseRow(map[string]bool{`\right`: true})
+		if err != nil || p.next() != `\right` {
+			return "", errUnsupportedTeX
+		}
+		closing := p.delimiter()
+		return mrow(append(append([]string{fence(opening)}, row...), fence(closing))), nil
+	}
+	return "", errUnsupportedTeX
+}
+
+// delimiter returns the delimiter after \left or \right. `.` is empty.
+func (p *texParser) delimiter() string {
+	switch tok := p.next(); tok {
+	case ".":
+		return ""
+	case `\{`, `\}`, `\|`:
+		return texSymbols[tok[1:]][1]
+	default:
+		if s, ok := texSymbols[strings.TrimPrefix(tok, `\`)]; ok && strings.HasPrefix(tok, `\`) {
+			return s[1]
+		}
+		return tok
+	}
+}
+
+func fence(s string) string {
+	if s == "" {
+		return ""
+	}
+	return `<mo fence="true">` + codeEscaper.Replace(s) + "</mo>"
+}
+
+// rawGroup returns the text of the braced group without conversion.
+func (p *texParser) rawGroup() (string, error) {
+	if tok, end := p.peek(); tok == "{" {
+		depth := 0
+		for i := end - 1; i < len(p.src); i++ {
+			switch p.src[i] {
+			case '{':
+				depth++
+			case '}':
+				if depth--; depth == 0 {
+					text := p.src[end:i]
+					p.pos = i + 1
+					return text, nil
+				}
+			}
+		}
+	}
+	return "", errUnsupportedTeX
+}
+
+// parseEnvironment parses the body of the environment until `\end{name}`.
+func (p *texParser) parseEnvironment(name string) (string, error) {
+	end := `\end{` + name + `}`
+	switch strings.TrimSuffix(name, "*") {
+	case "equation", "displaymath", "math":
+		row, err := p.parseRow(map[string]bool{end: true})
+		if err != nil || p.next() != end {
+			return "", errUnsupportedTeX
+		}
+		return mrow(row), nil
+	case "align", "aligned", "gather", "gathered", "split":
+		table, err := p.parseTable(end)
+		if err != nil {
+			return "", err
+		}
+		return `<mtable displaystyle="true" columnalign="right left">` + table + "</mtable>", nil
+	}
+	delims, ok := texMatrices[name]
+	if !ok {
+		return "", errUnsupportedTeX
+	}
+	table, err := p.parseTable(end)
+	if err != nil {
+		return "", err
+	}
+	attr := ""
+	if name == "cases" {
+		attr = ` columnalign="left left"`
+	}
+	return mrow([]string{fence(delims[0]), "<mtable" + attr + ">" + table + "</mtable>", fence(delims[1])}), nil
+}
+
+// parseTable parses the rows separated by `\\` and the cells separated by `&`
+// until the end token.
+func (p *texParser) parseTable(end string) (string, error) {
+	var (
+		buf   strings.Builder
+		cells []string
+		stop  = map[string]bool{"&": true, `\\`: true, end: true}
+	)
+	for {
+		row, err := p.parseRow(stop)
+		if err != nil {
+			return "", err
+		}
+		cells = append(cells, "<mtd>"+mrow(row)+"</mtd>")
+		switch p.next() {
+		case "&":
+			continue
+		case `\\`:
+			buf.WriteString("<mtr>" + strings.Join(cells, "") + "</mtr>")
+			cells = nil
+		case end:
+			// the last row ended by `\\` is empty.
+			if len(cells) > 1 || cells[0] != "<mtd><mrow></mrow></mtd>" {
+				buf.WriteString("<mtr>" + strings.Join(cells, "") + "</mtr>")
+			}
+			return buf.String(), nil
+		default:
+			return "", errUnsupportedTeX
+		}
+	}
+}
+
+func mathElem(tag, text string) string {
+	return "<" + tag + ">" + codeEscaper.Replace(text) + "</" + tag + ">"
+}
+
+// mrow groups the elements. A single element is not grouped.
+func mrow(elems []string) string {
+	var filtered []string
+	for _, e := range elems {
+		if e != "" {
+			filtered = append(filtered, e)
+		}
+	}
+	if len(filtered) == 1 {
+		return filtered[0]
+	}
+	return "<mrow>" + strings.Join(filtered, "") + "</mrow>"
+}
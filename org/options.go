@@ -0,0 +1,85 @@
+package org
+
+import "strings"
+
+// Options is the export settings which are specified by #+OPTIONS such as
+// `#+OPTIONS: e:nil -:t`.
+// https://orgmode.org/manual/Export-Settings.html
+type Options struct {
+	// Entities converts entities such as \alpha (e:).
+	Entities bool
+	// SpecialStrings converts special strings such as -- and ... (-:).
+	SpecialStrings bool
+	// SubSuperscripts is the mode of sub/superscripts such as H_2O (^:).
+	SubSuperscripts SubSuperscriptMode
+}
+
+// SubSuperscriptMode is the mode of sub/superscripts.
+type SubSuperscriptMode string
+
+const (
+	// SubSuperscriptEnabled converts both `x^2` and `x^{2}`.
+	SubSuperscriptEnabled SubSuperscriptMode = "t"
+	// SubSuperscriptBraced converts only `x^{2}`.
+	SubSuperscriptBraced SubSuperscriptMode = "{}"
+	// SubSuperscriptDisabled converts nothing.
+	SubSuperscriptDisabled SubSuperscriptMode = "nil"
+)
+
+// DefaultOptions returns the Options of documents without #+OPTIONS.
+func DefaultOptions() Options {
+	return Options{
+		Entities:        true,
+		SpecialStrings:  true,
+		SubSuperscripts: SubSuperscriptEnabled,
+	}
+}
+
+// ParseOptions returns DefaultOptions overridden by the #+OPTIONS keywords
+// in nodes. The later keyword takes precedence.
+func ParseOptions(nodes []Node) Options {
+	opts := DefaultOptions()
+	for _, node := range nodes {
+		if k, ok := node.(Keyword); ok && k.Key == string(OptionsKey) {
+			opts.Parse(k.Value)
+		}
+	}
+	return opts
+}
+
+// Parse overrides the options with the value of #+OPTIONS. Unsupported
+// options are ignored.
+func (o *Options) Parse(value string) {
+	for _, field := range strings.Fields(value) {
+		i := strings.Index(field, ":")
+		if i < 0 {
+			continue
+		}
+		key, val := field[:i], field[i+1:]
+		switch key {
+		case "e":
+			o.Entities = val != "nil"
+		case "-":
+			o.SpecialStrings = val != "nil"
+		case "^":
+			switch val {
+			case "{}":
+				o.SubSuperscripts = SubSuperscriptBraced
+			case "nil":
+				o.SubSuperscripts = SubSuperscriptDisabled
+			default:
+				o.SubSuperscripts = SubSuperscriptEnabled
+			}
+		}
+	}
+}
+
+// options returns the options of the document. DefaultOptions is used for
+// nodes written without HTMLWriter.Write.
+func (ctx *htmlContext) options() *Options {
+	if ctx.opts == nil {
+		opts := DefaultOptions()
+		ctx.opts = &opts
+	}
+	return ctx.opts
+}
@@ -0,0 +1,39 @@
+package org_test
+
+import (
+	"reflect"
+	"testing"
+
+	. "github.com/Ladicle/org2html/org"
+)
+
+func TestParseOptions(t *testing.T) {
+	var tests = []struct {
+		desc     string
+		input    string
+		wantOpts Options
+	}{
+		{
+			desc:     "default",
+			input:    "text\n",
+			wantOpts: Options{Entities: true, SpecialStrings: true, SubSuperscripts: SubSuperscriptEnabled},
+		},
+		{
+			desc:     "multiple keywords",
+			input:    "#+OPTIONS: e:nil toc:2 ^:{}\n#+options: -:nil\n",
+			wantOpts: Options{Entities: false, SpecialStrings: false, SubSuperscripts: SubSuperscriptBraced},
+		},
+		{
+			desc:     "later keyword takes precedence",
+			input:    "#+OPTIONS: e:nil ^:nil\ntext\n#+OPTIONS: e:t invalid\n",
+			wantOpts: Options{Entities: true, SpecialStrings: true, SubSuperscripts: SubSuperscriptDisabled},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			if got := ParseOptions(parseString(t, tt.input)); !reflect.DeepEqual(got, tt.wantOpts) {
+				t.Errorf("unexpected options:\ngot=%#v\nwant=%#v", got, tt.wantOpts)
+			}
+		})
+	}
+}
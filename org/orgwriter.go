@@ -0,0 +1,462 @@
+package org
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// OrgWriter writes nodes back as Org text.
+type OrgWriter struct {
+	// TagsColumn is the column of headline tags like org-tags-column. A
+	// negative value aligns the end of tags to the column, and a positive
+	// value aligns the beginning of tags.
+	TagsColumn int
+	// UpperCase writes keywords and block names in upper case such as
+	// #+BEGIN_SRC. Otherwise they are written in lower case.
+	UpperCase bool
+}
+
+// DefaultOrgWriter creates a new OrgWriter object with the default settings
+// of Org mode.
+func DefaultOrgWriter() OrgWriter {
+	return OrgWriter{TagsColumn: -77}
+}
+
+// Write writes nodes as Org text to the specified writer. Blank lines are
+// normalized: elements are separated by a blank line except the elements
+// which belong to the previous one such as agenda and property drawer.
+func (ow OrgWriter) Write(nodes []Node, out io.Writer) error {
+	var (
+		buf  bytes.Buffer
+		prev Node
+	)
+	for i := range nodes {
+		if prev != nil && !orgAttached(prev, nodes[i]) {
+			fmt.Fprintln(&buf)
+		}
+		if err := ow.writeNode(&buf, nodes[i]); err != nil {
+			return err
+		}
+		prev = nodes[i]
+	}
+	_, err := buf.WriteTo(out)
+	return err
+}
+
+// VerifyOrg returns an error if the Org text written from nodes is not parsed
+// into the same nodes except the positions, which means the text changes the
+// meaning of the document. It should be called before overwriting files.
+func VerifyOrg(nodes []Node, text []byte) error {
+	tokens, err := DefaultTokenizer().Tokenize(bytes.NewReader(text))
+	if err != nil {
+		return err
+	}
+	parsed, err := DefaultParser(tokens).Parse()
+	if err != nil {
+		return fmt.Errorf("written text is not parsed: %w", err)
+	}
+	for i := range nodes {
+		if i == len(parsed) || !reflect.DeepEqual(canonicalNode(nodes[i]), canonicalNode(parsed[i])) {
+			return fmt.Errorf("written text changes the %s at line %d", jsonTypeNames[reflect.TypeOf(nodes[i])], nodePos(nodes[i]).Line)
+		}
+	}
+	if len(parsed) > len(nodes) {
+		return fmt.Errorf("written text has the extra %s at line %d", jsonTypeNames[reflect.TypeOf(parsed[len(nodes)])], nodePos(parsed[len(nodes)]).Line)
+	}
+	return nil
+}
+
+// nodePos returns the Pos field of the node.
+func nodePos(node Node) Pos {
+	v := reflect.ValueOf(node)
+	if v.Kind() == reflect.Struct {
+		if f := v.FieldByName("Pos"); f.IsValid() {
+			if pos, ok := f.Interface().(Pos); ok {
+				return pos
+			}
+		}
+	}
+	return Pos{}
+}
+
+// canonicalNode returns a copy of the node to compare the meaning. The
+// positions are cleared, the rows of tables are filled with empty cells, and
+// ordered bullets are replaced with the delimiter because OrgWriter renumbers
+// them.
+func canonicalNode(node Node) Node {
+	v := reflect.New(reflect.TypeOf(node)).Elem()
+	v.Set(reflect.ValueOf(node))
+	canonicalize(v)
+	return v.Interface().(Node)
+}
+
+func canonicalize(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Struct:
+		switch v.Type() {
+		case reflect.TypeOf(Pos{}):
+			v.Set(reflect.Zero(v.Type()))
+			return
+		case reflect.TypeOf(Table{}):
+			t := v.Interface().(Table)
+			v.Set(reflect.ValueOf(Table{Rows: fillTableRows(t.Rows)}))
+			return
+		case reflect.TypeOf(List{}):
+			l := v.Interface().(List)
+			if l.Ordered {
+				items := make([]ListItem, len(l.Items))
+				for i, item := range l.Items {
+					item.Bullet = orderedDelimiter(l)
+					items[i] = item
+				}
+				l.Items = items
+				v.Set(reflect.ValueOf(l))
+			}
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if v.Field(i).CanSet() {
+				canonicalize(v.Field(i))
+			}
+		}
+	case reflect.Slice:
+		if v.IsNil() {
+			return
+		}
+		s := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		reflect.Copy(s, v)
+		for i := 0; i < s.Len(); i++ {
+			canonicalize(s.Index(i))
+		}
+		v.Set(s)
+	case reflect.Ptr:
+		if v.IsNil() {
+			return
+		}
+		p := reflect.New(v.Type().Elem())
+		p.Elem().Set(v.Elem())
+		canonicalize(p.Elem())
+		v.Set(p)
+	case reflect.Interface:
+		if v.IsNil() {
+			return
+		}
+		e := reflect.New(v.Elem().Type()).Elem()
+		e.Set(v.Elem())
+		canonicalize(e)
+		v.Set(e)
+	}
+}
+
+// fillTableRows returns the copy of rows whose cells are filled to the same
+// number. Horizontal rules are kept.
+func fillTableRows(rows [][]string) [][]string {
+	var n int
+	for _, row := range rows {
+		if len(row) > n {
+			n = len(row)
+		}
+	}
+	ret := make([][]string, len(rows))
+	for i, row := range rows {
+		if row != nil {
+			ret[i] = append(make([]string, 0, n), row...)
+			for len(ret[i]) < n {
+				ret[i] = append(ret[i], "")
+			}
+		}
+	}
+	return ret
+}
+
+// orgAttached returns true if the node must be placed just after the previous
+// node without blank lines.
+func orgAttached(prev, node Node) bool {
+	switch p := prev.(type) {
+	case Headline:
+		switch node.(type) {
+		case Headline, Agenda, PropertyDrawer, Drawer, Clock:
+			return true
+		}
+	case Agenda:
+		switch node.(type) {
+		case Headline, PropertyDrawer, Drawer, Clock:
+			return true
+		}
+	case PropertyDrawer, Drawer, Clock:
+		switch node.(type) {
+		case Headline, Drawer, Clock:
+			return true
+		}
+	case Keyword:
+		// affiliated keywords such as #+NAME belong to the next element.
+		if _, ok := node.(Keyword); ok || isAffiliatedKeyword(p.Key) {
+			return true
+		}
+	case Comment:
+		_, ok := node.(Comment)
+		return ok
+	}
+	return false
+}
+
+func isAffiliatedKeyword(key string) bool {
+	switch KeywordType(key) {
+	case NameKey, CaptionKey, "HEADER", "PLOT", "RESULTS":
+		return true
+	}
+	return strings.HasPrefix(key, "ATTR_")
+}
+
+func (ow OrgWriter) writeNode(w io.Writer, node Node) error {
+	switch n := node.(type) {
+	case Headline:
+		return ow.writeHeadline(w, n)
+	case Section:
+		for i := range n.Paragraphs {
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			fmt.Fprintln(w, n.Paragraphs[i])
+		}
+	case Agenda:
+		writeOrgAgenda(w, n)
+	case Keyword:
+		fmt.Fprintln(w, strings.TrimRight(fmt.Sprintf("#+%s: %s", ow.keywordCase(n.Key), n.Value), " "))
+	case Comment:
+		fmt.Fprintln(w, strings.TrimRight("# "+n.Message, " "))
+	case Block:
+		ow.writeBlock(w, n.Name, n.Parameters, n.Content)
+	case SourceBlock:
+		params := n.Language
+		if n.Switches != "" {
+			params += " " + n.Switches
+		}
+		for _, prop := range n.Property {
+			params += " :" + prop
+		}
+		ow.writeBlock(w, sourceBlockName, params, n.SourceCode)
+		if n.Results != nil {
+			fmt.Fprintln(w)
+			return ow.writeNode(w, *n.Results)
+		}
+	case Results:
+		key := ow.keywordCase(resultsKey)
+		if n.Hash != "" {
+			key += "[" + n.Hash + "]"
+		}
+		fmt.Fprintln(w, strings.TrimRight(fmt.Sprintf("#+%s: %s", key, n.Name), " "))
+		for i := range n.Value {
+			if err := ow.writeNode(w, n.Value[i]); err != nil {
+				return err
+			}
+		}
+	case FixedWidth:
+		for _, line := range strings.Split(n.Content, "\n") {
+			if line == "" {
+				fmt.Fprintln(w, ":")
+			} else {
+				fmt.Fprintln(w, ": "+line)
+			}
+		}
+	case Drawer:
+		fmt.Fprintf(w, ":%s:\n", n.Name)
+		if n.Content != "" {
+			fmt.Fprintln(w, n.Content)
+		}
+		fmt.Fprintf(w, ":%s:\n", drawerEndName)
+	case DynamicBlock:
+		params := n.Name
+		for _, p := range n.Parameters {
+			params += strings.TrimRight(" :"+p.Key+" "+p.Value, " ")
+		}
+		fmt.Fprintf(w, "#+%s: %s\n", ow.keywordCase(dynamicBlockBegin), params)
+		if n.Content != "" {
+			fmt.Fprintln(w, n.Content)
+		}
+		fmt.Fprintf(w, "#+%s:\n", ow.keywordCase(dynamicBlockEnd))
+	case Clock:
+		fmt.Fprintln(w, n.Literal())
+	case LatexEnvironment:
+		fmt.Fprintln(w, n.Content)
+	case FootnoteDefinition:
+		first, rest := n.Content, ""
+		if i := strings.Index(n.Content, "\n"); i >= 0 {
+			first, rest = n.Content[:i], n.Content[i:]
+		}
+		fmt.Fprintln(w, strings.TrimRight("[fn:"+n.Label+"] "+first, " ")+rest)
+	case PropertyDrawer:
+		fmt.Fprintf(w, ":%s:\n", propertiesDrawerName)
+		for _, prop := range n.Properties {
+			fmt.Fprintln(w, strings.TrimRight(fmt.Sprintf("%-10s %s", ":"+prop.Key+":", prop.Value), " "))
+		}
+		fmt.Fprintf(w, ":%s:\n", drawerEndName)
+	case List:
+		writeOrgList(w, n, "")
+	case Table:
+		writeOrgTable(w, n)
+	default:
+		return fmt.Errorf("org writer does not support the node: %T", node)
+	}
+	return nil
+}
+
+func (ow OrgWriter) writeHeadline(w io.Writer, h Headline) error {
+	if h.Starts == 0 {
+		return fmt.Errorf("invalid number of starts: %#v", h)
+	}
+	if h.Title == "" {
+		return fmt.Errorf("title is empty: %#v", h)
+	}
+	line := strings.Repeat("*", h.Starts)
+	if h.Keyword != "" {
+		line += " " + h.Keyword
+	}
+	if h.Priority != "" {
+		line += " [#" + h.Priority + "]"
+	}
+	line += " " + h.Title
+	if len(h.Tags) > 0 {
+		tags := ":" + strings.Join(h.Tags, ":") + ":"
+		width := stringWidth(line)
+		pad := 1
+		if ow.TagsColumn < 0 {
+			pad = -ow.TagsColumn - width - stringWidth(tags)
+		} else if ow.TagsColumn > 0 {
+			pad = ow.TagsColumn - width
+		}
+		if pad < 1 {
+			pad = 1
+		}
+		line += strings.Repeat(" ", pad) + tags
+	}
+	fmt.Fprintln(w, line)
+	return nil
+}
+
+func writeOrgAgenda(w io.Writer, a Agenda) {
+	var items []string
+	for _, key := range []AgendaKey{AgendaClosed, AgendaDeadline, AgendaScheduled} {
+		if log, ok := a.Logs[key]; ok {
+			// only the closed date is recorded as the inactive timestamp.
+			items = append(items, fmt.Sprintf("%v: %v", key, log.Literal(key != AgendaClosed)))
+		}
+	}
+	if len(items) > 0 {
+		fmt.Fprintln(w, a.Indent+strings.Join(items, " "))
+	}
+}
+
+func (ow OrgWriter) keywordCase(s string) string {
+	if ow.UpperCase {
+		return strings.ToUpper(s)
+	}
+	return strings.ToLower(s)
+}
+
+func (ow OrgWriter) writeBlock(w io.Writer, name, params, content string) {
+	fmt.Fprintln(w, strings.TrimRight(ow.keywordCase("#+begin_"+name)+" "+params, " "))
+	if content != "" {
+		fmt.Fprintln(w, escapeBlockContent(content))
+	}
+	fmt.Fprintln(w, ow.keywordCase("#+end_"+name))
+}
+
+// writeOrgList writes the list with renumbered bullets. Sublists are indented
+// to the content column of the parent item.
+func writeOrgList(w io.Writer, l List, indent string) {
+	delim := orderedDelimiter(l)
+	for i, item := range l.Items {
+		bullet := item.Bullet
+		if l.Ordered {
+			bullet = strconv.Itoa(i+1) + delim
+		}
+		// continuation lines are indented to the content column.
+		content := indent + strings.Repeat(" ", len(bullet)+1)
+		fmt.Fprintf(w, "%s%s %s\n", indent, bullet, strings.ReplaceAll(item.Content, "\n", "\n"+content))
+		if item.Sublist != nil {
+			writeOrgList(w, *item.Sublist, content)
+		}
+	}
+}
+
+// orderedDelimiter returns the delimiter of the first numbered bullet such as
+// `)` of `1)`. `.` is used if no items have numbered bullets.
+func orderedDelimiter(l List) string {
+	for _, item := range l.Items {
+		delim := strings.TrimLeft(item.Bullet, "0123456789")
+		if delim != item.Bullet && (delim == "." || delim == ")") {
+			return delim
+		}
+	}
+	return "."
+}
+
+// writeOrgTable writes the table whose columns are aligned.
+func writeOrgTable(w io.Writer, t Table) {
+	var widths []int
+	for _, row := range t.Rows {
+		for j, cell := range row {
+			if j == len(widths) {
+				widths = append(widths, 0)
+			}
+			if n := stringWidth(cell); n > widths[j] {
+				widths[j] = n
+			}
+		}
+	}
+	for _, row := range t.Rows {
+		if row == nil {
+			rule := make([]string, len(widths))
+			for j := range widths {
+				rule[j] = strings.Repeat("-", widths[j]+2)
+			}
+			fmt.Fprintf(w, "|%s|\n", strings.Join(rule, "+"))
+			continue
+		}
+		cells := make([]string, len(widths))
+		for j := range widths {
+			var cell string
+			if j < len(row) {
+				cell = row[j]
+			}
+			cells[j] = cell + strings.Repeat(" ", widths[j]-stringWidth(cell))
+		}
+		fmt.Fprintf(w, "| %s |\n", strings.Join(cells, " | "))
+	}
+}
+
+// stringWidth returns the display width of the string. East Asian wide
+// characters occupy two columns.
+func stringWidth(s string) int {
+	var width int
+	for _, r := range s {
+		width++
+		if isWideRune(r) {
+			width++
+		}
+	}
+	return width
+}
+
+func isWideRune(r rune) bool {
+	switch {
+	case r < 0x1100:
+		return false
+	case r <= 0x115f, // Hangul Jamo
+		0x2e80 <= r && r <= 0xa4cf && r != 0x303f, // CJK ... Yi
+		0xac00 <= r && r <= 0xd7a3,                // Hangul Syllables
+		0xf900 <= r && r <= 0xfaff,                // CJK Compatibility Ideographs
+		0xfe30 <= r && r <= 0xfe4f,                // CJK Compatibility Forms
+		0xff00 <= r && r <= 0xff60,                // Fullwidth Forms
+		0xffe0 <= r && r <= 0xffe6,
+		0x1f300 <= r && r <= 0x1f64f, // Emoticons
+		0x1f900 <= r && r <= 0x1f9ff,
+		0x20000 <= r && r <= 0x3fffd:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,363 @@
+package org_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	. "github.com/Ladicle/org2html/org"
+)
+
+func TestOrgWriter(t *testing.T) {
+	var tests = []struct {
+		desc    string
+		writer  OrgWriter
+		nodes   []Node
+		wantOut string
+	}{
+		{
+			desc:   "headline with right aligned tags",
+			writer: DefaultOrgWriter(),
+			nodes: []Node{
+				Headline{Starts: 2, Keyword: "TODO", Priority: "A", Title: "title", Tags: []string{"a", "@b"}},
+			},
+			wantOut: "** TODO [#A] title                                                     :a:@b:\n",
+		},
+		{
+			desc:    "headline with left aligned tags",
+			writer:  OrgWriter{TagsColumn: 20},
+			nodes:   []Node{Headline{Starts: 1, Title: "title", Tags: []string{"tag"}}},
+			wantOut: "* title             :tag:\n",
+		},
+		{
+			desc:    "too long headline",
+			writer:  OrgWriter{TagsColumn: -10},
+			nodes:   []Node{Headline{Starts: 1, Title: "long title", Tags: []string{"tag"}}},
+			wantOut: "* long title :tag:\n",
+		},
+		{
+			desc:   "planning and drawers",
+			writer: DefaultOrgWriter(),
+			nodes: []Node{
+				Headline{Starts: 1, Keyword: "DONE", Title: "title"},
+				Agenda{Logs: map[AgendaKey]Timestamp{
+					AgendaClosed:    mustParseTimestamp(t, "2022-01-31 Mon 11:12", ""),
+					AgendaScheduled: mustParseDatestamp(t, "2022-01-30 Sun", "+1w"),
+				}},
+				PropertyDrawer{Properties: []Property{{Key: "ID", Value: "abc"}, {Key: "EXPORT_FILE_NAME", Value: "post"}}},
+				Drawer{Name: "LOGBOOK", Content: "- note"},
+				Section{Paragraphs: []string{"line1\nline2", "paragraph2"}},
+			},
+			wantOut: `* DONE title
+CLOSED: [2022-01-31 Mon 11:12] SCHEDULED: <2022-01-30 Sun +1w>
+:PROPERTIES:
+:ID:       abc
+:EXPORT_FILE_NAME: post
+:END:
+:LOGBOOK:
+- note
+:END:
+
+line1
+line2
+
+paragraph2
+`,
+		},
+		{
+			desc:   "keywords and blocks",
+			writer: DefaultOrgWriter(),
+			nodes: []Node{
+				Keyword{Key: "TITLE", Value: "title"},
+				Keyword{Key: "DATE", Value: "[2022-02-03 Thu 11:19]"},
+				Comment{Message: "comment"},
+				Keyword{Key: "NAME", Value: "hello"},
+				SourceBlock{Language: "org", SourceCode: "* headline\n#+title: x", Property: []string{"exports both"}},
+				Block{Name: "EXPORT", Parameters: "html", Content: "<br>"},
+			},
+			wantOut: `#+title: title
+#+date: [2022-02-03 Thu 11:19]
+
+# comment
+
+#+name: hello
+#+begin_src org :exports both
+,* headline
+,#+title: x
+#+end_src
+
+#+begin_export html
+<br>
+#+end_export
+`,
+		},
+		{
+			desc:   "source block with results",
+			writer: DefaultOrgWriter(),
+			nodes: []Node{
+				SourceBlock{Language: "sh", SourceCode: "echo hi", Results: &Results{
+					Hash:  "ab",
+					Value: Nodes{FixedWidth{Content: "hi\n\n  there"}},
+				}},
+			},
+			wantOut: `#+begin_src sh
+echo hi
+#+end_src
+
+#+results[ab]:
+: hi
+:
+:   there
+`,
+		},
+		{
+			desc:   "list and table",
+			writer: DefaultOrgWriter(),
+			nodes: []Node{
+				List{Ordered: true, Items: []ListItem{
+					{Bullet: "3)", Content: "item1"},
+					{Bullet: "10)", Content: "item2", Sublist: &List{
+						Items: []ListItem{{Bullet: "-", Content: "sub"}},
+					}},
+				}},
+				Table{Rows: [][]string{{"name", "value"}, nil, {"日本語", "1"}, {"b"}}},
+			},
+			wantOut: `1) item1
+2) item2
+   - sub
+
+| name   | value |
+|--------+-------|
+| 日本語 | 1     |
+| b      |       |
+`,
+		},
+		{
+			desc:   "ordered list with empty and mixed bullets",
+			writer: DefaultOrgWriter(),
+			nodes: []Node{
+				List{Ordered: true, Items: []ListItem{{Content: "empty"}, {Bullet: "-", Content: "dash"}}},
+				List{Ordered: true, Items: []ListItem{{Bullet: "-", Content: "dash"}, {Bullet: "5)", Content: "paren"}, {Bullet: "2.", Content: "dot"}}},
+			},
+			wantOut: "1. empty\n2. dash\n\n1) dash\n2) paren\n3) dot\n",
+		},
+		{
+			desc:   "upper case",
+			writer: OrgWriter{UpperCase: true},
+			nodes: []Node{
+				Keyword{Key: "title", Value: "title"},
+				Block{Name: "QUOTE", Content: "quote"},
+			},
+			wantOut: `#+TITLE: title
+
+#+BEGIN_QUOTE
+quote
+#+END_QUOTE
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := tt.writer.Write(tt.nodes, &out); err != nil {
+				t.Fatalf("unexpected error: err=%v", err)
+			}
+			if got := out.String(); got != tt.wantOut {
+				t.Errorf("unexpected output:\ngot=%v\nwant=%v", got, tt.wantOut)
+			}
+		})
+	}
+}
+
+func TestOrgWriterRoundTrip(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "*", "*.org"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files = append(files, filepath.Join("..", "test", "fixtures", "input.org"))
+	for _, file := range files {
+		t.Run(file, func(t *testing.T) {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			nodes := parseString(t, string(data))
+
+			var out1, out2 bytes.Buffer
+			if err := DefaultOrgWriter().Write(nodes, &out1); err != nil {
+				t.Fatalf("unexpected error: err=%v", err)
+			}
+			formatted := parseString(t, out1.String())
+			if err := DefaultOrgWriter().Write(formatted, &out2); err != nil {
+				t.Fatalf("unexpected error: err=%v", err)
+			}
+			if got, want := out2.String(), out1.String(); got != want {
+				t.Errorf("format is not stable:\ngot=%v\nwant=%v", got, want)
+			}
+			if err := VerifyOrg(nodes, out1.Bytes()); err != nil {
+				t.Errorf("output changes the input: err=%v", err)
+			}
+		})
+	}
+}
+
+func TestOrgWriterGolden(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "fmt", "*.org"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		t.Run(file, func(t *testing.T) {
+			input, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			golden, err := os.ReadFile(strings.TrimSuffix(file, ".org") + ".golden")
+			if err != nil {
+				t.Fatal(err)
+			}
+			nodes := parseString(t, string(input))
+			var out bytes.Buffer
+			if err := DefaultOrgWriter().Write(nodes, &out); err != nil {
+				t.Fatalf("unexpected error: err=%v", err)
+			}
+			if got := out.String(); got != string(golden) {
+				t.Errorf("unexpected output:\ngot=%v\nwant=%v", got, string(golden))
+			}
+			if err := VerifyOrg(nodes, out.Bytes()); err != nil {
+				t.Errorf("output changes the input: err=%v", err)
+			}
+		})
+	}
+}
+
+func TestVerifyOrg(t *testing.T) {
+	var tests = []struct {
+		desc      string
+		nodes     []Node
+		text      string
+		wantError error
+	}{
+		{
+			desc:  "same nodes",
+			nodes: []Node{Table{Rows: [][]string{{"a", "b"}, nil, {"c"}}}},
+			text:  "| a | b |\n|---+---|\n| c |   |\n",
+		},
+		{
+			desc:      "paragraph becomes headline",
+			nodes:     []Node{Section{Pos: Pos{Line: 3}, Paragraphs: []string{"* not a headline"}}},
+			text:      "* not a headline\n",
+			wantError: errors.New("written text changes the section at line 3"),
+		},
+		{
+			desc:      "extra node",
+			nodes:     []Node{Keyword{Key: "TITLE", Value: "a"}},
+			text:      "#+title: a\n\ntext\n",
+			wantError: errors.New("written text has the extra section at line 3"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			err := VerifyOrg(tt.nodes, []byte(tt.text))
+			if err != nil {
+				if tt.wantError == nil || err.Error() != tt.wantError.Error() {
+					t.Fatalf("unexpected error: err=%v, want=%v", err, tt.wantError)
+				}
+				return
+			} else if tt.wantError != nil {
+				t.Fatalf("expect error but not occurred: want=%v", tt.wantError)
+			}
+		})
+	}
+}
+
+func TestSetAgenda(t *testing.T) {
+	nodes := parseString(t, "* TODO task\n  SCHEDULED: <2022-01-30 Sun>\n* TODO task2\n")
+	closed := mustParseTimestamp(t, "2022-01-31 Mon 11:12", "")
+	for i := len(nodes) - 1; i >= 0; i-- {
+		if hl, ok := nodes[i].(Headline); ok {
+			hl.Keyword = "DONE"
+			nodes[i] = hl
+			nodes = SetAgenda(nodes, i, AgendaClosed, closed)
+		}
+	}
+
+	var out bytes.Buffer
+	if err := DefaultOrgWriter().Write(nodes, &out); err != nil {
+		t.Fatalf("unexpected error: err=%v", err)
+	}
+	want := `* DONE task
+  CLOSED: [2022-01-31 Mon 11:12] SCHEDULED: <2022-01-30 Sun>
+* DONE task2
+CLOSED: [2022-01-31 Mon 11:12]
+`
+	if got := out.String(); got != want {
+		t.Errorf("unexpected output:\ngot=%v\nwant=%v", got, want)
+	}
+
+	// the original nodes are not modified.
+	orig := parseString(t, "* task\n  SCHEDULED: <2022-01-30 Sun>\n* task2\n")
+	saved := append([]Node(nil), orig...)
+	SetAgenda(orig, 0, AgendaClosed, closed)
+	SetAgenda(orig[:1], 0, AgendaClosed, closed)
+	if !reflect.DeepEqual(orig, saved) {
+		t.Errorf("nodes are modified:\ngot=%#v\nwant=%#v", orig, saved)
+	}
+}
+
+// withoutPos returns a copy of nodes whose positions are cleared.
+func withoutPos(nodes []Node) []Node {
+	ret := make([]Node, len(nodes))
+	for i := range nodes {
+		v := reflect.New(reflect.TypeOf(nodes[i])).Elem()
+		v.Set(reflect.ValueOf(nodes[i]))
+		clearPos(v)
+		ret[i] = v.Interface().(Node)
+	}
+	return ret
+}
+
+func clearPos(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Struct:
+		if v.Type() == reflect.TypeOf(Pos{}) {
+			v.Set(reflect.Zero(v.Type()))
+			return
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if v.Field(i).CanSet() {
+				clearPos(v.Field(i))
+			}
+		}
+	case reflect.Slice:
+		if v.IsNil() {
+			return
+		}
+		s := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		reflect.Copy(s, v)
+		for i := 0; i < s.Len(); i++ {
+			clearPos(s.Index(i))
+		}
+		v.Set(s)
+	case reflect.Ptr:
+		if v.IsNil() {
+			return
+		}
+		p := reflect.New(v.Type().Elem())
+		p.Elem().Set(v.Elem())
+		clearPos(p.Elem())
+		v.Set(p)
+	case reflect.Interface:
+		if v.IsNil() {
+			return
+		}
+		e := reflect.New(v.Elem().Type()).Elem()
+		e.Set(v.Elem())
+		clearPos(e)
+		v.Set(e)
+	}
+}
@@ -17,6 +17,18 @@ var defaultParseFns = map[TokenKind]ParseFn{
 	KindHeadline:   ParseHeadline,
 	KindText:       ParseSection,
 	KindBlockBegin: ParseBlock,
+	KindListItem:   ParseList,
+	KindTableRow:   ParseTable,
+	KindTableRule:  ParseTable,
+	KindDrawer:     ParseDrawer,
+	KindFixedWidth: ParseFixedWidth,
+	KindClock:      ParseClock,
+
+	KindDynamicBegin: ParseDynamicBlock,
+	KindDynamicEnd:   ParseDynamicBlock,
+
+	KindFootnoteDefinition: ParseFootnoteDefinition,
+	KindLatexEnvironment:   ParseLatexEnvironment,
 }
 
 // NewParser creates a new Parser object.
@@ -32,11 +44,31 @@ func DefaultParser(tokens []Token) Parser {
 type Parser struct {
 	tokens   []Token
 	parseFns map[TokenKind]ParseFn
+	// doc is the tokens of the whole document if the parser parses the
+	// contents of an element.
+	doc []Token
+}
+
+// subParser creates a new Parser object to parse the contents p.tokens[start:end]
+// of an element.
+func (p *Parser) subParser(start, end int) *Parser {
+	return &Parser{tokens: p.tokens[start:end], parseFns: p.parseFns, doc: p.document()}
+}
+
+// document returns the tokens of the whole document.
+func (p *Parser) document() []Token {
+	if p.doc != nil {
+		return p.doc
+	}
+	return p.tokens
 }
 
 func (p Parser) Parse() ([]Node, error) {
 	_, nodes, err := p.parseMany(0)
-	return nodes, err
+	if err != nil {
+		return nil, err
+	}
+	return attachResults(nodes), nil
 }
 
 // parseOne parses multiple Nodes and returns the number of tokens consumed,
@@ -56,7 +88,10 @@ func (p *Parser) parseMany(i int) (int, []Node, error) {
 		if err != nil {
 			return 0, nil, err
 		}
-		nodes = append(nodes, node)
+		// some parsers consume tokens without producing a node (e.g. empty comments).
+		if node != nil {
+			nodes = append(nodes, node)
+		}
 		i += consumed
 	}
 	return i - start, nodes, nil
@@ -0,0 +1,408 @@
+package org
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Project is a set of Org files in a file system such as a directory of
+// notes, which are published as a mirrored tree of HTML files like
+// org-publish.
+type Project struct {
+	// FS is the file system which contains the documents.
+	FS fs.FS
+	// HomeFS is the file system of the home directory for #+SETUPFILE and
+	// #+INCLUDE. See Loader.
+	HomeFS fs.FS
+	// Include is the glob patterns of the documents. All `.org` files are
+	// included if it is empty. Patterns are matched against the slash
+	// separated path and the base name with path.Match.
+	Include []string
+	// Exclude is the glob patterns of the excluded documents and directories.
+	Exclude []string
+	// Writer writes the documents as HTML. ResolveLink is overridden to
+	// resolve links between the documents.
+	Writer HTMLWriter
+	// BaseURL is the absolute URL of the published directory such as
+	// `https://example.com/notes/`. sitemap.xml is written if it is set.
+	BaseURL string
+	// Sitemap is the settings of the index page. It is not written if nil.
+	Sitemap *Sitemap
+	// Feed is the settings of the Atom or RSS feed, which requires BaseURL.
+	// It is not written if nil.
+	Feed *Feed
+	// Cache is the settings of incremental builds. All documents are
+	// rendered in each build if nil.
+	Cache *BuildCache
+}
+
+// Document is an Org file of the Project.
+type Document struct {
+	// Path is the slash separated path in Project.FS such as `notes/a.org`.
+	Path string
+	// Nodes is the parsed document whose macros are expanded.
+	Nodes []Node
+	// ModTime is the modification time of the file if it is available.
+	ModTime time.Time
+	// Deps is the paths of the files which are read by #+INCLUDE and
+	// #+SETUPFILE of the document.
+	Deps []string
+}
+
+// Keyword returns the values of the keyword such as TITLE joined with spaces.
+func (d Document) Keyword(key string) string {
+	var values []string
+	for _, node := range d.Nodes {
+		if k, ok := node.(Keyword); ok && k.Key == key {
+			values = append(values, k.Value)
+		}
+	}
+	return strings.Join(values, " ")
+}
+
+// Title returns #+TITLE, or the file name without the extension.
+func (d Document) Title() string {
+	if title := d.Keyword("TITLE"); title != "" {
+		return title
+	}
+	return strings.TrimSuffix(path.Base(d.Path), ".org")
+}
+
+// Date returns #+DATE such as `<2022-01-30 Sun>` or `2022-01-30`.
+func (d Document) Date() (time.Time, bool) {
+	date := d.Keyword("DATE")
+	if ts, err := ParseTimestampLiteral(date); err == nil {
+		return ts.Time, true
+	}
+	if t, err := time.Parse("2006-01-02", date); err == nil {
+		return t, true
+	}
+	return time.Time{}, false
+}
+
+// Tags returns the tags of #+FILETAGS such as `:emacs:org:`.
+func (d Document) Tags() []string {
+	return strings.FieldsFunc(d.Keyword("FILETAGS"), func(r rune) bool { return r == ':' || r == ' ' })
+}
+
+// HTMLPath returns the slash separated path of the published HTML file.
+func (d Document) HTMLPath() string {
+	return strings.TrimSuffix(d.Path, ".org") + ".html"
+}
+
+// Files returns the paths of the documents in lexical order.
+func (p Project) Files() ([]string, error) {
+	var files []string
+	err := fs.WalkDir(p.FS, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if name != "." && matchAny(p.Exclude, name) {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || path.Ext(name) != ".org" {
+			return nil
+		}
+		if len(p.Include) == 0 || matchAny(p.Include, name) {
+			files = append(files, name)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// matchAny reports whether the path or its base name matches any patterns.
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, path.Base(name)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Load parses the documents of the project concurrently. Macros of the
+// documents are expanded.
+func (p Project) Load() ([]Document, error) {
+	files, err := p.Files()
+	if err != nil {
+		return nil, err
+	}
+	return p.loadFiles(files)
+}
+
+// loadFiles parses the documents of the paths concurrently.
+func (p Project) loadFiles(files []string) ([]Document, error) {
+	var (
+		docs = make([]Document, len(files))
+		errs = make([]error, len(files))
+		sem  = make(chan struct{}, runtime.NumCPU())
+		wg   sync.WaitGroup
+	)
+	for i := range files {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			docs[i], errs[i] = p.load(files[i])
+		}(i)
+	}
+	wg.Wait()
+	for i := range errs {
+		if errs[i] != nil {
+			return nil, fmt.Errorf("%s: %w", files[i], errs[i])
+		}
+	}
+	return docs, nil
+}
+
+func (p Project) load(name string) (Document, error) {
+	var deps []string
+	loader := Loader{FS: p.FS, HomeFS: p.HomeFS, OnRead: func(dep string) {
+		if dep != name {
+			deps = append(deps, dep)
+		}
+	}}
+	nodes, err := loader.Load(name)
+	if err != nil {
+		return Document{}, err
+	}
+	env := MacroEnv{InputFile: name}
+	if info, err := fs.Stat(p.FS, name); err == nil {
+		env.ModTime = info.ModTime()
+	}
+	if nodes, err = ExpandMacros(nodes, env); err != nil {
+		return Document{}, err
+	}
+	return Document{Path: name, Nodes: nodes, ModTime: env.ModTime, Deps: deps}, nil
+}
+
+// Publish loads the documents and writes them as HTML pages to the directory.
+// The directory tree of the project is mirrored. The sitemap page, sitemap.xml
+// and the feed are also written if they are configured. With Cache, only the
+// documents which are changed since the last build and their dependents are
+// rendered, and nothing is written if no document is changed.
+func (p Project) Publish(dir string) error {
+	if p.Feed != nil && p.BaseURL == "" {
+		return errors.New("base URL is required for the feed")
+	}
+	files, err := p.Files()
+	if err != nil {
+		return err
+	}
+	b, err := p.newBuild(dir, files)
+	if err != nil {
+		return err
+	}
+	pages, err := p.loadFiles(b.stale())
+	if err != nil {
+		return err
+	}
+	links := b.links(pages)
+	relinked, err := p.loadFiles(b.relinked(pages, links))
+	if err != nil {
+		return err
+	}
+	pages = append(pages, relinked...)
+	for _, doc := range pages {
+		if err := p.publishPage(dir, doc, links); err != nil {
+			return err
+		}
+		if err := b.update(doc, links); err != nil {
+			return err
+		}
+	}
+	removed, err := b.removePages()
+	if err != nil {
+		return err
+	}
+	if p.Cache == nil || len(pages) > 0 || removed > 0 {
+		docs, err := b.documents(pages)
+		if err != nil {
+			return err
+		}
+		if err := p.publishIndexes(dir, docs, links); err != nil {
+			return err
+		}
+	}
+	return b.save()
+}
+
+// publishIndexes writes the sitemap page, sitemap.xml and the feed of all
+// documents.
+func (p Project) publishIndexes(dir string, docs []Document, links *ProjectLinks) error {
+	if p.Sitemap != nil {
+		sitemap, err := p.Sitemap.Document(docs)
+		if err != nil {
+			return err
+		}
+		if err := p.publishPage(dir, sitemap, links); err != nil {
+			return err
+		}
+		docs = append(docs, sitemap)
+	}
+	if p.BaseURL != "" {
+		var buf bytes.Buffer
+		if err := WriteSitemapXML(&buf, docs, p.BaseURL); err != nil {
+			return err
+		}
+		if err := writeOutputFile(dir, "sitemap.xml", buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	if p.Feed != nil {
+		entries, err := p.Feed.Entries(docs, links, p.Writer, p.BaseURL)
+		if err != nil {
+			return err
+		}
+		var buf bytes.Buffer
+		if err := p.Feed.Write(&buf, entries, p.BaseURL); err != nil {
+			return err
+		}
+		if err := writeOutputFile(dir, p.Feed.fileName(), buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p Project) publishPage(dir string, doc Document, links *ProjectLinks) error {
+	var buf bytes.Buffer
+	if err := p.WritePage(&buf, doc, links); err != nil {
+		return fmt.Errorf("%s: %w", doc.Path, err)
+	}
+	return writeOutputFile(dir, doc.HTMLPath(), buf.Bytes())
+}
+
+// writeOutputFile writes the data to the slash separated path in dir.
+func writeOutputFile(dir, name string, data []byte) error {
+	out := filepath.Join(dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(out), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(out, data, 0644)
+}
+
+// WritePage writes the document as an HTML page with #+TITLE and #+HTML_HEAD.
+// Links to the other documents are resolved by links.
+func (p Project) WritePage(w io.Writer, doc Document, links *ProjectLinks) error {
+	fmt.Fprintln(w, "<!DOCTYPE html>")
+	fmt.Fprintln(w, "<html>")
+	fmt.Fprintln(w, "<head>")
+	fmt.Fprintln(w, `<meta charset="utf-8">`)
+	fmt.Fprintf(w, "<title>%s</title>\n", html.EscapeString(doc.Title()))
+	for _, node := range doc.Nodes {
+		if k, ok := node.(Keyword); ok && (k.Key == "HTML_HEAD" || k.Key == "HTML_HEAD_EXTRA") {
+			fmt.Fprintln(w, k.Value)
+		}
+	}
+	fmt.Fprintln(w, "</head>")
+	fmt.Fprintln(w, "<body>")
+	hw := p.Writer
+	hw.ResolveLink = func(target string) (string, bool) {
+		return links.Resolve(doc.Path, target)
+	}
+	if err := hw.writeExpanded(doc.Nodes, w); err != nil {
+		return err
+	}
+	fmt.Fprintln(w, "</body>")
+	fmt.Fprintln(w, "</html>")
+	return nil
+}
+
+// ProjectLinks resolves `file:` and `id:` links between the documents.
+type ProjectLinks struct {
+	docs map[string]bool
+	// ids maps the CUSTOM_ID and ID properties to the documents.
+	ids map[string]string
+}
+
+// NewProjectLinks returns the ProjectLinks of the documents.
+func NewProjectLinks(docs []Document) *ProjectLinks {
+	links := newProjectLinks()
+	for _, doc := range docs {
+		links.add(doc.Path, documentIDs(doc.Nodes))
+	}
+	return links
+}
+
+func newProjectLinks() *ProjectLinks {
+	return &ProjectLinks{docs: make(map[string]bool), ids: make(map[string]string)}
+}
+
+// add adds the document and the IDs of its headlines.
+func (l *ProjectLinks) add(name string, ids []string) {
+	l.docs[name] = true
+	for _, id := range ids {
+		l.ids[id] = name
+	}
+}
+
+// documentIDs returns the CUSTOM_ID and ID properties of the headlines.
+func documentIDs(nodes []Node) []string {
+	var ids []string
+	for i := range nodes {
+		if id, ok := headlineID(nodes, i); ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// Resolve returns the relative URL of the link target from the document
+// from. It returns false if the target is not a document of the project.
+func (l *ProjectLinks) Resolve(from, target string) (string, bool) {
+	switch {
+	case strings.HasPrefix(target, "file:"):
+		file, search := splitLinkSearch(strings.TrimPrefix(target, "file:"))
+		name := resolvePath(from, file)
+		if !l.docs[name] {
+			return "", false
+		}
+		return relativeURL(from, Document{Path: name}.HTMLPath()) + linkFragment(search), true
+	case strings.HasPrefix(target, "id:"):
+		id := strings.TrimPrefix(target, "id:")
+		name, ok := l.ids[id]
+		if !ok {
+			return "", false
+		}
+		if name == from {
+			return "#" + id, true
+		}
+		return relativeURL(from, Document{Path: name}.HTMLPath()) + "#" + id, true
+	}
+	return "", false
+}
+
+// relativeURL returns the relative URL of the slash separated path to from
+// the directory of the file from.
+func relativeURL(from, to string) string {
+	fromDirs := strings.Split(path.Dir(from), "/")
+	if fromDirs[0] == "." {
+		fromDirs = nil
+	}
+	toDirs := strings.Split(to, "/")
+	i := 0
+	for i < len(fromDirs) && i < len(toDirs)-1 && fromDirs[i] == toDirs[i] {
+		i++
+	}
+	return strings.Repeat("../", len(fromDirs)-i) + strings.Join(toDirs[i:], "/")
+}
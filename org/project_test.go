@@ -0,0 +1,117 @@
+package org_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	. "github.com/Ladicle/org2html/org"
+)
+
+func TestProjectFiles(t *testing.T) {
+	fsys := fstest.MapFS{
+		"index.org":         {},
+		"style.css":         {},
+		"notes/a.org":       {},
+		"notes/b.org":       {},
+		"notes/draft-c.org": {},
+		"private/d.org":     {},
+	}
+	var tests = []struct {
+		desc      string
+		project   Project
+		wantFiles []string
+	}{
+		{
+			desc:      "all org files",
+			project:   Project{FS: fsys},
+			wantFiles: []string{"index.org", "notes/a.org", "notes/b.org", "notes/draft-c.org", "private/d.org"},
+		},
+		{
+			desc:      "include and exclude",
+			project:   Project{FS: fsys, Include: []string{"notes/*", "index.org"}, Exclude: []string{"draft-*"}},
+			wantFiles: []string{"index.org", "notes/a.org", "notes/b.org"},
+		},
+		{
+			desc:      "exclude directory",
+			project:   Project{FS: fsys, Exclude: []string{"private", "notes/b.org"}},
+			wantFiles: []string{"index.org", "notes/a.org", "notes/draft-c.org"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := tt.project.Files()
+			if err != nil {
+				t.Fatalf("unexpected error: err=%v", err)
+			}
+			if !reflect.DeepEqual(got, tt.wantFiles) {
+				t.Errorf("unexpected files:\ngot=%#v\nwant=%#v", got, tt.wantFiles)
+			}
+		})
+	}
+}
+
+func TestProjectPublish(t *testing.T) {
+	project := Project{FS: fstest.MapFS{
+		"index.org": {Data: []byte(`#+TITLE: Home & Notes
+#+HTML_HEAD: <link rel="stylesheet" href="style.css">
+See [[file:notes/a.org][A]], [[id:b-usage][usage of B]] and [[file:missing.org]].
+`)},
+		"notes/a.org": {Data: []byte("Back to [[file:../index.org][home]] from {{{input-file}}}.\n")},
+		"notes/b.org": {Data: []byte("* Usage\n:PROPERTIES:\n:ID: b-usage\n:END:\n[[id:b-usage][self]]\n")},
+	}}
+	dir := t.TempDir()
+	if err := project.Publish(dir); err != nil {
+		t.Fatalf("unexpected error: err=%v", err)
+	}
+	want := map[string]string{
+		"index.html": `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Home &amp; Notes</title>
+<link rel="stylesheet" href="style.css">
+</head>
+<body>
+<p>See <a href="notes/a.html">A</a>, <a href="notes/b.html#b-usage">usage of B</a> and <a href="missing.html">file:missing.org</a>.</p>
+</body>
+</html>
+`,
+		"notes/a.html": `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>a</title>
+</head>
+<body>
+<p>Back to <a href="../index.html">home</a> from a.org.</p>
+</body>
+</html>
+`,
+		"notes/b.html": `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>b</title>
+</head>
+<body>
+<h1 id="b-usage" class="org-headline">
+Usage
+</h1>
+<p><a href="#b-usage">self</a></p>
+</body>
+</html>
+`,
+	}
+	for name, wantOut := range want {
+		got, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatalf("unexpected error: err=%v", err)
+		}
+		if string(got) != wantOut {
+			t.Errorf("unexpected output of %s:\ngot=%v\nwant=%v", name, string(got), wantOut)
+		}
+	}
+}
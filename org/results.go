@@ -0,0 +1,212 @@
+package org
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const resultsKey = "RESULTS"
+
+var _ Node = Results{}
+
+// Results is a Node to describe the output of a source block such as
+// `#+RESULTS:`. Results just after the source block or named as the block are
+// attached to SourceBlock.Results. Value is a fixed-width area, block, table,
+// list, drawer or link.
+type Results struct {
+	Pos   Pos    `json:"pos"`
+	Name  string `json:"name,omitempty"`
+	Hash  string `json:"hash,omitempty"`
+	Value Nodes  `json:"value,omitempty"`
+}
+
+func (r Results) Write(w io.Writer) error {
+	return r.writeHTML(w, &htmlContext{HTMLWriter: DefaultHTMLWriter()})
+}
+
+func (r Results) writeHTML(w io.Writer, ctx *htmlContext) error {
+	return ctx.writeAll(w, r.Value)
+}
+
+var (
+	resultsKeyRegexp = regexp.MustCompile(`(?i)^RESULTS(?:\[(.*)\])?$`)
+	linkLineRegexp   = regexp.MustCompile(`^\[\[.+\]\]$`)
+)
+
+// isResultsKey reports whether the keyword key is RESULTS or RESULTS[hash].
+func isResultsKey(key string) bool {
+	return resultsKeyRegexp.MatchString(key)
+}
+
+// parseResults parses the results keyword p.tokens[i] and the following
+// element.
+func parseResults(p *Parser, i int) (int, Results, error) {
+	results := Results{
+		Pos:  p.tokens[i].pos,
+		Name: strings.TrimSpace(p.tokens[i].vals[1]),
+	}
+	if m := resultsKeyRegexp.FindStringSubmatch(p.tokens[i].vals[0]); m != nil {
+		results.Hash = m[1]
+	}
+
+	start := i
+	i++
+	if i >= len(p.tokens) {
+		return i - start, results, nil
+	}
+	switch p.tokens[i].kind {
+	case KindText:
+		if linkLineRegexp.MatchString(p.tokens[i].vals[0]) {
+			results.Value = Nodes{Section{Pos: p.tokens[i].pos, Paragraphs: []string{p.tokens[i].vals[0]}}}
+			i++
+		}
+	case KindFixedWidth, KindBlockBegin, KindTableRow, KindTableRule, KindListItem, KindDrawer:
+		fn, ok := p.parseFns[p.tokens[i].kind]
+		if !ok {
+			break
+		}
+		consumed, node, err := fn(p, i)
+		if err != nil {
+			return 0, Results{}, err
+		}
+		if node != nil {
+			results.Value = Nodes{node}
+		}
+		i += consumed
+	}
+	return i - start, results, nil
+}
+
+// attachResults attaches the results just after the source block or named as
+// the block to SourceBlock.Results, and removes them from nodes.
+func attachResults(nodes []Node) []Node {
+	used := make(map[int]bool)
+	for i := range nodes {
+		block, ok := nodes[i].(SourceBlock)
+		if !ok || block.Results != nil {
+			continue
+		}
+		j := -1
+		if i+1 < len(nodes) {
+			if r, ok := nodes[i+1].(Results); ok && (r.Name == "" || r.Name == block.Name) {
+				j = i + 1
+			}
+		}
+		if j < 0 && block.Name != "" {
+			for k := range nodes {
+				if r, ok := nodes[k].(Results); ok && !used[k] && r.Name == block.Name {
+					j = k
+					break
+				}
+			}
+		}
+		if j < 0 {
+			continue
+		}
+		r := nodes[j].(Results)
+		block.Results = &r
+		nodes[i] = block
+		used[j] = true
+	}
+	if len(used) == 0 {
+		return nodes
+	}
+	attached := make([]Node, 0, len(nodes)-len(used))
+	for i := range nodes {
+		if !used[i] {
+			attached = append(attached, nodes[i])
+		}
+	}
+	return attached
+}
+
+// SpliceResults returns src whose results are replaced with the results of
+// the source blocks of evaluated, such as the nodes returned by
+// Evaluator.Evaluate. nodes must be parsed from src. The results are replaced
+// at their positions or inserted after the source blocks, and the other lines
+// of src are not changed.
+func SpliceResults(src []byte, nodes, evaluated []Node) ([]byte, error) {
+	before, after := sourceBlocks(nodes), sourceBlocks(evaluated)
+	if len(before) != len(after) {
+		return nil, fmt.Errorf("evaluated nodes have %d source blocks: want=%d", len(after), len(before))
+	}
+	tokens, err := DefaultTokenizer().Tokenize(bytes.NewReader(src))
+	if err != nil {
+		return nil, err
+	}
+	p := DefaultParser(tokens)
+	lines := strings.SplitAfter(string(src), "\n")
+
+	// edit replaces lines[start:end] with text.
+	type edit struct {
+		start, end int
+		text       string
+	}
+	var edits []edit
+	for i := range before {
+		b, a := before[i], after[i]
+		if a.Results == nil || reflect.DeepEqual(b.Results, a.Results) {
+			continue
+		}
+		if b.Results != nil {
+			start := b.Results.Pos.Line - 1
+			consumed, _, err := parseResults(&p, start)
+			if err != nil {
+				return nil, err
+			}
+			text, err := resultsText(*a.Results, lines[start])
+			if err != nil {
+				return nil, err
+			}
+			edits = append(edits, edit{start: start, end: start + consumed, text: text})
+			continue
+		}
+		end, err := p.blockEnd(b.Pos.Line - 1)
+		if err != nil {
+			return nil, err
+		}
+		if end >= len(lines) {
+			return nil, fmt.Errorf("%s block at line %d is not closed", b.Language, b.Pos.Line)
+		}
+		text, err := resultsText(*a.Results, lines[b.Pos.Line-1])
+		if err != nil {
+			return nil, err
+		}
+		if !strings.HasSuffix(lines[end], "\n") {
+			lines[end] += "\n"
+		}
+		edits = append(edits, edit{start: end + 1, end: end + 1, text: "\n" + text})
+	}
+
+	// apply the edits from the bottom so that the line numbers are kept.
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start > edits[j].start })
+	for _, e := range edits {
+		lines = append(lines[:e.start], append([]string{e.text}, lines[e.end:]...)...)
+	}
+	return []byte(strings.Join(lines, "")), nil
+}
+
+// resultsText returns the Org text of the results with the indentation and
+// the keyword case of the line, which is the source block or the old results.
+func resultsText(r Results, line string) (string, error) {
+	ow := DefaultOrgWriter()
+	ow.UpperCase = strings.Contains(line, "#+RESULTS") || strings.Contains(line, "#+BEGIN_")
+	var buf bytes.Buffer
+	if err := ow.writeNode(&buf, r); err != nil {
+		return "", err
+	}
+	indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+	var text strings.Builder
+	for _, l := range strings.SplitAfter(buf.String(), "\n") {
+		if strings.TrimSpace(l) != "" {
+			text.WriteString(indent)
+		}
+		text.WriteString(l)
+	}
+	return text.String(), nil
+}
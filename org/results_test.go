@@ -0,0 +1,201 @@
+package org_test
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+
+	. "github.com/Ladicle/org2html/org"
+)
+
+func TestParseResults(t *testing.T) {
+	var tests = []struct {
+		desc      string
+		input     string
+		wantNodes []Node
+	}{
+		{
+			desc:  "fixed-width results",
+			input: "#+begin_src sh\necho hello\n#+end_src\n\n#+RESULTS:\n: hello\n:   world\n:\n\ntext\n",
+			wantNodes: []Node{
+				SourceBlock{
+					Language:   "sh",
+					SourceCode: "echo hello",
+					Results: &Results{Value: Nodes{
+						FixedWidth{Content: "hello\n  world\n"},
+					}},
+				},
+				Section{Paragraphs: []string{"text"}},
+			},
+		},
+		{
+			desc:  "block results with hash",
+			input: "#+begin_src sh\necho hello\n#+end_src\n#+RESULTS[a1b2]:\n#+begin_example\nhello\n#+end_example\n",
+			wantNodes: []Node{
+				SourceBlock{
+					Language:   "sh",
+					SourceCode: "echo hello",
+					Results: &Results{Hash: "a1b2", Value: Nodes{
+						Block{Name: "EXAMPLE", Content: "hello"},
+					}},
+				},
+			},
+		},
+		{
+			desc:  "table and link results",
+			input: "#+begin_src sh\n#+end_src\n#+RESULTS:\n| a | 1 |\n#+begin_src sh\n#+end_src\n#+RESULTS:\n[[file:out.png]]\n",
+			wantNodes: []Node{
+				SourceBlock{
+					Language: "sh",
+					Results: &Results{Value: Nodes{
+						Table{Rows: [][]string{{"a", "1"}}},
+					}},
+				},
+				SourceBlock{
+					Language: "sh",
+					Results: &Results{Value: Nodes{
+						Section{Paragraphs: []string{"[[file:out.png]]"}},
+					}},
+				},
+			},
+		},
+		{
+			desc:  "named results",
+			input: "#+RESULTS: hello\n: hello\n\n#+NAME: hello\n#+begin_src sh\necho hello\n#+end_src\n\n#+RESULTS: other\n: other\n",
+			wantNodes: []Node{
+				Keyword{Key: "NAME", Value: "hello"},
+				SourceBlock{
+					Name:       "hello",
+					Language:   "sh",
+					SourceCode: "echo hello",
+					Results: &Results{Name: "hello", Value: Nodes{
+						FixedWidth{Content: "hello"},
+					}},
+				},
+				Results{Name: "other", Value: Nodes{
+					FixedWidth{Content: "other"},
+				}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			nodes := withoutPos(parseString(t, tt.input))
+			if !reflect.DeepEqual(nodes, tt.wantNodes) {
+				t.Errorf("unexpected nodes:\ngot=%#v\nwant=%#v", nodes, tt.wantNodes)
+			}
+		})
+	}
+}
+
+func TestResultsWriter(t *testing.T) {
+	var tests = []struct {
+		desc    string
+		exports string
+		wantOut string
+	}{
+		{
+			desc:    "exports code",
+			exports: "exports code",
+			wantOut: "<div class=\"org-block block-src\">\n<code class=\"block lang-sh\" data-lang=\"sh\">\necho '&lt;a&gt;'\n</code>\n</div>\n",
+		},
+		{
+			desc:    "exports results",
+			exports: "exports results",
+			wantOut: "<pre class=\"example\">\n&lt;a&gt;\n</pre>\n",
+		},
+		{
+			desc:    "exports both",
+			exports: "exports both",
+			wantOut: "<div class=\"org-block block-src\">\n<code class=\"block lang-sh\" data-lang=\"sh\">\necho '&lt;a&gt;'\n</code>\n</div>\n<pre class=\"example\">\n&lt;a&gt;\n</pre>\n",
+		},
+		{
+			desc:    "exports none",
+			exports: "exports none",
+			wantOut: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			block := SourceBlock{
+				Language:   "sh",
+				SourceCode: "echo '<a>'",
+				Property:   []string{tt.exports},
+				Results:    &Results{Value: Nodes{FixedWidth{Content: "<a>"}}},
+			}
+			var out bytes.Buffer
+			if err := block.Write(&out); err != nil {
+				t.Fatalf("unexpected error: err=%v", err)
+			}
+			if got := out.String(); got != tt.wantOut {
+				t.Errorf("unexpected output:\ngot=%v\nwant=%v", got, tt.wantOut)
+			}
+		})
+	}
+}
+
+func TestSpliceResults(t *testing.T) {
+	// the lines except the results are not formatted by OrgWriter.
+	input := `#+TITLE:   Splice
+* Headline      :tag:
+  #+BEGIN_SRC text
+  hello
+  #+END_SRC
+
+
+| a |   b |
+|---+-----|
+
+#+begin_src text
+world
+#+end_src
+
+#+results:
+: old
+: lines
+Text after results.
+#+begin_src text :eval no
+skipped
+#+end_src`
+	want := `#+TITLE:   Splice
+* Headline      :tag:
+  #+BEGIN_SRC text
+  hello
+  #+END_SRC
+
+  #+RESULTS:
+  : HELLO
+
+
+| a |   b |
+|---+-----|
+
+#+begin_src text
+world
+#+end_src
+
+#+results:
+: WORLD
+Text after results.
+#+begin_src text :eval no
+skipped
+#+end_src`
+	nodes := parseString(t, input)
+	var reqs []ExecRequest
+	e := Evaluator{Executors: map[string]Executor{"text": upperExecutor{reqs: &reqs}}}
+	evaluated, err := e.Evaluate(context.Background(), nodes)
+	if err != nil {
+		t.Fatalf("unexpected error: err=%v", err)
+	}
+	got, err := SpliceResults([]byte(input), nodes, evaluated)
+	if err != nil {
+		t.Fatalf("unexpected error: err=%v", err)
+	}
+	if string(got) != want {
+		t.Errorf("unexpected output:\ngot=%v\nwant=%v", string(got), want)
+	}
+	if err := VerifyOrg(evaluated, got); err != nil {
+		t.Errorf("unexpected error: err=%v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package org
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// scriptRegexp matches the sequence of sub/superscripts such as `x_i^2` with
+// the preceding character, which must not be a whitespace. Each script is
+// `{...}`, `*` or alphanumerics such as `x^-1` and `x_1,2`.
+var (
+	scriptRegexp     = regexp.MustCompile(`(\S)((?:[_^](?:\{[^{}]*\}|\*|[+-]?[A-Za-z0-9,.\\]*[A-Za-z0-9]))+)`)
+	scriptPartRegexp = regexp.MustCompile(`([_^])(\{([^{}]*)\}|\*|[+-]?[A-Za-z0-9,.\\]*[A-Za-z0-9])`)
+)
+
+// scriptEnabled reports whether the sub/superscripts are converted with the
+// #+OPTIONS `^:`.
+func scriptEnabled(ctx *htmlContext, m []string) bool {
+	switch ctx.options().SubSuperscripts {
+	case SubSuperscriptBraced:
+		for _, part := range scriptPartRegexp.FindAllStringSubmatch(m[2], -1) {
+			if !strings.HasPrefix(part[2], "{") {
+				return false
+			}
+		}
+		return true
+	case SubSuperscriptDisabled:
+		return false
+	}
+	return true
+}
+
+// writeScript writes the sub/superscripts as HTML. Entities in the scripts
+// such as `x^{\alpha}` are converted.
+func writeScript(w io.Writer, ctx *htmlContext, m []string) {
+	io.WriteString(w, codeEscaper.Replace(m[1]))
+	for _, part := range scriptPartRegexp.FindAllStringSubmatch(m[2], -1) {
+		tag := "sub"
+		if part[1] == "^" {
+			tag = "sup"
+		}
+		script := part[2]
+		if strings.HasPrefix(script, "{") {
+			script = part[3]
+		}
+		fmt.Fprintf(w, "<%s>", tag)
+		writeEntities(w, ctx, script)
+		fmt.Fprintf(w, "</%s>", tag)
+	}
+}
+
+// writeEntities writes the escaped text whose entities are converted.
+func writeEntities(w io.Writer, ctx *htmlContext, text string) {
+	for {
+		loc := entityRegexp.FindStringSubmatchIndex(text)
+		if loc == nil {
+			break
+		}
+		io.WriteString(w, codeEscaper.Replace(text[:loc[0]]))
+		writeEntity(w, ctx, []string{text[loc[0]:loc[1]], text[loc[2]:loc[3]]})
+		text = text[loc[1]:]
+	}
+	io.WriteString(w, codeEscaper.Replace(text))
+}
@@ -0,0 +1,48 @@
+package org_test
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/Ladicle/org2html/org"
+)
+
+func TestScriptWriter(t *testing.T) {
+	var tests = []struct {
+		desc    string
+		input   string
+		wantOut string
+	}{
+		{
+			desc:    "enabled",
+			input:   "CO_2, x^{n+1}, e^-1, a^* and x_i^{\\alpha} but _a and x_ y\n",
+			wantOut: "<p>CO<sub>2</sub>, x<sup>n+1</sup>, e<sup>-1</sup>, a<sup>*</sup> and x<sub>i</sub><sup>&alpha;</sup> but _a and x_ y</p>\n",
+		},
+		{
+			desc:    "braced only",
+			input:   "#+OPTIONS: ^:{}\nsnake_case, x_i^{2}, SO_{4}^{2-} and H_{2}O\n",
+			wantOut: "<p>snake_case, x_i<sup>2</sup>, SO<sub>4</sub><sup>2-</sup> and H<sub>2</sub>O</p>\n",
+		},
+		{
+			desc:    "disabled",
+			input:   "#+OPTIONS: ^:nil\nH_{2}O and x^2\n",
+			wantOut: "<p>H_{2}O and x^2</p>\n",
+		},
+		{
+			desc:    "escaped",
+			input:   "<a>_{<b>}\n",
+			wantOut: "<p>&lt;a&gt;<sub>&lt;b&gt;</sub></p>\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := Write(parseString(t, tt.input), &out); err != nil {
+				t.Fatalf("unexpected error: err=%v", err)
+			}
+			if got := out.String(); got != tt.wantOut {
+				t.Errorf("unexpected output:\ngot=%v\nwant=%v", got, tt.wantOut)
+			}
+		})
+	}
+}
@@ -9,32 +9,82 @@ import (
 
 var _ Node = Section{}
 
+// Section is a Node to describe paragraphs. Lines of the paragraph are
+// separated by a newline to write them back as Org text, and they are joined
+// with spaces in HTML and Markdown.
 type Section struct {
-	Paragraphs []string
+	Pos        Pos      `json:"pos"`
+	Paragraphs []string `json:"paragraphs"`
 }
 
 func (s Section) Write(w io.Writer) error {
+	return s.writeHTML(w, &htmlContext{HTMLWriter: DefaultHTMLWriter()})
+}
+
+func (s Section) writeHTML(w io.Writer, ctx *htmlContext) error {
 	for i := range s.Paragraphs {
-		fmt.Fprintf(w, "<p>%s</p>\n", s.Paragraphs[i])
+		fmt.Fprint(w, "<p>")
+		ctx.writeInline(w, joinLines(s.Paragraphs[i]))
+		fmt.Fprintln(w, "</p>")
 	}
 	return nil
 }
 
+// textLine returns the line of p.tokens[i] if it is a part of paragraphs.
+// Drawer and clock lines are text if they are not valid drawers and clocks.
+func (p *Parser) textLine(i int) (string, bool) {
+	t := p.tokens[i]
+	switch {
+	case t.kind == KindText:
+		return t.vals[0], true
+	case t.kind == KindDrawer && p.drawerEnd(i) < 0:
+		return drawerText(t), true
+	case t.kind == KindClock && !validClock(t):
+		return strings.TrimSpace(t.text()), true
+	}
+	return "", false
+}
+
+// joinLines joins the lines of the paragraph with spaces. Newlines after the
+// line breaks `\\` are kept.
+func joinLines(para string) string {
+	lines := strings.Split(para, "\n")
+	var b strings.Builder
+	for i, line := range lines {
+		b.WriteString(line)
+		switch {
+		case i == len(lines)-1:
+		case strings.HasSuffix(line, `\\`):
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(' ')
+		}
+	}
+	return b.String()
+}
+
 func LexText(line string) (Token, bool) {
 	return NewToken(KindText, 1, []string{strings.TrimSpace(line)}), true
 }
 
+// ParseSection parses paragraphs. Lines which look like other elements but
+// are not valid, such as `:smile: text`, are also the lines of paragraphs. It
+// returns nil Node if the tokens are only blank lines.
 func ParseSection(p *Parser, i int) (int, Node, error) {
 	var (
 		buf        bytes.Buffer
 		paragraphs []string
+		pos        Pos
 	)
 	start, end := i, len(p.tokens)
-	for i < end && p.tokens[i].kind == KindText {
-		if len(p.tokens[i].vals) == 0 {
+	for i < end {
+		if p.tokens[i].kind == KindText && len(p.tokens[i].vals) == 0 {
 			return 0, nil, fmt.Errorf("section token[%d] does not have any values", i)
 		}
-		line := p.tokens[i].vals[0]
+		line, ok := p.textLine(i)
+		if !ok {
+			break
+		}
 		i++
 		// start new paragraph
 		if line == "" {
@@ -45,7 +95,9 @@ func ParseSection(p *Parser, i int) (int, Node, error) {
 			continue
 		}
 		if buf.Len() > 0 {
-			buf.WriteString(" ")
+			buf.WriteString("\n")
+		} else if len(paragraphs) == 0 {
+			pos = p.tokens[i-1].pos
 		}
 		buf.WriteString(line)
 	}
@@ -53,5 +105,8 @@ func ParseSection(p *Parser, i int) (int, Node, error) {
 	if para := buf.String(); para != "" {
 		paragraphs = append(paragraphs, para)
 	}
-	return i - start, Section{Paragraphs: paragraphs}, nil
+	if len(paragraphs) == 0 {
+		return i - start, nil, nil
+	}
+	return i - start, Section{Pos: pos, Paragraphs: paragraphs}, nil
 }
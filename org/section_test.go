@@ -67,7 +67,7 @@ func TestParseSection(t *testing.T) {
 				NewToken(KindText, 1, []string{"line1."}),
 				NewToken(KindText, 1, []string{"line2."}),
 			},
-			wantNode:     Section{Paragraphs: []string{"line1. line2."}},
+			wantNode:     Section{Paragraphs: []string{"line1.\nline2."}},
 			wantConsumed: 2,
 		},
 		{
@@ -78,9 +78,17 @@ func TestParseSection(t *testing.T) {
 				NewToken(KindText, 1, []string{"paragraph2."}),
 				NewToken(KindText, 1, []string{"..."}),
 			},
-			wantNode:     Section{Paragraphs: []string{"paragraph1.", "paragraph2. ..."}},
+			wantNode:     Section{Paragraphs: []string{"paragraph1.", "paragraph2.\n..."}},
 			wantConsumed: 4,
 		},
+		{
+			desc: "only blank lines",
+			tokens: []Token{
+				NewToken(KindText, 1, []string{""}),
+				NewToken(KindText, 1, []string{""}),
+			},
+			wantConsumed: 2,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.desc, func(t *testing.T) {
@@ -0,0 +1,28 @@
+package org
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	setupfileRegexp = regexp.MustCompile(`(?i)^\s*#\+SETUPFILE:\s*(.*?)\s*$`)
+	// settingRegexp matches the keywords which are merged from setup files.
+	settingRegexp = regexp.MustCompile(`(?i)^\s*#\+(OPTIONS|(?:SEQ_|TYP_)?TODO|MACRO|LINK|HTML_HEAD(?:_EXTRA)?):`)
+)
+
+// setupLines returns the setting keywords of the setup file such as
+// #+OPTIONS and #+MACRO. Setup files can contain other setup files.
+func (l Loader) setupLines(name string, stack []string) ([]string, error) {
+	lines, err := l.readLines(name, stack, nil)
+	if err != nil {
+		return nil, err
+	}
+	var settings []string
+	for _, line := range lines {
+		if settingRegexp.MatchString(line) {
+			settings = append(settings, strings.TrimSpace(line))
+		}
+	}
+	return settings, nil
+}
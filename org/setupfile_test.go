@@ -0,0 +1,85 @@
+package org_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	. "github.com/Ladicle/org2html/org"
+)
+
+func TestLoaderSetupfile(t *testing.T) {
+	home := fstest.MapFS{
+		"doc/setup.org":  {Data: []byte("#+TITLE: ignored\n#+OPTIONS: e:nil\n#+todo: TODO WAIT | DONE\n#+MACRO: who Alice\n#+SETUPFILE: \"common.org\"\ntext is ignored\n")},
+		"doc/common.org": {Data: []byte("#+LINK: gh https://github.com/%s\n#+HTML_HEAD: <link rel=\"stylesheet\" href=\"style.css\">\n")},
+	}
+	var tests = []struct {
+		desc      string
+		input     string
+		home      fstest.MapFS
+		wantOut   string
+		wantError error
+	}{
+		{
+			desc:    "home directory",
+			input:   "#+setupfile: ~/doc/setup.org\n#+OPTIONS: -:nil\ntext\n",
+			home:    home,
+			wantOut: "#+OPTIONS: e:nil\n#+todo: TODO WAIT | DONE\n#+MACRO: who Alice\n#+LINK: gh https://github.com/%s\n#+HTML_HEAD: <link rel=\"stylesheet\" href=\"style.css\">\n#+OPTIONS: -:nil\ntext\n",
+		},
+		{
+			desc:    "relative path",
+			input:   "#+SETUPFILE: setup/common.org\n",
+			wantOut: "#+OPTIONS: ^:{}\n",
+		},
+		{
+			desc:      "home directory is not available",
+			input:     "#+SETUPFILE: ~/doc/setup.org\n",
+			wantError: errors.New("input.org:1: home directory is not available: ~/doc/setup.org"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			loader := Loader{FS: fstest.MapFS{
+				"input.org":        {Data: []byte(tt.input)},
+				"setup/common.org": {Data: []byte("#+OPTIONS: ^:{}\n")},
+			}}
+			if tt.home != nil {
+				loader.HomeFS = tt.home
+			}
+			got, err := loader.ReadFile("input.org")
+			if err != nil {
+				if tt.wantError == nil || err.Error() != tt.wantError.Error() {
+					t.Fatalf("unexpected error: err=%v, want=%v", err, tt.wantError)
+				}
+				return
+			} else if tt.wantError != nil {
+				t.Fatalf("expect error but not occurred: want=%v", tt.wantError)
+			}
+			if string(got) != tt.wantOut {
+				t.Errorf("unexpected output:\ngot=%q\nwant=%q", got, tt.wantOut)
+			}
+		})
+	}
+}
+
+func TestLoaderSetupfileSettings(t *testing.T) {
+	loader := Loader{
+		FS:     fstest.MapFS{"index.org": {Data: []byte("#+SETUPFILE: ~/setup.org\n\\alpha by {{{who}}}\n")}},
+		HomeFS: fstest.MapFS{"setup.org": {Data: []byte("#+OPTIONS: e:nil\n#+MACRO: who Alice\n")}},
+	}
+	nodes, err := loader.Load("index.org")
+	if err != nil {
+		t.Fatalf("unexpected error: err=%v", err)
+	}
+	if nodes, err = ExpandMacros(nodes, MacroEnv{}); err != nil {
+		t.Fatalf("unexpected error: err=%v", err)
+	}
+	var out bytes.Buffer
+	if err := Write(nodes, &out); err != nil {
+		t.Fatalf("unexpected error: err=%v", err)
+	}
+	if got, want := out.String(), "<p>\\alpha by Alice</p>\n"; got != want {
+		t.Errorf("unexpected output:\ngot=%q\nwant=%q", got, want)
+	}
+}
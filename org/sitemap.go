@@ -0,0 +1,206 @@
+package org
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+)
+
+// SitemapGroup is the grouping of the documents in the sitemap page.
+type SitemapGroup string
+
+const (
+	// SitemapGroupNone lists all documents in a list.
+	SitemapGroupNone SitemapGroup = ""
+	// SitemapGroupDirectory groups the documents by the directories.
+	SitemapGroupDirectory SitemapGroup = "directory"
+	// SitemapGroupTag groups the documents by #+FILETAGS. Documents with
+	// multiple tags are listed in each group.
+	SitemapGroupTag SitemapGroup = "tag"
+)
+
+// SitemapSort is the order of the documents in the sitemap page.
+type SitemapSort string
+
+const (
+	// SitemapSortTitle sorts the documents by the titles.
+	SitemapSortTitle SitemapSort = "title"
+	// SitemapSortDate sorts the documents by #+DATE. Documents without the
+	// date are listed at the end.
+	SitemapSortDate SitemapSort = "date"
+	// SitemapSortPath sorts the documents by the paths.
+	SitemapSortPath SitemapSort = "path"
+)
+
+const (
+	defaultSitemapFileName = "sitemap.org"
+	defaultSitemapTitle    = "Sitemap"
+	untaggedGroup          = "Untagged"
+)
+
+// Sitemap is the settings of the index page of the published documents like
+// the :auto-sitemap of org-publish.
+type Sitemap struct {
+	// FileName is the path of the page in the project. The page is written
+	// as the HTML file of it. "sitemap.org" is used if it is empty.
+	FileName string
+	// Title is the title of the page. "Sitemap" is used if it is empty.
+	Title string
+	// GroupBy is the grouping of the documents.
+	GroupBy SitemapGroup
+	// SortBy is the order of the documents. SitemapSortTitle is used if it
+	// is empty.
+	SortBy SitemapSort
+	// Reverse reverses the order such as the newest first.
+	Reverse bool
+}
+
+func (s Sitemap) fileName() string {
+	if s.FileName == "" {
+		return defaultSitemapFileName
+	}
+	return s.FileName
+}
+
+// Document returns the page which lists the documents as links. The page
+// itself is not listed.
+func (s Sitemap) Document(docs []Document) (Document, error) {
+	var listed []Document
+	for _, doc := range docs {
+		if doc.Path != s.fileName() {
+			listed = append(listed, doc)
+		}
+	}
+	if err := s.sort(listed); err != nil {
+		return Document{}, err
+	}
+	groups, names, err := s.group(listed)
+	if err != nil {
+		return Document{}, err
+	}
+
+	title := s.Title
+	if title == "" {
+		title = defaultSitemapTitle
+	}
+	nodes := []Node{Keyword{Key: "TITLE", Value: title}}
+	for _, name := range names {
+		if name != "" {
+			nodes = append(nodes, Headline{Starts: 1, Title: name})
+		}
+		list := List{}
+		for _, doc := range groups[name] {
+			content := fmt.Sprintf("[[file:%s][%s]]", relativeURL(s.fileName(), doc.Path), doc.Title())
+			if date, ok := doc.Date(); ok {
+				content += date.Format(" (2006-01-02)")
+			}
+			list.Items = append(list.Items, ListItem{Bullet: "-", Content: content})
+		}
+		nodes = append(nodes, list)
+	}
+	return Document{Path: s.fileName(), Nodes: nodes}, nil
+}
+
+// sort sorts the documents stably by SortBy.
+func (s Sitemap) sort(docs []Document) error {
+	var less func(a, b Document) bool
+	switch s.SortBy {
+	case SitemapSortTitle, "":
+		less = func(a, b Document) bool { return strings.ToLower(a.Title()) < strings.ToLower(b.Title()) }
+	case SitemapSortDate:
+		less = func(a, b Document) bool {
+			da, okA := a.Date()
+			db, okB := b.Date()
+			return okA && (!okB || da.Before(db))
+		}
+	case SitemapSortPath:
+		less = func(a, b Document) bool { return a.Path < b.Path }
+	default:
+		return fmt.Errorf("unsupported sitemap sort: %q", s.SortBy)
+	}
+	sort.SliceStable(docs, func(i, j int) bool {
+		if s.Reverse {
+			return less(docs[j], docs[i])
+		}
+		return less(docs[i], docs[j])
+	})
+	return nil
+}
+
+// group returns the documents by the group names, and the sorted names. The
+// name of the root directory and SitemapGroupNone is empty.
+func (s Sitemap) group(docs []Document) (map[string][]Document, []string, error) {
+	groups := make(map[string][]Document)
+	for _, doc := range docs {
+		switch s.GroupBy {
+		case SitemapGroupNone:
+			groups[""] = append(groups[""], doc)
+		case SitemapGroupDirectory:
+			dir := path.Dir(doc.Path)
+			if dir == "." {
+				dir = ""
+			}
+			groups[dir] = append(groups[dir], doc)
+		case SitemapGroupTag:
+			tags := doc.Tags()
+			if len(tags) == 0 {
+				tags = []string{untaggedGroup}
+			}
+			for _, tag := range tags {
+				groups[tag] = append(groups[tag], doc)
+			}
+		default:
+			return nil, nil, fmt.Errorf("unsupported sitemap group: %q", s.GroupBy)
+		}
+	}
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return groups, names, nil
+}
+
+// sitemapURLSet is the XML of the sitemap protocol.
+// https://www.sitemaps.org/protocol.html
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 urlset"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// WriteSitemapXML writes sitemap.xml of the documents for search engines.
+// The URLs are the HTML files in baseURL. The last modification date is the
+// modification time of the file, or #+DATE if it is unknown.
+func WriteSitemapXML(w io.Writer, docs []Document, baseURL string) error {
+	var set sitemapURLSet
+	for _, doc := range docs {
+		u := sitemapURL{Loc: absoluteURL(baseURL, doc.HTMLPath())}
+		if !doc.ModTime.IsZero() {
+			u.LastMod = doc.ModTime.Format("2006-01-02")
+		} else if date, ok := doc.Date(); ok {
+			u.LastMod = date.Format("2006-01-02")
+		}
+		set.URLs = append(set.URLs, u)
+	}
+	io.WriteString(w, xml.Header)
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(set); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// absoluteURL returns the URL of the slash separated path in baseURL.
+func absoluteURL(baseURL, name string) string {
+	return strings.TrimSuffix(baseURL, "/") + "/" + name
+}
@@ -0,0 +1,109 @@
+package org_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/Ladicle/org2html/org"
+)
+
+func sitemapDocuments() []Document {
+	return []Document{
+		{Path: "index.org", Nodes: []Node{Keyword{Key: "TITLE", Value: "home"}}},
+		{Path: "notes/a.org", Nodes: []Node{
+			Keyword{Key: "TITLE", Value: "Beta"},
+			Keyword{Key: "DATE", Value: "<2022-02-03 Thu>"},
+			Keyword{Key: "FILETAGS", Value: ":go:org:"},
+		}},
+		{Path: "notes/b.org", Nodes: []Node{
+			Keyword{Key: "DATE", Value: "2022-01-30"},
+			Keyword{Key: "FILETAGS", Value: ":org:"},
+		}},
+		{Path: "sitemap.org"},
+	}
+}
+
+func TestSitemapDocument(t *testing.T) {
+	var tests = []struct {
+		desc      string
+		sitemap   Sitemap
+		wantOut   string
+		wantError error
+	}{
+		{
+			desc:    "sort by title",
+			sitemap: Sitemap{},
+			wantOut: "#+title: Sitemap\n\n- [[file:notes/b.org][b]] (2022-01-30)\n- [[file:notes/a.org][Beta]] (2022-02-03)\n- [[file:index.org][home]]\n",
+		},
+		{
+			desc:    "newest first by directory",
+			sitemap: Sitemap{FileName: "pages/index.org", Title: "Index", GroupBy: SitemapGroupDirectory, SortBy: SitemapSortDate, Reverse: true},
+			wantOut: "#+title: Index\n\n- [[file:../index.org][home]]\n- [[file:../sitemap.org][sitemap]]\n\n" +
+				"* notes\n\n- [[file:../notes/a.org][Beta]] (2022-02-03)\n- [[file:../notes/b.org][b]] (2022-01-30)\n",
+		},
+		{
+			desc:    "group by tag",
+			sitemap: Sitemap{GroupBy: SitemapGroupTag, SortBy: SitemapSortPath},
+			wantOut: "#+title: Sitemap\n\n* Untagged\n\n- [[file:index.org][home]]\n\n* go\n\n- [[file:notes/a.org][Beta]] (2022-02-03)\n\n" +
+				"* org\n\n- [[file:notes/a.org][Beta]] (2022-02-03)\n- [[file:notes/b.org][b]] (2022-01-30)\n",
+		},
+		{
+			desc:      "unsupported sort",
+			sitemap:   Sitemap{SortBy: "size"},
+			wantError: errors.New(`unsupported sitemap sort: "size"`),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			doc, err := tt.sitemap.Document(sitemapDocuments())
+			if err != nil {
+				if tt.wantError == nil || err.Error() != tt.wantError.Error() {
+					t.Fatalf("unexpected error: err=%v, want=%v", err, tt.wantError)
+				}
+				return
+			} else if tt.wantError != nil {
+				t.Fatalf("expect error but not occurred: want=%v", tt.wantError)
+			}
+			var out bytes.Buffer
+			if err := DefaultOrgWriter().Write(doc.Nodes, &out); err != nil {
+				t.Fatalf("unexpected error: err=%v", err)
+			}
+			if got := out.String(); got != tt.wantOut {
+				t.Errorf("unexpected output:\ngot=%q\nwant=%q", got, tt.wantOut)
+			}
+		})
+	}
+}
+
+func TestWriteSitemapXML(t *testing.T) {
+	docs := sitemapDocuments()
+	docs[0].ModTime = time.Date(2022, 3, 4, 5, 6, 7, 0, time.UTC)
+	var out bytes.Buffer
+	if err := WriteSitemapXML(&out, docs, "https://example.com/notes/"); err != nil {
+		t.Fatalf("unexpected error: err=%v", err)
+	}
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url>
+    <loc>https://example.com/notes/index.html</loc>
+    <lastmod>2022-03-04</lastmod>
+  </url>
+  <url>
+    <loc>https://example.com/notes/notes/a.html</loc>
+    <lastmod>2022-02-03</lastmod>
+  </url>
+  <url>
+    <loc>https://example.com/notes/notes/b.html</loc>
+    <lastmod>2022-01-30</lastmod>
+  </url>
+  <url>
+    <loc>https://example.com/notes/sitemap.html</loc>
+  </url>
+</urlset>
+`
+	if got := out.String(); got != want {
+		t.Errorf("unexpected output:\ngot=%v\nwant=%v", got, want)
+	}
+}
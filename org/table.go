@@ -0,0 +1,110 @@
+package org
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+var _ Node = Table{}
+
+// Table is a Node to describe org tables. A nil row describes a horizontal
+// rule such as |---+---|.
+type Table struct {
+	Pos  Pos        `json:"pos"`
+	Rows [][]string `json:"rows"`
+}
+
+// header returns the number of header rows. Rows before the first horizontal
+// rule are header rows if the rule is followed by other rows.
+func (t Table) header() int {
+	for i, row := range t.Rows {
+		if row != nil {
+			continue
+		}
+		for _, rest := range t.Rows[i+1:] {
+			if rest != nil {
+				return i
+			}
+		}
+		break
+	}
+	return 0
+}
+
+func (t Table) Write(w io.Writer) error {
+	return t.writeHTML(w, &htmlContext{HTMLWriter: DefaultHTMLWriter()})
+}
+
+func (t Table) writeHTML(w io.Writer, ctx *htmlContext) error {
+	fmt.Fprintln(w, `<table class="org-table">`)
+	header := t.header()
+	if header > 0 {
+		fmt.Fprintln(w, "<thead>")
+		for _, row := range t.Rows[:header] {
+			ctx.writeTableRow(w, "th", row)
+		}
+		fmt.Fprintln(w, "</thead>")
+	}
+	fmt.Fprintln(w, "<tbody>")
+	for _, row := range t.Rows[header:] {
+		if row != nil {
+			ctx.writeTableRow(w, "td", row)
+		}
+	}
+	fmt.Fprintln(w, "</tbody>")
+	fmt.Fprintln(w, "</table>")
+	return nil
+}
+
+func (ctx *htmlContext) writeTableRow(w io.Writer, tag string, row []string) {
+	fmt.Fprint(w, "<tr>")
+	for _, cell := range row {
+		fmt.Fprintf(w, "<%s>", tag)
+		ctx.writeInline(w, cell)
+		fmt.Fprintf(w, "</%s>", tag)
+	}
+	fmt.Fprintln(w, "</tr>")
+}
+
+var (
+	tableRuleRegexp = regexp.MustCompile(`^\s*\|-`)
+	tableRowRegexp  = regexp.MustCompile(`^\s*\|(.*)`)
+)
+
+func LexTable(line string) (Token, bool) {
+	if tableRuleRegexp.MatchString(line) {
+		return NewToken(KindTableRule, 1, []string{}), true
+	} else if m := tableRowRegexp.FindStringSubmatch(line); m != nil {
+		return NewToken(KindTableRow, 1, m[1:]), true
+	}
+	return Token{}, false
+}
+
+func ParseTable(p *Parser, i int) (int, Node, error) {
+	var (
+		table = Table{Pos: p.tokens[i].pos}
+		start = i
+	)
+	for ; i < len(p.tokens); i++ {
+		switch p.tokens[i].kind {
+		case KindTableRule:
+			table.Rows = append(table.Rows, nil)
+			continue
+		case KindTableRow:
+		default:
+			return i - start, table, nil
+		}
+		if len(p.tokens[i].vals) != 1 {
+			return 0, nil, fmt.Errorf("table token[%d] does not have any values", i)
+		}
+		cells := strings.Split(strings.TrimSuffix(strings.TrimSpace(p.tokens[i].vals[0]), "|"), "|")
+		row := make([]string, len(cells))
+		for j := range cells {
+			row[j] = strings.TrimSpace(cells[j])
+		}
+		table.Rows = append(table.Rows, row)
+	}
+	return i - start, table, nil
+}
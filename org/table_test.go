@@ -0,0 +1,126 @@
+package org_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	. "github.com/Ladicle/org2html/org"
+)
+
+func TestLexTable(t *testing.T) {
+	var tests = []struct {
+		desc      string
+		line      string
+		wantToken Token
+		wantFlag  bool
+	}{
+		{
+			desc:      "empty line",
+			line:      "",
+			wantToken: Token{},
+		},
+		{
+			desc:      "table row",
+			line:      "  | a | b |",
+			wantFlag:  true,
+			wantToken: NewToken(KindTableRow, 1, []string{" a | b |"}),
+		},
+		{
+			desc:      "table rule",
+			line:      "|---+---|",
+			wantFlag:  true,
+			wantToken: NewToken(KindTableRule, 1, []string{}),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			token, flag := LexTable(tt.line)
+			if flag != tt.wantFlag {
+				t.Errorf("unexpected flag: got=%v, want=%v", flag, tt.wantFlag)
+			}
+			if !reflect.DeepEqual(token, tt.wantToken) {
+				t.Errorf("unexpected token:\ngot=%#v\nwant=%#v", token, tt.wantToken)
+			}
+		})
+	}
+}
+
+func TestParseTable(t *testing.T) {
+	var tests = []struct {
+		desc         string
+		tokens       []Token
+		wantConsumed int
+		wantNode     Node
+	}{
+		{
+			desc: "table with header",
+			tokens: []Token{
+				NewToken(KindTableRow, 1, []string{" name | value |"}),
+				NewToken(KindTableRule, 1, []string{}),
+				NewToken(KindTableRow, 1, []string{" a    |     1 "}),
+				NewToken(KindText, 1, []string{""}),
+			},
+			wantNode:     Table{Rows: [][]string{{"name", "value"}, nil, {"a", "1"}}},
+			wantConsumed: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			parser := DefaultParser(tt.tokens)
+			consumed, node, err := ParseTable(&parser, 0)
+			if err != nil {
+				t.Fatalf("unexpected error: err=%v", err)
+			}
+			if consumed != tt.wantConsumed {
+				t.Errorf("unexpected consumed: got=%v, want=%v", consumed, tt.wantConsumed)
+			}
+			if !reflect.DeepEqual(node, tt.wantNode) {
+				t.Errorf("unexpected node:\ngot=%#v\nwant=%#v", node, tt.wantNode)
+			}
+		})
+	}
+}
+
+func TestTableWriter(t *testing.T) {
+	var tests = []struct {
+		desc    string
+		table   Table
+		wantOut string
+	}{
+		{
+			desc:  "table without header",
+			table: Table{Rows: [][]string{{"a", "b"}, nil}},
+			wantOut: `<table class="org-table">
+<tbody>
+<tr><td>a</td><td>b</td></tr>
+</tbody>
+</table>
+`,
+		},
+		{
+			desc:  "table with header",
+			table: Table{Rows: [][]string{{"name", "value"}, nil, {"a", "1"}}},
+			wantOut: `<table class="org-table">
+<thead>
+<tr><th>name</th><th>value</th></tr>
+</thead>
+<tbody>
+<tr><td>a</td><td>1</td></tr>
+</tbody>
+</table>
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := tt.table.Write(&out); err != nil {
+				t.Fatalf("unexpected error: err=%v", err)
+			}
+			if got := out.String(); got != tt.wantOut {
+				t.Errorf("unexpected output: got=%v, want=%v", got, tt.wantOut)
+			}
+		})
+	}
+}
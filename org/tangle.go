@@ -0,0 +1,181 @@
+package org
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// tangleExts maps languages to the file extension of `:tangle yes`.
+var tangleExts = map[string]string{
+	"bash":       "sh",
+	"shell":      "sh",
+	"zsh":        "sh",
+	"python":     "py",
+	"golang":     "go",
+	"javascript": "js",
+	"typescript": "ts",
+	"emacs-lisp": "el",
+	"elisp":      "el",
+	"ruby":       "rb",
+	"yaml":       "yml",
+	"markdown":   "md",
+}
+
+// TangledFile is a source file extracted from source blocks.
+type TangledFile struct {
+	// Path is the slash-separated file path. Relative paths in `:tangle` are
+	// resolved from the directory of the org file. Paths in the home
+	// directory keep the `~/` prefix.
+	Path    string
+	Content string
+	// Executable is true if the file starts with the `:shebang` line.
+	Executable bool
+	// Mkdirp is true if the parent directories should be created.
+	Mkdirp bool
+}
+
+var nowebRefRegexp = regexp.MustCompile(`<<([^<>\s]+)>>`)
+
+// Tangle extracts source blocks with the `:tangle` header argument into files
+// like org-babel-tangle. Blocks for the same file are concatenated in the
+// document order. `:tangle yes` writes the block to the org file name with the
+// extension of the language.
+func Tangle(nodes []Node, orgFile string) ([]TangledFile, error) {
+	var (
+		blocks = sourceBlocks(nodes)
+		refs   = make(map[string][]SourceBlock)
+		files  []TangledFile
+		index  = make(map[string]int)
+	)
+	for _, b := range blocks {
+		args := b.HeaderArgs()
+		if b.Name != "" {
+			refs[b.Name] = append(refs[b.Name], b)
+		}
+		if ref, ok := args.Get("noweb-ref"); ok && ref != "" && ref != b.Name {
+			refs[ref] = append(refs[ref], b)
+		}
+	}
+
+	for _, b := range blocks {
+		args := b.HeaderArgs()
+		target := unquote(args.Tangle)
+		switch target {
+		case "", "no":
+			continue
+		case "yes":
+			ext, ok := tangleExts[b.Language]
+			if !ok {
+				ext = b.Language
+			}
+			target = strings.TrimSuffix(path.Base(orgFile), path.Ext(orgFile)) + "." + ext
+		}
+		switch {
+		case strings.HasPrefix(target, homePrefix):
+			target = homePrefix + path.Clean(strings.TrimPrefix(target, homePrefix))
+		case !path.IsAbs(target):
+			target = path.Join(path.Dir(orgFile), target)
+		}
+
+		code, err := tangleCode(b, refs, nil)
+		if err != nil {
+			return nil, err
+		}
+		if code != "" {
+			code += "\n"
+		}
+
+		i, ok := index[target]
+		if !ok {
+			i = len(files)
+			index[target] = i
+			files = append(files, TangledFile{Path: target})
+			if shebang, ok := args.Get("shebang"); ok && shebang != "" {
+				files[i].Content = unquote(shebang) + "\n"
+				files[i].Executable = true
+			}
+		} else if padline, _ := args.Get("padline"); padline != "no" {
+			// blocks are separated by a blank line unless `:padline no`.
+			files[i].Content += "\n"
+		}
+		files[i].Content += code
+		if args.enabled("mkdirp") {
+			files[i].Mkdirp = true
+		}
+	}
+	return files, nil
+}
+
+// tangleCode returns the source code whose noweb references such as
+// `<<name>>` are expanded if `:noweb` allows it. Continuation lines of the
+// expanded code are prefixed with the text before the reference.
+func tangleCode(b SourceBlock, refs map[string][]SourceBlock, stack []string) (string, error) {
+	switch b.HeaderArgs().Noweb {
+	case "yes", "tangle", "no-export", "strip-export":
+	case "strip-tangle":
+		return nowebRefRegexp.ReplaceAllString(b.SourceCode, ""), nil
+	default:
+		return b.SourceCode, nil
+	}
+
+	lines := strings.Split(b.SourceCode, "\n")
+	for i, line := range lines {
+		loc := nowebRefRegexp.FindStringIndex(line)
+		if loc == nil {
+			continue
+		}
+		prefix := line[:loc[0]]
+		var err error
+		lines[i] = nowebRefRegexp.ReplaceAllStringFunc(line, func(ref string) string {
+			name := nowebRefRegexp.FindStringSubmatch(ref)[1]
+			for _, s := range stack {
+				if s == name {
+					err = fmt.Errorf("noweb reference %q is recursive", name)
+					return ref
+				}
+			}
+			var codes []string
+			for _, rb := range refs[name] {
+				code, e := tangleCode(rb, refs, append(stack, name))
+				if e != nil {
+					err = e
+					return ref
+				}
+				codes = append(codes, code)
+			}
+			// unresolved references are expanded to empty like Org mode.
+			return strings.ReplaceAll(strings.Join(codes, "\n"), "\n", "\n"+prefix)
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// sourceBlocks returns the source blocks in the document order including the
+// blocks in greater blocks and drawers.
+func sourceBlocks(nodes []Node) []SourceBlock {
+	var blocks []SourceBlock
+	for _, node := range nodes {
+		switch n := node.(type) {
+		case SourceBlock:
+			blocks = append(blocks, n)
+		case Block:
+			blocks = append(blocks, sourceBlocks(n.Children)...)
+		case Drawer:
+			blocks = append(blocks, sourceBlocks(n.Children)...)
+		}
+	}
+	return blocks
+}
+
+// unquote removes the double quotes around the header argument value.
+func unquote(s string) string {
+	if len(s) >= 2 && strings.HasPrefix(s, `"`) && strings.HasSuffix(s, `"`) {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
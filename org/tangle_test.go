@@ -0,0 +1,145 @@
+package org_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	. "github.com/Ladicle/org2html/org"
+)
+
+func TestTangle(t *testing.T) {
+	var tests = []struct {
+		desc      string
+		input     string
+		wantFiles []TangledFile
+		wantError error
+	}{
+		{
+			desc:  "no tangle",
+			input: "#+begin_src sh\necho hello\n#+end_src\n",
+		},
+		{
+			desc: "tangle yes and paths",
+			input: `#+begin_src bash :tangle yes
+echo 1
+#+end_src
+
+#+begin_src bash :tangle yes :padline no
+echo 2
+#+end_src
+
+#+begin_src go :tangle "cmd/main.go" :mkdirp yes
+package main
+#+end_src
+
+#+begin_src python :tangle /tmp/a.py :shebang "#!/usr/bin/env python3"
+print(1)
+#+end_src
+
+#+begin_src sh :tangle ~/.config/../.bashrc_test
+alias ll='ls -l'
+#+end_src
+`,
+			wantFiles: []TangledFile{
+				{Path: "docs/config.sh", Content: "echo 1\necho 2\n"},
+				{Path: "docs/cmd/main.go", Content: "package main\n", Mkdirp: true},
+				{Path: "/tmp/a.py", Content: "#!/usr/bin/env python3\nprint(1)\n", Executable: true},
+				{Path: "~/.bashrc_test", Content: "alias ll='ls -l'\n"},
+			},
+		},
+		{
+			desc: "blocks in greater blocks",
+			input: `#+begin_quote
+#+begin_src sh :tangle a.sh
+a
+#+end_src
+#+end_quote
+:NOTE:
+#+begin_src sh :tangle a.sh
+b
+#+end_src
+:END:
+`,
+			wantFiles: []TangledFile{
+				{Path: "docs/a.sh", Content: "a\n\nb\n"},
+			},
+		},
+		{
+			desc: "file level header args",
+			input: `#+PROPERTY: header-args:sh :tangle init.sh
+
+#+begin_src sh
+a
+#+end_src
+
+#+begin_src sh :tangle no
+b
+#+end_src
+
+#+begin_src sh
+c
+#+end_src
+`,
+			wantFiles: []TangledFile{
+				{Path: "docs/init.sh", Content: "a\n\nc\n"},
+			},
+		},
+		{
+			desc: "noweb",
+			input: `#+begin_src go :tangle main.go :noweb yes
+func main() {
+	<<body>>
+}
+#+end_src
+
+#+NAME: body
+#+begin_src go :noweb yes
+fmt.Println(1)
+<<more>>
+#+end_src
+
+#+begin_src go :noweb-ref more
+fmt.Println(2)
+#+end_src
+
+#+begin_src go :noweb-ref more
+fmt.Println(3)
+#+end_src
+
+#+begin_src go :tangle plain.go
+<<body>>
+#+end_src
+`,
+			wantFiles: []TangledFile{
+				{Path: "docs/main.go", Content: "func main() {\n\tfmt.Println(1)\n\tfmt.Println(2)\n\tfmt.Println(3)\n}\n"},
+				{Path: "docs/plain.go", Content: "<<body>>\n"},
+			},
+		},
+		{
+			desc: "recursive noweb",
+			input: `#+NAME: loop
+#+begin_src sh :tangle loop.sh :noweb yes
+<<loop>>
+#+end_src
+`,
+			wantError: errors.New(`noweb reference "loop" is recursive`),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			files, err := Tangle(parseString(t, tt.input), "docs/config.org")
+			if err != nil {
+				if tt.wantError == nil || err.Error() != tt.wantError.Error() {
+					t.Fatalf("unexpected error: err=%v, want=%v", err, tt.wantError)
+				}
+				return
+			} else if tt.wantError != nil {
+				t.Fatalf("expect error but not occurred: want=%v", tt.wantError)
+			}
+			if !reflect.DeepEqual(files, tt.wantFiles) {
+				t.Errorf("unexpected files:\ngot=%#v\nwant=%#v", files, tt.wantFiles)
+			}
+		})
+	}
+}
@@ -1,6 +1,10 @@
 package org
 
 import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
 	"time"
 )
 
@@ -22,6 +26,52 @@ func (t Timestamp) String() string {
 	return t.Time.Format(timestampFormat)
 }
 
+// Literal returns the org timestamp such as <2022-01-30 Sun +1w>. The active
+// timestamp is enclosed in angle brackets, otherwise square brackets.
+func (t Timestamp) Literal(active bool) string {
+	left, right := "[", "]"
+	if active {
+		left, right = "<", ">"
+	}
+	s := t.String()
+	if t.Interval != "" {
+		s += " " + t.Interval
+	}
+	return left + s + right
+}
+
+// timestampJSON is the JSON representation of Timestamp.
+type timestampJSON struct {
+	Type     string    `json:"type"`
+	Time     time.Time `json:"time"`
+	IsDate   bool      `json:"isDate"`
+	Interval string    `json:"interval,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler. The output has the "timestamp" type
+// discriminator like Nodes.
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	return json.Marshal(timestampJSON{
+		Type:     timestampJSONType,
+		Time:     t.Time,
+		IsDate:   t.IsDate,
+		Interval: t.Interval,
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (t *Timestamp) UnmarshalJSON(data []byte) error {
+	var v timestampJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if v.Type != timestampJSONType {
+		return fmt.Errorf("unexpected timestamp type: %q", v.Type)
+	}
+	*t = Timestamp{Time: v.Time, IsDate: v.IsDate, Interval: v.Interval}
+	return nil
+}
+
 func ParseTimestamp(value, interval string) (Timestamp, error) {
 	t, err := time.Parse(timestampFormat, value)
 	if err != nil {
@@ -45,3 +95,27 @@ func ParseDatestamp(value, interval string) (Timestamp, error) {
 		Interval: interval,
 	}, nil
 }
+
+var timestampRegexp = regexp.MustCompile(`^[\[<](\d{4}-\d{2}-\d{2})(?: ([A-Za-z]+))?(?: (\d{1,2}:\d{2}))?(?: (\+\d+[dwmy]))?[\]>]$`)
+
+// ParseTimestampLiteral parses org timestamp such as <2022-01-30 Sun 10:03 +1w>
+// and [2022-01-30 Sun].
+func ParseTimestampLiteral(value string) (Timestamp, error) {
+	m := timestampRegexp.FindStringSubmatch(strings.TrimSpace(value))
+	if m == nil {
+		return Timestamp{}, fmt.Errorf("invalid timestamp: %q", value)
+	}
+	// day name is optional and redundant, so ignore it.
+	if m[3] == "" {
+		t, err := time.Parse("2006-01-02", m[1])
+		if err != nil {
+			return Timestamp{}, err
+		}
+		return Timestamp{Time: t, IsDate: true, Interval: m[4]}, nil
+	}
+	t, err := time.Parse("2006-01-02 15:04", m[1]+" "+m[3])
+	if err != nil {
+		return Timestamp{}, err
+	}
+	return Timestamp{Time: t, Interval: m[4]}, nil
+}
@@ -21,3 +21,44 @@ func mustParseDatestamp(t *testing.T, val, interval string) org.Timestamp {
 	}
 	return tp
 }
+
+func TestParseTimestampLiteral(t *testing.T) {
+	var tests = []struct {
+		desc    string
+		value   string
+		want    org.Timestamp
+		wantErr bool
+	}{
+		{
+			desc:  "inactive timestamp",
+			value: "[2022-02-03 Thu 11:19]",
+			want:  mustParseTimestamp(t, "2022-02-03 Thu 11:19", ""),
+		},
+		{
+			desc:  "active date with interval",
+			value: "<2022-02-03 Thu +1w>",
+			want:  mustParseDatestamp(t, "2022-02-03 Thu", "+1w"),
+		},
+		{
+			desc:  "without day name",
+			value: "<2022-02-03>",
+			want:  mustParseDatestamp(t, "2022-02-03 Thu", ""),
+		},
+		{
+			desc:    "not timestamp",
+			value:   "2022-02-03",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := org.ParseTimestampLiteral(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: err=%v, wantErr=%v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("unexpected timestamp: got=%#v, want=%#v", got, tt.want)
+			}
+		})
+	}
+}
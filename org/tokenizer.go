@@ -16,6 +16,18 @@ const (
 	KindText       TokenKind = "section"
 	KindBlockBegin TokenKind = "blockBegin"
 	KindBlockEnd   TokenKind = "blockEnd"
+	KindListItem   TokenKind = "listItem"
+	KindTableRow   TokenKind = "tableRow"
+	KindTableRule  TokenKind = "tableRule"
+	KindDrawer     TokenKind = "drawer"
+	KindFixedWidth TokenKind = "fixedWidth"
+	KindClock      TokenKind = "clock"
+
+	KindFootnoteDefinition TokenKind = "footnoteDefinition"
+	KindLatexEnvironment   TokenKind = "latexEnvironment"
+
+	KindDynamicBegin TokenKind = "dynamicBegin"
+	KindDynamicEnd   TokenKind = "dynamicEnd"
 )
 
 // NewToken creates new Token object.
@@ -35,6 +47,30 @@ type Token struct {
 	num int
 	// vals is the matched values. It can contain multiple item values.
 	vals []string
+	// pos is the position of the line in the source document.
+	pos Pos
+	// raw is the original line.
+	raw string
+}
+
+// Pos returns the position of the Token in the source document.
+func (t Token) Pos() Pos {
+	return t.pos
+}
+
+// text returns the original line. Tokens which are not created by Tokenizer
+// (e.g. NewToken) do not have the line, so the first value is returned.
+func (t Token) text() string {
+	if t.raw == "" && len(t.vals) > 0 {
+		return t.vals[0]
+	}
+	return t.raw
+}
+
+// Pos is a position in the source document.
+type Pos struct {
+	// Line is the 1-based line number. Zero means the position is unknown.
+	Line int `json:"line"`
 }
 
 // LexFn is a lexer function which returns token and flag.
@@ -42,11 +78,19 @@ type LexFn = func(line string) (t Token, ok bool)
 
 // defaultLexFns expresses currently supported lexers.
 var defaultLexFns = []LexFn{
-	LexHeadline, // * <keyword> <priority> <title> <tags>
-	LexBlock,    // #+BEGIN_<Name>: <property> .. #+END_<name>
-	LexKeyword,  // #+<keyword>: <val>
-	LexComment,  // # <comment>
-	LexAgenda,   // <agenda>: <date>
+	LexHeadline,           // * <keyword> <priority> <title> <tags>
+	LexBlock,              // #+BEGIN_<Name>: <property> .. #+END_<name>
+	LexDynamicBlock,       // #+BEGIN: <name> <params> .. #+END:
+	LexKeyword,            // #+<keyword>: <val>
+	LexComment,            // # <comment>
+	LexClock,              // CLOCK: [<start>]--[<end>] => <duration>
+	LexAgenda,             // <agenda>: <date>
+	LexDrawer,             // :<name>: <value>
+	LexFixedWidth,         // : <text>
+	LexTable,              // | <cell> | <cell> |
+	LexListItem,           // - <item>
+	LexFootnoteDefinition, // [fn:<label>] <text>
+	LexLatexEnvironment,   // \begin{<name>} .. \end{<name>}
 
 	LexText, // *
 }
@@ -70,13 +114,17 @@ func (t Tokenizer) Tokenize(in io.Reader) ([]Token, error) {
 	var (
 		scanner = bufio.NewScanner(in)
 		tokens  []Token
+		lineNum int
 	)
 nextLine:
 	for scanner.Scan() {
 		line := scanner.Text()
+		lineNum++
 		// try all lexFns
 		for _, lexFn := range t.lexFns {
 			if token, ok := lexFn(line); ok {
+				token.pos = Pos{Line: lineNum}
+				token.raw = line
 				tokens = append(tokens, token)
 				continue nextLine
 			}
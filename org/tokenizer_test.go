@@ -32,12 +32,12 @@ func TestTokenize(t *testing.T) {
 		{
 			desc:       "one token",
 			input:      "test",
-			wantTokens: []Token{testToken},
+			wantTokens: []Token{TokenAt(testToken, 1, "test")},
 		},
 		{
 			desc:       "multiple token",
 			input:      "test\ntest",
-			wantTokens: []Token{testToken, testToken},
+			wantTokens: []Token{TokenAt(testToken, 1, "test"), TokenAt(testToken, 2, "test")},
 		},
 		{
 			desc:      "no lexers can parse",
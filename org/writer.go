@@ -7,11 +7,107 @@ type Node interface {
 	Write(w io.Writer) error
 }
 
-func Write(nodes []Node, out io.Writer) error {
+// htmlWriterNode is implemented by Nodes whose HTML depends on the HTMLWriter
+// settings or the other nodes of the document.
+type htmlWriterNode interface {
+	writeHTML(w io.Writer, ctx *htmlContext) error
+}
+
+// HTMLWriter writes nodes as HTML.
+type HTMLWriter struct {
+	// Highlighter highlights the source code of SourceBlock. The source code
+	// is written as escaped plain text if it is nil.
+	Highlighter Highlighter
+	// HighlightStyle maps the classes of highlighted tokens to the inline CSS.
+	// The tokens are written with the class attribute if it is nil.
+	HighlightStyle map[string]string
+	// MathRenderer converts LaTeX fragments and environments. They are written
+	// with the delimiters for MathJax or KaTeX if it is nil.
+	MathRenderer MathRenderer
+	// ResolveLink converts the link target such as `file:notes.org` to the
+	// URL. The default conversion is used if it is nil or returns false.
+	ResolveLink func(target string) (string, bool)
+	// BaseURL is the absolute URL of the document such as
+	// `https://example.com/notes/a.html`. Relative link URLs are resolved
+	// against it if it is set, so the HTML can be used in other pages such as
+	// feeds.
+	BaseURL string
+	// MacroEnv is the environment of the built-in macros such as
+	// {{{input-file}}}, which are expanded before writing.
+	MacroEnv MacroEnv
+}
+
+// DefaultHTMLWriter creates a new HTMLWriter object without optional features.
+func DefaultHTMLWriter() HTMLWriter {
+	return HTMLWriter{}
+}
+
+// htmlContext is the state while writing a document.
+type htmlContext struct {
+	HTMLWriter
+	// lineNumber is the last line number of source blocks, which is
+	// continued by the +n switch.
+	lineNumber int
+	// notes is the footnotes of the document.
+	notes *footnotes
+	// opts is the #+OPTIONS of the document.
+	opts *Options
+	// links is the link abbreviations of #+LINK.
+	links map[string]string
+	// anchors is the IDs of headlines by their indexes in the document.
+	anchors map[int]string
+	// index is the index of the node being written in the document.
+	index int
+}
+
+// Write writes nodes as HTML to the specified writer. The footnotes section
+// is written at the end unless #+PRINT_FOOTNOTES is placed. Macros are
+// expanded with ExpandMacros.
+func (hw HTMLWriter) Write(nodes []Node, out io.Writer) error {
+	nodes, err := ExpandMacros(nodes, hw.MacroEnv)
+	if err != nil {
+		return err
+	}
+	return hw.writeExpanded(nodes, out)
+}
+
+// writeExpanded writes the nodes whose macros are already expanded, such as
+// the documents of Project.
+func (hw HTMLWriter) writeExpanded(nodes []Node, out io.Writer) error {
+	opts := ParseOptions(nodes)
+	ctx := &htmlContext{
+		HTMLWriter: hw,
+		notes:      newFootnotes(nodes),
+		opts:       &opts,
+		links:      linkAbbrevs(nodes),
+		anchors:    headlineAnchors(nodes),
+	}
 	for i := range nodes {
-		if err := nodes[i].Write(out); err != nil {
+		ctx.index = i
+		if err := ctx.write(out, nodes[i]); err != nil {
+			return err
+		}
+	}
+	return ctx.writeFootnotes(out)
+}
+
+func (ctx *htmlContext) writeAll(w io.Writer, nodes []Node) error {
+	for i := range nodes {
+		if err := ctx.write(w, nodes[i]); err != nil {
 			return err
 		}
 	}
 	return nil
 }
+
+func (ctx *htmlContext) write(w io.Writer, node Node) error {
+	if n, ok := node.(htmlWriterNode); ok {
+		return n.writeHTML(w, ctx)
+	}
+	return node.Write(w)
+}
+
+// Write writes nodes as HTML with DefaultHTMLWriter.
+func Write(nodes []Node, out io.Writer) error {
+	return DefaultHTMLWriter().Write(nodes, out)
+}
@@ -10,8 +10,9 @@ import (
 )
 
 const (
-	inputFile  = "fixtures/input.org"
-	outputFile = "fixtures/output.html"
+	inputFile          = "fixtures/input.org"
+	outputFile         = "fixtures/output.html"
+	markdownOutputFile = "fixtures/output.md"
 )
 
 func main() {
@@ -40,5 +41,13 @@ func test() error {
 	if err := org.Write(nodes, &out); err != nil {
 		return err
 	}
-	return ioutil.WriteFile(outputFile, out.Bytes(), 0644)
+	if err := ioutil.WriteFile(outputFile, out.Bytes(), 0644); err != nil {
+		return err
+	}
+
+	out.Reset()
+	if err := org.WriteMarkdown(nodes, &out); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(markdownOutputFile, out.Bytes(), 0644)
 }